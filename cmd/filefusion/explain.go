@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/drgsn/filefusion/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd traces why a file is included in or left out of a bundle
+var explainCmd = &cobra.Command{
+	Use:   "explain <file> [paths...]",
+	Short: "Explain why a file is included in or left out of the bundle",
+	Long: `Explain runs the file selection of a bundle with the same flags for a single
+file and prints every decision taken for it: the include and exclude patterns
+tested, the filters, how links are resolved, the size checks, the detected
+language and the outcome of the cleaner and other transformers.
+The paths are the inputs of the bundle and default to the current directory.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplain,
+}
+
+// runExplain implements the explain command
+func runExplain(cmd *cobra.Command, args []string) error {
+	// The decision chain covers what is logged for the file, so only errors
+	// are logged unless -v is given
+	level := core.LevelForVerbosity(quiet, verbosity)
+	if verbosity == 0 {
+		level = slog.LevelError
+	}
+	if err := configureLoggingLevel(level); err != nil {
+		return err
+	}
+
+	config, err := validateAndGetConfig(args[1:])
+	if err != nil {
+		return err
+	}
+
+	inputs := args[1:]
+	if len(inputs) == 0 {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("error getting current working directory: %w", err)
+		}
+		inputs = []string{currentDir}
+	}
+
+	explainer := &core.Explainer{
+		Finder:    newFileFinder(config),
+		Manager:   core.NewFileManager(config.MaxFileSize, config.MaxOutputSize, config.OutputType),
+		Processor: newFileProcessor(config, inputs),
+	}
+	explainer.Manager.SetOversize(config.Oversize)
+	explainer.Manager.SetSymlinks(config.Symlinks)
+
+	outputPaths, err := explainer.Manager.DeriveOutputPaths(inputs, outputPath)
+	if err != nil {
+		return err
+	}
+	explainer.Finder.SetOutputs(outputPaths)
+
+	explanation, err := explainer.Explain(args[0], inputs)
+	if err != nil {
+		return err
+	}
+	return writeExplanation(cmd.OutOrStdout(), explanation)
+}
+
+// writeExplanation prints the decision chain as a table followed by the outcome
+func writeExplanation(w io.Writer, x *core.Explanation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "STAGE\tRESULT\tDETAIL\n")
+	for _, step := range x.Steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", step.Stage, step.Result, step.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	outcome := "left out"
+	if x.Included {
+		outcome = "included"
+	}
+	_, err := fmt.Fprintf(w, "\n%s is %s\n", x.Path, outcome)
+	return err
+}
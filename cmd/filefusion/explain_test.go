@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drgsn/filefusion/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunExplain(t *testing.T) {
+	tmpDir := t.TempDir()
+	goFile := filepath.Join(tmpDir, "main.go")
+	assert.NoError(t, os.WriteFile(goFile, []byte("package main\n"), 0644))
+	txtFile := filepath.Join(tmpDir, "notes.txt")
+	assert.NoError(t, os.WriteFile(txtFile, []byte("notes\n"), 0644))
+
+	pattern, exclude, maxFileSize, maxOutputSize, outputPath = "*.go", "", "10MB", "50MB", ""
+
+	tests := []struct {
+		name        string
+		args        []string
+		contains    []string
+		expectError bool
+	}{
+		{
+			name:     "Included file",
+			args:     []string{goFile, tmpDir},
+			contains: []string{"patterns  included", "language  go", goFile + " is included"},
+		},
+		{
+			name:     "Excluded file",
+			args:     []string{txtFile, tmpDir},
+			contains: []string{"did not match any include pattern", txtFile + " is left out"},
+		},
+		{
+			name:        "Missing file",
+			args:        []string{filepath.Join(tmpDir, "missing.go"), tmpDir},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			explainCmd.SetOut(&out)
+			defer explainCmd.SetOut(nil)
+
+			err := runExplain(explainCmd, tt.args)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, out.String(), want)
+			}
+		})
+	}
+}
+
+func TestRootCommandArgs(t *testing.T) {
+	// Input paths must not be taken for unknown subcommands
+	cmd, args, err := rootCmd.Find([]string{"src", "--dry-run"})
+	assert.NoError(t, err)
+	assert.Equal(t, rootCmd, cmd)
+	assert.NoError(t, cmd.ValidateArgs([]string{"src"}))
+	assert.Equal(t, []string{"src", "--dry-run"}, args)
+
+	cmd, _, err = rootCmd.Find([]string{"explain", "main.go"})
+	assert.NoError(t, err)
+	assert.Equal(t, explainCmd, cmd)
+}
+
+func TestWriteExplanation(t *testing.T) {
+	var out bytes.Buffer
+	err := writeExplanation(&out, &core.Explanation{
+		Path: "/src/a.go",
+		Steps: []core.ExplainStep{
+			{Stage: "input", Result: "found", Detail: "below /src as a.go"},
+			{Stage: "patterns", Result: "excluded", Detail: "did not match any include pattern"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "STAGE     RESULT    DETAIL\n"+
+		"input     found     below /src as a.go\n"+
+		"patterns  excluded  did not match any include pattern\n"+
+		"\n/src/a.go is left out\n", out.String())
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/drgsn/filefusion/internal/core"
 	"github.com/drgsn/filefusion/internal/core/cleaner"
+	"github.com/drgsn/filefusion/internal/core/markup"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Command-line flags
@@ -21,6 +26,25 @@ var (
 	maxOutputSize  string
 	dryRun         bool
 	ignoreSymlinks bool
+	symlinks       string
+	includeStats   bool
+	provenance     bool
+	reportPath     string
+	keepGoing      bool
+	failFast       bool
+	force          bool
+	noClobber      bool
+	backup         bool
+	skipBinary     bool
+	langMap        []string
+	oversize       string
+	oversizeHead   int
+	oversizeTail   int
+
+	// Logging flags
+	quiet     bool
+	verbosity int
+	logFormat string
 
 	// Cleaner flags
 	cleanEnabled         bool
@@ -31,6 +55,36 @@ var (
 	removeGettersSetters bool
 	optimizeWhitespace   bool
 	removeEmptyLines     bool
+
+	// Document flags
+	convertNotebooks    bool
+	notebookOutputs     string
+	notebookOutputLines int
+	stripBadges         bool
+	stripDocComments    bool
+	stripImages         bool
+
+	// Transformer flags
+	transforms []string
+
+	// Skeleton flags
+	skeletonEnabled bool
+	fullPattern     string
+
+	// Selection flags
+	symbols []string
+	entries []string
+	depth   int
+
+	// Ranking flags
+	query     string
+	maxTokens int
+	fallback  string
+
+	// Filter flags
+	maxDepth      int
+	includeHidden bool
+	filterTerms   []core.FilterTerm // Predicates and operators in command-line order
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -40,23 +94,135 @@ var rootCmd = &cobra.Command{
 	Long: `Filefusion concatenates files into a format optimized for Large Language Models (LLMs).
 It preserves file metadata and structures the output in an XML-like or JSON format.
 Complete documentation is available at https://github.com/drgsn/filefusion`,
+	// Input paths are accepted next to subcommands such as explain
+	Args: cobra.ArbitraryArgs,
 	RunE: runMix,
 }
 
 func init() {
 	initCoreFlags()
 	initCleanerFlags()
+	initMarkupFlags()
+	initTransformFlags()
+	initLoggingFlags()
+	initSkeletonFlags()
+	initSelectionFlags()
+	initRankingFlags()
+	initFilterFlags()
+
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(versionCmd)
 }
 
 // initCoreFlags initializes the core command-line flags
 func initCoreFlags() {
 	rootCmd.PersistentFlags().StringVarP(&outputPath, "output", "o", "", "output file path")
+	rootCmd.PersistentFlags().BoolVar(&force, "force", false, "replace an existing output file that is not a bundle")
+	rootCmd.PersistentFlags().BoolVar(&noClobber, "no-clobber", false, "leave an existing output file unchanged")
+	rootCmd.PersistentFlags().BoolVar(&backup, "backup", false, "keep an existing output file with a .bak suffix before replacing it")
+	rootCmd.MarkFlagsMutuallyExclusive("force", "no-clobber", "backup")
 	rootCmd.PersistentFlags().StringVarP(&pattern, "pattern", "p", "*.go,*.json,*.yaml,*.yml", "file patterns")
 	rootCmd.PersistentFlags().StringVarP(&exclude, "exclude", "e", "", "exclude patterns")
 	rootCmd.PersistentFlags().StringVar(&maxFileSize, "max-file-size", "10MB", "maximum size for individual input files")
 	rootCmd.PersistentFlags().StringVar(&maxOutputSize, "max-output-size", "50MB", "maximum size for output file")
+	rootCmd.PersistentFlags().StringVar(&oversize, "oversize", "skip", "files over max-file-size: skip, truncate or skeleton")
+	rootCmd.PersistentFlags().IntVar(&oversizeHead, "oversize-head", core.DefaultOversizeHead, "lines kept from the start of truncated files")
+	rootCmd.PersistentFlags().IntVar(&oversizeTail, "oversize-tail", core.DefaultOversizeTail, "lines kept from the end of truncated files")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show the list of files that will be processed")
+	rootCmd.PersistentFlags().StringVar(&symlinks, "symlinks", "follow", "symbolic links: follow, skip or record (bundled with their target and no content)")
 	rootCmd.PersistentFlags().BoolVar(&ignoreSymlinks, "ignore-symlinks", false, "Ignore symbolic links when processing files")
+	rootCmd.PersistentFlags().MarkDeprecated("ignore-symlinks", "use --symlinks=skip instead")
+	rootCmd.PersistentFlags().BoolVar(&skipBinary, "skip-binary", true, "skip files that appear to be binary")
+	rootCmd.PersistentFlags().StringArrayVar(&langMap, "lang-map", nil, "override language detection, e.g. '*.inc=php' (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&includeStats, "stats", false, "include a statistics section in the output")
+	rootCmd.PersistentFlags().BoolVar(&provenance, "provenance", false, "record the version, generation time, git commit and options in the output")
+	rootCmd.PersistentFlags().StringVar(&reportPath, "report", "", "write a JSON report of included and skipped files to this path")
+	rootCmd.PersistentFlags().BoolVar(&keepGoing, "keep-going", true, "write the bundle without the files that fail and exit with code 2")
+	rootCmd.PersistentFlags().BoolVar(&failFast, "fail-fast", false, "stop at the first file that fails")
+	rootCmd.MarkFlagsMutuallyExclusive("keep-going", "fail-fast")
+}
+
+// initMarkupFlags initializes the flags for notebooks and documentation files
+func initMarkupFlags() {
+	rootCmd.PersistentFlags().BoolVar(&convertNotebooks, "notebooks", true, "reduce Jupyter notebooks to their markdown and code cells")
+	rootCmd.PersistentFlags().StringVar(&notebookOutputs, "notebook-outputs", "truncate", "notebook cell outputs: drop, truncate or keep")
+	rootCmd.PersistentFlags().IntVar(&notebookOutputLines, "notebook-output-lines", 10, "lines kept per notebook output when truncating")
+	rootCmd.PersistentFlags().BoolVar(&stripBadges, "doc-strip-badges", false, "remove status badges from Markdown and reStructuredText")
+	rootCmd.PersistentFlags().BoolVar(&stripDocComments, "doc-strip-comments", false, "remove HTML comments from Markdown and comments from reStructuredText")
+	rootCmd.PersistentFlags().BoolVar(&stripImages, "doc-strip-images", false, "remove images from Markdown and reStructuredText")
+}
+
+// initTransformFlags initializes the flags for the content transformer chain
+func initTransformFlags() {
+	rootCmd.PersistentFlags().StringArrayVar(&transforms, "transform", nil, "add a transformer to the chain run on every file: clean, redact, truncate[:lines], line-numbers, log-summary, minify[:items] or exec:command, optionally prefixed by 'patterns=' (repeatable, applied in order)")
+}
+
+// initLoggingFlags initializes the logging flags
+func initLoggingFlags() {
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only log errors")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "increase log verbosity (-v for debug, -vv for trace)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format: text or json")
+}
+
+// initSkeletonFlags initializes the skeleton mode flags
+func initSkeletonFlags() {
+	rootCmd.PersistentFlags().BoolVar(&skeletonEnabled, "skeleton", false, "keep only declarations and signatures, eliding function bodies")
+	rootCmd.PersistentFlags().StringVar(&fullPattern, "full", "", "patterns for files kept whole in skeleton mode")
+}
+
+// initSelectionFlags initializes the flags that narrow the output to selected code
+func initSelectionFlags() {
+	rootCmd.PersistentFlags().StringArrayVar(&symbols, "symbol", nil, "extract only the named declaration and its dependencies (repeatable, e.g. pkg.Func)")
+	rootCmd.PersistentFlags().StringArrayVar(&entries, "entry", nil, "bundle an entry file and the local files it imports (repeatable)")
+	rootCmd.PersistentFlags().IntVar(&depth, "depth", 1, "levels of references or imports to follow from selected symbols or entries")
+}
+
+// initRankingFlags initializes the flags for relevance ranking and budget fitting
+func initRankingFlags() {
+	rootCmd.PersistentFlags().StringVar(&query, "query", "", "rank files by relevance to this text and fill the output budget with the best matches")
+	rootCmd.PersistentFlags().IntVar(&maxTokens, "max-tokens", 0, "token budget for ranked selection (0 for no token limit)")
+	rootCmd.PersistentFlags().StringVar(&fallback, "fallback", "skeleton", "for ranked files that do not fit: skeleton, tree or none")
+}
+
+// initFilterFlags initializes the find-style flags that filter matched files
+func initFilterFlags() {
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "descend at most this many directory levels below each input (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&includeHidden, "hidden", true, "include hidden files and directories, --hidden=false skips them")
+	rootCmd.PersistentFlags().Var(&filterFlag{name: "min-size"}, "min-size", "keep files of at least this size, e.g. 1KB")
+	rootCmd.PersistentFlags().Var(&filterFlag{name: "newer-than"}, "newer-than", "keep files modified within this age (e.g. 7d, 12h) or after this date")
+	rootCmd.PersistentFlags().Var(&filterFlag{name: "older-than"}, "older-than", "keep files modified before this age or date")
+	rootCmd.PersistentFlags().Var(&filterFlag{name: "contains"}, "contains", "keep files whose content matches this regular expression")
+	rootCmd.PersistentFlags().Var(&filterFlag{name: "not-contains"}, "not-contains", "keep files whose content does not match this regular expression")
+	for _, operator := range []string{"and", "or"} {
+		flag := rootCmd.PersistentFlags().VarPF(&filterFlag{name: operator, operator: true}, operator, "",
+			fmt.Sprintf("combine the filters before and after with %s (filters are combined with and by default)", strings.ToUpper(operator)))
+		flag.NoOptDefVal = "true"
+	}
+}
+
+// filterFlag records a filter predicate or operator in filterTerms, keeping
+// the order of the command line that the filter expression depends on
+type filterFlag struct {
+	name     string
+	operator bool
+}
+
+func (f *filterFlag) Set(value string) error {
+	term := core.FilterTerm{Name: f.name, Value: value}
+	if f.operator {
+		term.Value = ""
+	}
+	filterTerms = append(filterTerms, term)
+	return nil
+}
+
+func (f *filterFlag) String() string { return "" }
+
+func (f *filterFlag) Type() string {
+	if f.operator {
+		return "bool"
+	}
+	return "string"
 }
 
 // initCleanerFlags initializes the code cleaner flags
@@ -71,22 +237,67 @@ func initCleanerFlags() {
 	rootCmd.PersistentFlags().BoolVar(&removeEmptyLines, "clean-remove-empty-lines", true, "remove empty lines")
 }
 
+// exitPartial is the exit code of a run that completed without the files that
+// failed, set apart from the exit code 1 of a run that stopped
+const exitPartial = 2
+
+// partialError is returned by a run that completed without the files that failed
+type partialError struct {
+	failures *core.MultiError
+}
+
+func (e *partialError) Error() string {
+	noun := "files"
+	if e.failures.Len() == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("completed without %d %s that failed", e.failures.Len(), noun)
+}
+
+func (e *partialError) Unwrap() error {
+	return e.failures
+}
+
 // main is the entry point of the application
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var partial *partialError
+		if errors.As(err, &partial) {
+			os.Exit(exitPartial)
+		}
 		os.Exit(1)
 	}
 }
 
 // runMix implements the main program logic
 func runMix(cmd *cobra.Command, args []string) error {
+	if err := configureLogging(); err != nil {
+		return err
+	}
+
 	// Validate and get initial configuration
 	config, err := validateAndGetConfig(args)
 	if err != nil {
 		return err
 	}
 
+	// Split line-range and symbol selectors such as file.go:40-120 off the inputs
+	args, config.Selections, err = core.ParseSelectors(args)
+	if err != nil {
+		return err
+	}
+
+	// Entries follow their whole import closure unless --depth is given
+	if flag := cmd.Flag("depth"); flag == nil || !flag.Changed {
+		config.EntryDepth = -1
+	}
+	// and keep every imported file unless --pattern narrows them, as the
+	// default patterns would drop the sources of most languages
+	if flag := cmd.Flag("pattern"); len(config.Entries) > 0 && (flag == nil || !flag.Changed) {
+		config.IncludePatterns = nil
+	}
+
 	if len(args) == 0 {
 		currentDir, err := os.Getwd()
 		if err != nil {
@@ -95,60 +306,203 @@ func runMix(cmd *cobra.Command, args []string) error {
 		args = []string{currentDir}
 	}
 
+	// Open archives and git revisions given as inputs, their files are read in place
+	for _, arg := range args {
+		src, err := core.OpenSource(arg)
+		if err != nil {
+			return err
+		}
+		if src != nil {
+			defer src.Close()
+		}
+	}
+
+	// Files that fail are collected and listed together at the end of the run
+	failures := &core.MultiError{}
+	defer func() {
+		if failures.Len() > 0 {
+			failures.WriteTable(cmd.ErrOrStderr())
+		}
+	}()
+
 	// Create file manager
 	fileManager := core.NewFileManager(config.MaxFileSize, config.MaxOutputSize, config.OutputType)
+	fileManager.SetOversize(config.Oversize)
+	fileManager.SetSelections(config.Selections)
+	fileManager.SetSymlinks(config.Symlinks)
 
-	// Get list of files using FileFinder
-	finder := core.NewFileFinder(config.IncludePatterns, config.ExcludePatterns, !ignoreSymlinks)
-	files, err := finder.FindMatchingFiles(args)
+	// Get output paths, the finder leaves them out of the inputs
+	outputPaths, err := fileManager.DeriveOutputPaths(args, outputPath)
 	if err != nil {
-		return fmt.Errorf("error finding files: %w", err)
+		return err
 	}
 
-	// Validate files against size limits
-	validFiles, err := fileManager.ValidateFiles(files)
-	if err != nil {
-		return err
+	// Get list of files from the entry dependency graph or using FileFinder
+	var files []string
+	var external []string
+	finder := newFileFinder(config)
+	finder.SetOutputs(outputPaths)
+	if len(config.Entries) > 0 {
+		graph, err := core.ResolveDependencies(config.Entries, config.EntryDepth)
+		if err != nil {
+			return fmt.Errorf("error resolving dependencies: %w", err)
+		}
+		external = graph.External
+		if len(external) > 0 {
+			core.Logger().Info("External imports not expanded", "event", core.EventExternalImport, "count", len(external))
+		}
+		// The closure goes through the same rules and output checks as a walk
+		files, err = finder.MatchFiles(graph.Files, args)
+		if err != nil {
+			return err
+		}
+	} else {
+		files, err = finder.FindMatchingFiles(args)
+		if err := addFailures(cmd, failures, core.StageFind, err, config); err != nil {
+			return err
+		}
 	}
+	skipped := finder.ExcludedFiles()
 
+	filterLevel := slog.LevelDebug
 	if dryRun {
-		fmt.Println("\nDry run complete. No files will be processed.")
-		return nil
+		filterLevel = slog.LevelInfo
+	}
+	core.LogFiltered(skipped, filterLevel)
+
+	// Validate files against size limits. Ranked runs fit the output size
+	// by selection instead of failing when the files are too large together.
+	var validFiles []string
+	if config.Query != "" {
+		validFiles, err = fileManager.FilterFiles(files)
+	} else {
+		validFiles, err = fileManager.ValidateFiles(files)
+	}
+	if err := addFailures(cmd, failures, core.StageValidate, fileManager.Errors(), config); err != nil {
+		return err
 	}
-
-	// Get output paths
-	outputPaths, err := fileManager.DeriveOutputPaths(args, outputPath)
 	if err != nil {
 		return err
 	}
 
+	// Collect files left out before processing for the report
+	skipped = append(skipped, fileManager.SkippedFiles()...)
+
+	// Rank files against the query and keep the best ones within the budget
+	var ranked []core.RankedFile
+	if config.Query != "" {
+		ranked, err = core.RankFiles(validFiles, config.Query)
+		if err != nil {
+			return fmt.Errorf("error ranking files: %w", err)
+		}
+		core.FitBudget(ranked, core.Budget{
+			MaxBytes:  config.MaxOutputSize * 9 / 10, // leave room for the output format
+			MaxTokens: config.MaxTokens,
+			Fallback:  config.Fallback,
+		})
+
+		rankLevel := slog.LevelDebug
+		if dryRun {
+			rankLevel = slog.LevelInfo
+		}
+		core.LogRanking(ranked, rankLevel)
+
+		var dropped []core.ReportEntry
+		validFiles, dropped = core.SplitRanking(ranked)
+		skipped = append(skipped, dropped...)
+		if len(validFiles) == 0 {
+			return fmt.Errorf("no files fit within the budget")
+		}
+	}
+
+	if dryRun {
+		if reportPath != "" {
+			skipped = append(skipped, failures.ReportEntries()...)
+			if err := writeDryRunReport(validFiles, skipped, external, ranked); err != nil {
+				return err
+			}
+		}
+		core.Logger().Info("Dry run complete. No files will be processed.", "event", core.EventDryRun)
+		return completeRun(cmd, failures)
+	}
+
 	// Group files by output path
 	fileGroups, err := fileManager.GroupFilesByOutput(validFiles, outputPaths)
 	if err != nil {
 		return err
 	}
 
+	// The provenance is shared by the outputs of the run
+	var prov *core.Provenance
+	if provenance {
+		prov, err = core.NewProvenance(readBuildInfo().Version, args, provenanceOptions(cmd, config))
+		if err != nil {
+			return err
+		}
+	}
+
+	var included []core.FileContent
+	var outputs []string
+
 	// Process each group and generate output
 	for _, group := range fileGroups {
+		// Check the output first, so no files are processed for an output
+		// that is left unchanged or refused
+		write, err := core.CheckOutput(group.OutputPath, validFiles, config.Overwrite)
+		if err != nil {
+			return err
+		}
+		if !write {
+			continue
+		}
+
 		// Create processor for this group
-		processor := core.NewFileProcessor(&core.MixOptions{
-			MaxFileSize:    config.MaxFileSize,
-			MaxOutputSize:  config.MaxOutputSize,
-			OutputType:     config.OutputType,
-			CleanerOptions: config.CleanerOptions,
-		})
+		processor := newFileProcessor(config, args)
 
 		// Process files
 		contents, err := processor.ProcessFiles(group.Files)
-		if err != nil {
-			return fmt.Errorf("error processing files for %s: %w", group.OutputPath, err)
+		if err := addFailures(cmd, failures, core.StageProcess, err, config); err != nil {
+			return err
+		}
+
+		// An output of selected files that all came out empty is left as it is
+		if len(contents) == 0 && hasSelection(group.Files, config.Selections) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("no lines selected for %s, the output is left unchanged", group.OutputPath)
+		}
+
+		// Keep the entries first, followed by their imports
+		if len(config.Entries) > 0 {
+			core.OrderContents(contents, group.Files)
+		}
+
+		// Reduce lower-ranked files and order the bundle by relevance
+		if len(ranked) > 0 {
+			contents = core.ApplyRanking(contents, ranked)
+		}
+
+		// Narrow the contents to the selected symbols
+		if len(config.Symbols) > 0 {
+			contents, err = core.ExtractSymbols(contents, core.SymbolOptions{
+				Symbols:      config.Symbols,
+				Depth:        config.Depth,
+				SkeletonRest: config.Skeleton,
+			})
+			if err != nil {
+				return fmt.Errorf("error extracting symbols for %s: %w", group.OutputPath, err)
+			}
 		}
 
 		// Generate output
+		skipped = append(skipped, processor.SkippedFiles()...)
+
 		generator, err := core.NewOutputGenerator(&core.MixOptions{
 			OutputPath:    group.OutputPath,
 			OutputType:    config.OutputType,
 			MaxOutputSize: config.MaxOutputSize,
+			IncludeStats:  includeStats,
+			Overwrite:     config.Overwrite,
+			Provenance:    prov,
 		})
 		if err != nil {
 			return fmt.Errorf("error creating output: %w", err)
@@ -158,9 +512,188 @@ func runMix(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("error generating output for %s: %w", group.OutputPath, err)
 		}
 
-		fmt.Printf("Generated output: %s\n", group.OutputPath)
+		core.Logger().Info("Generated output", "event", core.EventOutputWritten, "path", group.OutputPath)
+		included = append(included, contents...)
+		outputs = append(outputs, group.OutputPath)
 	}
 
+	if reportPath != "" {
+		skipped = append(skipped, failures.ReportEntries()...)
+		report := core.NewReport(included, skipped)
+		report.Outputs = outputs
+		report.External = external
+		report.Ranking = ranked
+		if err := core.WriteReport(reportPath, report); err != nil {
+			return err
+		}
+		core.Logger().Info("Generated report", "event", core.EventOutputWritten, "path", reportPath)
+	}
+
+	return completeRun(cmd, failures)
+}
+
+// hasSelection reports whether any of the files is narrowed by a selector
+func hasSelection(files []string, selections map[string]*core.Selection) bool {
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil && selections[abs] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// overwritePolicy returns the policy for existing output files selected by
+// --force, --no-clobber or --backup
+func overwritePolicy() core.OverwritePolicy {
+	switch {
+	case force:
+		return core.OverwriteForce
+	case noClobber:
+		return core.OverwriteNoClobber
+	case backup:
+		return core.OverwriteBackup
+	default:
+		return core.OverwriteBundles
+	}
+}
+
+// provenanceIgnored lists the flags that do not change the content of a
+// bundle, which are left out of its provenance
+var provenanceIgnored = map[string]bool{
+	"output": true, "report": true, "dry-run": true, "provenance": true,
+	"force": true, "no-clobber": true, "backup": true,
+	"quiet": true, "verbose": true, "log-format": true, "help": true,
+}
+
+// provenanceOptions returns every flag of a run that can change the content
+// of a bundle with the value in effect, defaults included, so a change of
+// default between versions shows in the options. The filters are recorded
+// as one expression, empty without filters, next to the output format.
+func provenanceOptions(cmd *cobra.Command, config *Config) []core.ProvenanceOption {
+	options := []core.ProvenanceOption{{Name: "format", Value: strings.ToLower(string(config.OutputType))}}
+	filter := ""
+	if config.Filter != nil {
+		filter = config.Filter.String()
+	}
+	options = append(options, core.ProvenanceOption{Name: "filter", Value: filter})
+	cmd.Root().PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if _, isFilter := f.Value.(*filterFlag); isFilter || provenanceIgnored[f.Name] {
+			return
+		}
+		// Deprecated flags act through their replacement unless given
+		if f.Deprecated != "" && !f.Changed {
+			return
+		}
+		options = append(options, core.ProvenanceOption{Name: f.Name, Value: f.Value.String()})
+	})
+	return options
+}
+
+// addFailures records the files that failed at a stage of the run. With
+// --fail-fast the run stops at the first failure.
+func addFailures(cmd *cobra.Command, failures *core.MultiError, stage core.Stage, err error, config *Config) error {
+	failures.Add(stage, err)
+	if config.FailFast && failures.Len() > 0 {
+		cmd.SilenceUsage = true
+		return failures
+	}
+	return nil
+}
+
+// completeRun returns the error of a run that went on past failed files,
+// which sets the exit code apart from a run that stopped
+func completeRun(cmd *cobra.Command, failures *core.MultiError) error {
+	if failures.Len() == 0 {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return &partialError{failures: failures}
+}
+
+// newFileFinder creates the file finder for the patterns and filters of a run
+func newFileFinder(config *Config) *core.FileFinder {
+	finder := core.NewFileFinder(config.IncludePatterns, config.ExcludePatterns, true)
+	finder.SetSymlinks(config.Symlinks)
+	finder.SetFilter(config.Filter)
+	finder.SetMaxDepth(config.MaxDepth)
+	finder.SetHidden(config.Hidden)
+	finder.SetFailFast(config.FailFast)
+	return finder
+}
+
+// newFileProcessor creates the processor that reads and transforms the files
+// found below the inputs of a run
+func newFileProcessor(config *Config, inputs []string) *core.FileProcessor {
+	return core.NewFileProcessor(&core.MixOptions{
+		MaxFileSize:    config.MaxFileSize,
+		MaxOutputSize:  config.MaxOutputSize,
+		OutputType:     config.OutputType,
+		CleanerOptions: config.CleanerOptions,
+		MarkupOptions:  config.MarkupOptions,
+		SkipBinary:     skipBinary,
+		Skeleton:       config.Skeleton && len(config.Symbols) == 0,
+		FullPatterns:   config.FullPatterns,
+		LanguageMap:    config.LanguageMap,
+		InputRoots:     inputs,
+		Transformers:   config.Transformers,
+		Oversize:       config.Oversize,
+		OversizeHead:   config.OversizeHead,
+		OversizeTail:   config.OversizeTail,
+		Selections:     config.Selections,
+		Symlinks:       config.Symlinks,
+		FailFast:       config.FailFast,
+	})
+}
+
+// writeDryRunReport writes a report for a dry run, where files that passed
+// validation are listed as included without being read
+func writeDryRunReport(validFiles []string, skipped []core.ReportEntry, external []string, ranked []core.RankedFile) error {
+	var included []core.FileContent
+	for _, file := range validFiles {
+		info, err := core.StatFile(file)
+		if err != nil {
+			// Recorded links may point nowhere
+			info, err = os.Lstat(file)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting file info: %w", err)
+		}
+		included = append(included, core.FileContent{
+			Path:      file,
+			Name:      filepath.Base(file),
+			Extension: strings.TrimPrefix(filepath.Ext(file), "."),
+			Size:      info.Size(),
+		})
+	}
+
+	report := core.NewReport(included, skipped)
+	report.External = external
+	report.Ranking = ranked
+	if err := core.WriteReport(reportPath, report); err != nil {
+		return err
+	}
+	core.Logger().Info("Generated report", "event", core.EventOutputWritten, "path", reportPath)
+	return nil
+}
+
+// configureLogging installs the logger selected by the logging flags
+func configureLogging() error {
+	return configureLoggingLevel(core.LevelForVerbosity(quiet, verbosity))
+}
+
+// configureLoggingLevel installs a logger in the format of the logging flags
+// that logs records of at least the given level
+func configureLoggingLevel(level slog.Level) error {
+	format, err := core.ParseLogFormat(logFormat)
+	if err != nil {
+		return err
+	}
+
+	core.SetLogger(core.NewLogger(os.Stderr, core.LogOptions{
+		Format: format,
+		Level:  level,
+		Color:  core.ColorEnabled(os.Stderr),
+	}))
 	return nil
 }
 
@@ -170,8 +703,30 @@ type Config struct {
 	ExcludePatterns []string
 	MaxFileSize     int64
 	MaxOutputSize   int64
+	Oversize        core.Oversize
+	Symlinks        core.SymlinkPolicy
+	FailFast        bool
+	Overwrite       core.OverwritePolicy
+	OversizeHead    int
+	OversizeTail    int
 	OutputType      core.OutputType
 	CleanerOptions  *cleaner.CleanerOptions
+	MarkupOptions   *markup.MarkupOptions
+	Skeleton        bool
+	FullPatterns    []string
+	LanguageMap     []core.LanguageMapping
+	Transformers    []core.Transformer
+	Symbols         []string
+	Depth           int
+	Entries         []string
+	EntryDepth      int
+	Query           string
+	MaxTokens       int
+	Fallback        core.Fallback
+	Filter          *core.Filter
+	MaxDepth        int
+	Hidden          bool
+	Selections      map[string]*core.Selection
 }
 
 // validateAndGetConfig validates inputs and returns a Config struct
@@ -194,6 +749,60 @@ func validateAndGetConfig(args []string) (*Config, error) {
 		}
 	}
 
+	var fullPatterns []string
+	if fullPattern != "" {
+		fullPatterns, err = validator.ExpandPattern(fullPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid full pattern: %w", err)
+		}
+	}
+
+	languageMap, err := core.ParseLanguageMap(langMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if depth < 0 {
+		return nil, fmt.Errorf("depth cannot be negative")
+	}
+
+	if maxTokens < 0 {
+		return nil, fmt.Errorf("max-tokens cannot be negative")
+	}
+
+	if maxDepth < 0 {
+		return nil, fmt.Errorf("max-depth cannot be negative")
+	}
+
+	filter, err := core.ParseFilter(filterTerms, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	fallbackMode, err := core.ParseFallback(fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	oversizeMode, err := core.ParseOversize(oversize)
+	if err != nil {
+		return nil, err
+	}
+
+	symlinkPolicy, err := core.ParseSymlinkPolicy(symlinks)
+	if err != nil {
+		return nil, err
+	}
+	if ignoreSymlinks {
+		symlinkPolicy = core.SymlinkSkip
+	}
+	if oversizeHead < 0 || oversizeTail < 0 {
+		return nil, fmt.Errorf("oversize-head and oversize-tail cannot be negative")
+	}
+	if oversizeHead == 0 && oversizeTail == 0 {
+		return nil, fmt.Errorf("oversize-head and oversize-tail cannot both be zero")
+	}
+
 	fileManager := core.NewFileManager(0, 0, core.OutputTypeXML) // Temporary instance for parsing
 	maxFileSizeBytes, err := fileManager.ParseSize(maxFileSize)
 	if err != nil {
@@ -212,13 +821,44 @@ func validateAndGetConfig(args []string) (*Config, error) {
 
 	cleanerOpts := getCleanerOptions()
 
+	markupOpts, err := getMarkupOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	transformers, err := getTransformers(cleanerOpts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		IncludePatterns: includePatterns,
 		ExcludePatterns: excludePatterns,
 		MaxFileSize:     maxFileSizeBytes,
 		MaxOutputSize:   maxOutputSizeBytes,
+		Oversize:        oversizeMode,
+		Symlinks:        symlinkPolicy,
+		FailFast:        failFast || !keepGoing,
+		Overwrite:       overwritePolicy(),
+		OversizeHead:    oversizeHead,
+		OversizeTail:    oversizeTail,
 		OutputType:      outputType,
 		CleanerOptions:  cleanerOpts,
+		MarkupOptions:   markupOpts,
+		Skeleton:        skeletonEnabled,
+		FullPatterns:    fullPatterns,
+		LanguageMap:     languageMap,
+		Transformers:    transformers,
+		Symbols:         symbols,
+		Depth:           depth,
+		Entries:         entries,
+		EntryDepth:      depth,
+		Query:           strings.TrimSpace(query),
+		MaxTokens:       maxTokens,
+		Fallback:        fallbackMode,
+		Filter:          filter,
+		MaxDepth:        maxDepth,
+		Hidden:          includeHidden,
 	}, nil
 }
 
@@ -239,6 +879,47 @@ func getCleanerOptions() *cleaner.CleanerOptions {
 	}
 }
 
+// getMarkupOptions creates notebook and document options based on command-line
+// flags, or returns nil when every transformation is disabled
+func getMarkupOptions() (*markup.MarkupOptions, error) {
+	mode, err := markup.ParseOutputMode(notebookOutputs)
+	if err != nil {
+		return nil, err
+	}
+	if notebookOutputLines < 0 {
+		return nil, fmt.Errorf("notebook-output-lines cannot be negative")
+	}
+	if mode == markup.OutputsTruncate && notebookOutputLines == 0 {
+		return nil, fmt.Errorf("notebook-output-lines must be at least 1 when truncating, use --notebook-outputs=drop to leave outputs out")
+	}
+	if !convertNotebooks && !stripBadges && !stripDocComments && !stripImages {
+		return nil, nil
+	}
+
+	return &markup.MarkupOptions{
+		ConvertNotebooks: convertNotebooks,
+		NotebookOutputs:  mode,
+		MaxOutputLines:   notebookOutputLines,
+		StripBadges:      stripBadges,
+		StripComments:    stripDocComments,
+		StripImages:      stripImages,
+	}, nil
+}
+
+// getTransformers creates the transformer chain from the --transform flags.
+// The "clean" transformer uses the cleaner flags, or the defaults without --clean.
+func getTransformers(cleanerOpts *cleaner.CleanerOptions) ([]core.Transformer, error) {
+	var chain []core.Transformer
+	for _, spec := range transforms {
+		t, err := core.ParseTransformer(spec, cleanerOpts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform: %w", err)
+		}
+		chain = append(chain, t)
+	}
+	return chain, nil
+}
+
 // validateOutputType validates and returns the output type
 func validateAndGetOutputType(outputPath string) (core.OutputType, error) {
 	if outputPath == "" {
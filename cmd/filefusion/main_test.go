@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/drgsn/filefusion/internal/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateAndGetConfig(t *testing.T) {
@@ -16,6 +20,8 @@ func TestValidateAndGetConfig(t *testing.T) {
 		maxFileSize   string
 		maxOutputSize string
 		outputPath    string
+		langMap       []string
+		oversize      string
 		expectError   bool
 	}{
 		{
@@ -56,6 +62,38 @@ func TestValidateAndGetConfig(t *testing.T) {
 			outputPath:    "output.invalid",
 			expectError:   true,
 		},
+		{
+			name:          "Valid language map",
+			pattern:       "*.go",
+			maxFileSize:   "10MB",
+			maxOutputSize: "50MB",
+			langMap:       []string{"*.inc=php"},
+			expectError:   false,
+		},
+		{
+			name:          "Unsupported language in language map",
+			pattern:       "*.go",
+			maxFileSize:   "10MB",
+			maxOutputSize: "50MB",
+			langMap:       []string{"*.inc=cobol"},
+			expectError:   true,
+		},
+		{
+			name:          "Truncate oversized files",
+			pattern:       "*.go",
+			maxFileSize:   "10MB",
+			maxOutputSize: "50MB",
+			oversize:      "truncate",
+			expectError:   false,
+		},
+		{
+			name:          "Invalid oversize mode",
+			pattern:       "*.go",
+			maxFileSize:   "10MB",
+			maxOutputSize: "50MB",
+			oversize:      "shrink",
+			expectError:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -66,6 +104,9 @@ func TestValidateAndGetConfig(t *testing.T) {
 			maxFileSize = tt.maxFileSize
 			maxOutputSize = tt.maxOutputSize
 			outputPath = tt.outputPath
+			langMap = tt.langMap
+			oversize = tt.oversize
+			defer func() { langMap, oversize = nil, "skip" }()
 
 			config, err := validateAndGetConfig([]string{})
 
@@ -79,6 +120,310 @@ func TestValidateAndGetConfig(t *testing.T) {
 	}
 }
 
+func TestSymlinksFlag(t *testing.T) {
+	defer func() { symlinks, ignoreSymlinks = "follow", false }()
+	pattern, maxFileSize, maxOutputSize, outputPath = "*.go", "10MB", "50MB", ""
+
+	tests := []struct {
+		name           string
+		symlinks       string
+		ignoreSymlinks bool
+		want           core.SymlinkPolicy
+		expectError    bool
+	}{
+		{name: "default", symlinks: "follow", want: core.SymlinkFollow},
+		{name: "record", symlinks: "record", want: core.SymlinkRecord},
+		{name: "deprecated ignore-symlinks", symlinks: "follow", ignoreSymlinks: true, want: core.SymlinkSkip},
+		{name: "invalid", symlinks: "copy", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symlinks, ignoreSymlinks = tt.symlinks, tt.ignoreSymlinks
+			config, err := validateAndGetConfig(nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config.Symlinks)
+		})
+	}
+
+	// Recorded links are bundled with their target and no content
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.Symlink("main.go", filepath.Join(dir, "alias.go")))
+	require.NoError(t, os.Symlink("gone.go", filepath.Join(dir, "broken.go")))
+
+	origWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(origWd)
+	require.NoError(t, os.Chdir(dir))
+
+	symlinks, outputPath = "record", "out.xml"
+	defer func() { outputPath = "" }()
+	require.NoError(t, runMix(rootCmd, []string{"."}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `target="main.go">`)
+	assert.Contains(t, string(data), `target="gone.go">`)
+	assert.Contains(t, string(data), "package main")
+}
+
+func TestTransformPatterns(t *testing.T) {
+	defer func() { transforms, outputPath = nil, "" }()
+	pattern, maxFileSize, maxOutputSize = "*.go", "10MB", "50MB"
+
+	// Patterns with a slash match the path below the input root, not below
+	// the working directory
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "proj", "gen"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "proj", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "proj", "gen", "gen.go"), []byte("package gen\n\nvar generated = 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "proj", "src", "main.go"), []byte("package main\n"), 0644))
+
+	origWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(origWd)
+	require.NoError(t, os.Chdir(dir))
+
+	transforms = []string{"gen/**=truncate:1", "/src/*.go=line-numbers"}
+	outputPath = "out.xml"
+	require.NoError(t, runMix(rootCmd, []string{"proj"}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "var generated")
+	assert.Contains(t, string(data), "1 | package main")
+}
+
+func TestFailureFlags(t *testing.T) {
+	defer func() { keepGoing, failFast, outputPath, reportPath = true, false, "", "" }()
+	pattern, maxFileSize, maxOutputSize = "*.go", "10MB", "50MB"
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.go"), []byte("package other\n"), 0644))
+
+	origWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(origWd)
+	require.NoError(t, os.Chdir(dir))
+
+	// The selected line is past the end of other.go, so processing it fails
+	tests := []struct {
+		name        string
+		keepGoing   bool
+		failFast    bool
+		wantPartial bool
+		wantOutput  bool
+	}{
+		{name: "keep going by default", keepGoing: true, wantPartial: true, wantOutput: true},
+		{name: "fail fast", keepGoing: true, failFast: true},
+		{name: "no keep going", keepGoing: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepGoing, failFast = tt.keepGoing, tt.failFast
+			outputPath, reportPath = "out.xml", "report.json"
+			os.Remove(outputPath)
+			os.Remove(reportPath)
+
+			config, err := validateAndGetConfig(nil)
+			require.NoError(t, err)
+			assert.Equal(t, !tt.wantPartial, config.FailFast)
+
+			var stderr bytes.Buffer
+			rootCmd.SetErr(&stderr)
+			defer rootCmd.SetErr(nil)
+
+			err = runMix(rootCmd, []string{"main.go", "other.go:50"})
+			require.Error(t, err)
+
+			var partial *partialError
+			assert.Equal(t, tt.wantPartial, errors.As(err, &partial))
+			var failures *core.MultiError
+			require.True(t, errors.As(err, &failures))
+			require.Equal(t, 1, failures.Len())
+			assert.Equal(t, core.StageProcess, failures.Errors[0].Stage)
+			assert.Contains(t, stderr.String(), "other.go")
+			assert.Contains(t, stderr.String(), "1 file failed")
+
+			_, statErr := os.Stat(outputPath)
+			assert.Equal(t, tt.wantOutput, statErr == nil)
+			if tt.wantOutput {
+				data, err := os.ReadFile(reportPath)
+				require.NoError(t, err)
+				assert.Contains(t, string(data), `"status": "failed"`)
+				data, err = os.ReadFile(outputPath)
+				require.NoError(t, err)
+				assert.Contains(t, string(data), "package main")
+				assert.NotContains(t, string(data), "package other")
+			}
+		})
+	}
+}
+
+func TestEmptySelection(t *testing.T) {
+	defer func() { outputPath = "" }()
+	pattern, maxFileSize, maxOutputSize = "*.go", "10MB", "50MB"
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc run() {}\n"), 0644))
+
+	origWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(origWd)
+	require.NoError(t, os.Chdir(dir))
+
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetErr(nil)
+
+	outputPath = "out.xml"
+	require.NoError(t, runMix(rootCmd, []string{"main.go#run"}))
+	before, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	// A missing symbol fails the run before the previous bundle is replaced
+	err = runMix(rootCmd, []string{"main.go#missing"})
+	require.Error(t, err)
+	var partial *partialError
+	assert.False(t, errors.As(err, &partial))
+
+	after, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestOverwriteFlags(t *testing.T) {
+	defer func() { force, noClobber, backup, outputPath, pattern = false, false, false, "", "*.go" }()
+	pattern, maxFileSize, maxOutputSize = "*.go", "10MB", "50MB"
+
+	tests := []struct {
+		name      string
+		force     bool
+		noClobber bool
+		backup    bool
+		want      core.OverwritePolicy
+	}{
+		{name: "default", want: core.OverwriteBundles},
+		{name: "force", force: true, want: core.OverwriteForce},
+		{name: "no clobber", noClobber: true, want: core.OverwriteNoClobber},
+		{name: "backup", backup: true, want: core.OverwriteBackup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			force, noClobber, backup = tt.force, tt.noClobber, tt.backup
+			config, err := validateAndGetConfig(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config.Overwrite)
+		})
+	}
+
+	// An absolute output path is used as given
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.xml"), []byte("my notes"), 0644))
+	force, noClobber, backup = false, false, false
+	outputPath = filepath.Join(dir, "notes.xml")
+
+	err := runMix(rootCmd, []string{dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a bundle")
+
+	backup = true
+	require.NoError(t, runMix(rootCmd, []string{dir}))
+	data, err := os.ReadFile(outputPath + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "my notes", string(data))
+	data, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package main")
+
+	// The output matched by the pattern is not bundled into itself
+	backup, pattern = false, "*.go,*.xml"
+	require.NoError(t, runMix(rootCmd, []string{dir}))
+	data, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "notes.xml</source>")
+
+	// An output reached as a selected input under another name is refused
+	link := filepath.Join(dir, "link.go")
+	require.NoError(t, os.Link(outputPath, link))
+	pattern = "*.go"
+	err = runMix(rootCmd, []string{dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is the selected input")
+}
+
+func TestFilterFlags(t *testing.T) {
+	defer func() { filterTerms, maxDepth, includeHidden = nil, 0, true }()
+	pattern, maxFileSize, maxOutputSize, outputPath = "*.go", "10MB", "50MB", ""
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantTerms   []core.FilterTerm
+		wantFilter  string
+		expectError bool
+	}{
+		{
+			name:       "No filters",
+			args:       []string{},
+			wantFilter: "",
+		},
+		{
+			name:       "Alternatives in command-line order",
+			args:       []string{"--contains", "TODO", "--newer-than", "7d", "--or", "--min-size", "1KB"},
+			wantTerms:  []core.FilterTerm{{Name: "contains", Value: "TODO"}, {Name: "newer-than", Value: "7d"}, {Name: "or"}, {Name: "min-size", Value: "1KB"}},
+			wantFilter: "--newer-than 7d --contains TODO --or --min-size 1KB",
+		},
+		{
+			name:        "Trailing operator",
+			args:        []string{"--min-size", "1KB", "--and"},
+			wantTerms:   []core.FilterTerm{{Name: "min-size", Value: "1KB"}, {Name: "and"}},
+			expectError: true,
+		},
+		{
+			name:        "Negative max depth",
+			args:        []string{"--max-depth", "-1"},
+			expectError: true,
+		},
+		{
+			name:       "Depth and hidden files",
+			args:       []string{"--max-depth", "3", "--hidden=false"},
+			wantFilter: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filterTerms, maxDepth, includeHidden = nil, 0, true
+			assert.NoError(t, rootCmd.PersistentFlags().Parse(tt.args))
+			assert.Equal(t, tt.wantTerms, filterTerms)
+
+			config, err := validateAndGetConfig([]string{})
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantFilter == "" {
+				assert.Nil(t, config.Filter)
+			} else {
+				assert.Equal(t, tt.wantFilter, config.Filter.String())
+			}
+			assert.Equal(t, maxDepth, config.MaxDepth)
+			assert.Equal(t, includeHidden, config.Hidden)
+		})
+	}
+}
+
 func TestValidateAndGetOutputType(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -169,6 +514,85 @@ func TestGetCleanerOptions(t *testing.T) {
 	}
 }
 
+func TestGetMarkupOptions(t *testing.T) {
+	defer func() {
+		convertNotebooks, notebookOutputs, notebookOutputLines = true, "truncate", 10
+		stripBadges, stripDocComments, stripImages = false, false, false
+	}()
+
+	tests := []struct {
+		name        string
+		notebooks   bool
+		outputs     string
+		lines       int
+		stripImages bool
+		wantNil     bool
+		expectError bool
+	}{
+		{name: "Defaults", notebooks: true, outputs: "truncate", lines: 10},
+		{name: "Everything disabled", notebooks: false, outputs: "truncate", lines: 10, wantNil: true},
+		{name: "Stripping without notebooks", notebooks: false, outputs: "drop", stripImages: true},
+		{name: "Invalid outputs mode", notebooks: true, outputs: "all", expectError: true},
+		{name: "Negative line count", notebooks: true, outputs: "keep", lines: -1, expectError: true},
+		{name: "Truncating to no lines", notebooks: true, outputs: "truncate", lines: 0, expectError: true},
+		{name: "No lines when dropping", notebooks: true, outputs: "drop", lines: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertNotebooks = tt.notebooks
+			notebookOutputs = tt.outputs
+			notebookOutputLines = tt.lines
+			stripImages = tt.stripImages
+
+			opts, err := getMarkupOptions()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, opts)
+			} else {
+				assert.NotNil(t, opts)
+				assert.Equal(t, tt.notebooks, opts.ConvertNotebooks)
+				assert.Equal(t, tt.stripImages, opts.StripImages)
+			}
+		})
+	}
+}
+
+func TestGetTransformers(t *testing.T) {
+	defer func() { transforms = nil }()
+
+	tests := []struct {
+		name        string
+		transforms  []string
+		wantLen     int
+		expectError bool
+	}{
+		{name: "No transformers", wantLen: 0},
+		{name: "Chain in order", transforms: []string{"redact", "*.go=line-numbers", "truncate:100"}, wantLen: 3},
+		{name: "External transformer", transforms: []string{"exec:jq -c ."}, wantLen: 1},
+		{name: "Unknown transformer", transforms: []string{"uppercase"}, expectError: true},
+		{name: "Invalid argument", transforms: []string{"truncate:-1"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transforms = tt.transforms
+
+			chain, err := getTransformers(nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, chain, tt.wantLen)
+		})
+	}
+}
+
 func TestRunMix(t *testing.T) {
 	// Save current working directory
 	origWd, err := os.Getwd()
@@ -253,3 +677,108 @@ func TestRunMix(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureLogging(t *testing.T) {
+	defer func() {
+		logFormat = "text"
+		quiet = false
+		verbosity = 0
+		_ = configureLogging()
+	}()
+
+	tests := []struct {
+		name        string
+		format      string
+		quiet       bool
+		verbosity   int
+		expectError bool
+	}{
+		{name: "Text format", format: "text"},
+		{name: "JSON format", format: "json", verbosity: 2},
+		{name: "Quiet", format: "text", quiet: true},
+		{name: "Invalid format", format: "xml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logFormat = tt.format
+			quiet = tt.quiet
+			verbosity = tt.verbosity
+
+			err := configureLogging()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, core.Logger())
+			}
+		})
+	}
+}
+
+func TestProvenanceOptions(t *testing.T) {
+	defer func() {
+		pattern, exclude, outputPath, quiet, provenance = "*.go,*.json,*.yaml,*.yml", "", "", false, false
+	}()
+	pattern, exclude, maxFileSize, maxOutputSize = "*.go", "vendor/**", "10MB", "50MB"
+	outputPath, quiet, provenance = "bundle.json", true, true
+
+	config, err := validateAndGetConfig(nil)
+	require.NoError(t, err)
+	options := provenanceOptions(rootCmd, config)
+
+	values := map[string]string{}
+	for _, option := range options {
+		values[option.Name] = option.Value
+	}
+	assert.Equal(t, "json", values["format"])
+	assert.Equal(t, "*.go", values["pattern"])
+	assert.Equal(t, "vendor/**", values["exclude"])
+	// Flags left at their default still affect the bundle and are recorded
+	assert.Equal(t, "10MB", values["max-file-size"])
+	assert.Equal(t, "skip", values["oversize"])
+	assert.Contains(t, values, "filter")
+	assert.Equal(t, "", values["filter"])
+	for _, name := range []string{"output", "quiet", "provenance", "ignore-symlinks"} {
+		assert.NotContains(t, values, name, "%s is not recorded", name)
+	}
+
+	// The bundle carries the options when asked to
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	outputPath = filepath.Join(dir, "bundle.json")
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	require.NoError(t, runMix(rootCmd, []string{dir}))
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"generated_at": "2023-11-14T22:13:20Z"`)
+	assert.Contains(t, string(data), `"name": "exclude"`)
+	assert.Contains(t, string(data), `"config_hash": "sha256:`)
+	assert.Contains(t, string(data), `"path": "`+dir+`"`)
+}
+
+func TestEntryRules(t *testing.T) {
+	defer func() { entries, exclude, outputPath = nil, "", "" }()
+	pattern, maxFileSize, maxOutputSize = "*.go,*.json,*.yaml,*.yml", "10MB", "50MB"
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.23\n",
+		"main.go":    "package main\n\nimport (\n\t\"example.com/app/gen\"\n\t\"example.com/app/lib\"\n)\n\nfunc main() { lib.Run(); gen.Run() }\n",
+		"lib/lib.go": "package lib\n\nfunc Run() {}\n",
+		"gen/gen.go": "package gen\n\nfunc Run() {}\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	entries, exclude = []string{filepath.Join(dir, "main.go")}, "gen/**"
+	outputPath = filepath.Join(dir, "bundle.xml")
+	require.NoError(t, runMix(rootCmd, []string{dir}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "lib.go</source>")
+	assert.NotContains(t, string(data), "gen.go</source>", "excluded imports are left out")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"text/tabwriter"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+	"github.com/spf13/cobra"
+)
+
+// Build information, set by the Makefile with -ldflags "-X main.version=..."
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// versionCmd prints the version of filefusion and of the grammars it parses with
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit, Go version and tree-sitter grammar versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeVersion(cmd.OutOrStdout(), readBuildInfo())
+	},
+}
+
+// buildInfo describes the binary that is running
+type buildInfo struct {
+	Version   string
+	Commit    string
+	Modified  bool // Whether the binary was built from a tree with local changes
+	GoVersion string
+	Platform  string
+	Bindings  string // Version of the tree-sitter bindings module
+}
+
+// readBuildInfo combines the linker flags with the build information Go
+// embeds, which covers binaries installed with go install
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == cleaner.BindingsModule {
+			info.Bindings = dep.Version
+		}
+	}
+	return info
+}
+
+// writeVersion prints the build information followed by the grammar versions
+func writeVersion(w io.Writer, info buildInfo) error {
+	fmt.Fprintf(w, "filefusion %s\n", info.Version)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	commit := info.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	if info.Modified {
+		commit += " (modified)"
+	}
+	fmt.Fprintf(tw, "commit:\t%s\n", commit)
+	fmt.Fprintf(tw, "go:\t%s %s\n", info.GoVersion, info.Platform)
+	if info.Bindings != "" {
+		fmt.Fprintf(tw, "tree-sitter:\t%s %s\n", cleaner.BindingsModule, info.Bindings)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "grammars:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, grammar := range cleaner.Grammars() {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", grammar.Language, grammar.Version, grammar.Revision)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteVersion(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeVersion(&buf, buildInfo{
+		Version:   "v1.2.3",
+		Commit:    "0123abc",
+		Modified:  true,
+		GoVersion: "go1.23.0",
+		Platform:  "linux/amd64",
+		Bindings:  "v0.0.0-20240827094217-dd81d9e9be82",
+	}))
+	out := buf.String()
+
+	assert.Contains(t, out, "filefusion v1.2.3\n")
+	assert.Contains(t, out, "0123abc (modified)")
+	assert.Contains(t, out, "go1.23.0 linux/amd64")
+	assert.Contains(t, out, "github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82")
+	assert.Contains(t, out, "grammars:\n")
+	assert.Regexp(t, `\n  go\s+master\s+6204b7308a32\n`, out)
+
+	// Without build information the commit is unknown and the bindings left out
+	buf.Reset()
+	require.NoError(t, writeVersion(&buf, buildInfo{Version: "dev"}))
+	assert.Contains(t, buf.String(), "unknown")
+	assert.NotContains(t, buf.String(), "tree-sitter:")
+}
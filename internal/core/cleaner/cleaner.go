@@ -9,20 +9,28 @@ import (
 	"github.com/drgsn/filefusion/internal/core/cleaner/handlers"
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/bash"
+	cgrammar "github.com/smacker/go-tree-sitter/c"
 	"github.com/smacker/go-tree-sitter/cpp"
 	"github.com/smacker/go-tree-sitter/csharp"
 	"github.com/smacker/go-tree-sitter/css"
+	"github.com/smacker/go-tree-sitter/elixir"
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/smacker/go-tree-sitter/html"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
 	"github.com/smacker/go-tree-sitter/kotlin"
+	"github.com/smacker/go-tree-sitter/lua"
+	"github.com/smacker/go-tree-sitter/ocaml"
 	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
 	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/scala"
 	"github.com/smacker/go-tree-sitter/sql"
 	"github.com/smacker/go-tree-sitter/swift"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
+	"github.com/smacker/go-tree-sitter/yaml"
 )
 
 // Cleaner represents a code cleaner instance that processes source code
@@ -73,8 +81,13 @@ func getLanguageAndHandler(lang Language) (*sitter.Language, handlers.LanguageHa
 		return javascript.GetLanguage(), &handlers.JavaScriptHandler{}, nil
 	case LangTypeScript:
 		return typescript.GetLanguage(), &handlers.TypeScriptHandler{}, nil
+	case LangTSX:
+		// TSX uses its own grammar for JSX elements but the TypeScript node types
+		return tsx.GetLanguage(), &handlers.TypeScriptHandler{}, nil
 	case LangCSS:
 		return css.GetLanguage(), &handlers.CSSHandler{}, nil
+	case LangC:
+		return cgrammar.GetLanguage(), &handlers.CHandler{}, nil
 	case LangCPP:
 		return cpp.GetLanguage(), &handlers.CPPHandler{}, nil
 	case LangCSharp:
@@ -85,6 +98,18 @@ func getLanguageAndHandler(lang Language) (*sitter.Language, handlers.LanguageHa
 		return ruby.GetLanguage(), &handlers.RubyHandler{}, nil
 	case LangBash:
 		return bash.GetLanguage(), &handlers.BashHandler{}, nil
+	case LangRust:
+		return rust.GetLanguage(), &handlers.RustHandler{}, nil
+	case LangLua:
+		return lua.GetLanguage(), &handlers.LuaHandler{}, nil
+	case LangScala:
+		return scala.GetLanguage(), &handlers.ScalaHandler{}, nil
+	case LangElixir:
+		return elixir.GetLanguage(), &handlers.ElixirHandler{}, nil
+	case LangOCaml:
+		return ocaml.GetLanguage(), &handlers.OCamlHandler{}, nil
+	case LangYAML:
+		return yaml.GetLanguage(), &handlers.YAMLHandler{}, nil
 	default:
 		return nil, nil, fmt.Errorf("unsupported language: %s", lang)
 	}
@@ -96,42 +121,54 @@ func (c *Cleaner) Clean(input []byte) ([]byte, error) {
 		return nil, fmt.Errorf("empty input")
 	}
 
-	// Create a new parser for each Clean call to avoid concurrency issues
+	tree, root, err := c.parse(input)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	output := make([]byte, len(input))
+	copy(output, input)
+
+	if err := c.processNode(root, &output); err != nil {
+		return nil, fmt.Errorf("processing error: %w", err)
+	}
+
+	if c.options.OptimizeWhitespace {
+		output = c.optimizeWhitespace(output)
+	}
+
+	return output, nil
+}
+
+// parse builds a syntax tree for the input and verifies that it is valid
+func (c *Cleaner) parse(input []byte) (*sitter.Tree, *sitter.Node, error) {
+	// Create a new parser for each call to avoid concurrency issues
 	parser := sitter.NewParser()
 	language, _, err := getLanguageAndHandler(c.language)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get language handler: %w", err)
+		return nil, nil, fmt.Errorf("failed to get language handler: %w", err)
 	}
 	parser.SetLanguage(language)
 
 	tree := parser.Parse(nil, input)
 	if tree == nil {
-		return nil, fmt.Errorf("parsing error: failed to create syntax tree")
+		return nil, nil, fmt.Errorf("parsing error: failed to create syntax tree")
 	}
-	defer tree.Close()
 
 	root := tree.RootNode()
 	if root == nil {
-		return nil, fmt.Errorf("parsing error: empty syntax tree")
+		tree.Close()
+		return nil, nil, fmt.Errorf("parsing error: empty syntax tree")
 	}
 
 	// Verify the syntax is valid
 	if root.HasError() {
-		return nil, fmt.Errorf("parsing error: invalid syntax")
-	}
-
-	output := make([]byte, len(input))
-	copy(output, input)
-
-	if err := c.processNode(root, &output); err != nil {
-		return nil, fmt.Errorf("processing error: %w", err)
-	}
-
-	if c.options.OptimizeWhitespace {
-		output = c.optimizeWhitespace(output)
+		tree.Close()
+		return nil, nil, fmt.Errorf("parsing error: invalid syntax")
 	}
 
-	return output, nil
+	return tree, root, nil
 }
 
 // processNode recursively processes a node in the syntax tree
@@ -169,8 +206,15 @@ func (c *Cleaner) processNode(node *sitter.Node, content *[]byte) error {
 		shouldRemove = true
 	}
 
-	// Remove the node if necessary
+	// Remove the node if necessary, together with the code the language
+	// requires to go with it
 	if shouldRemove {
+		if removal, ok := c.handler.(handlers.RemovalHandler); ok && !c.isComment(node) {
+			if r := removal.GetRemovalRange(node, *content); r != nil {
+				*content = c.removeRange(r.Start, r.End, r.Placeholder, *content)
+				return nil
+			}
+		}
 		*content = c.removeNode(node, *content)
 	}
 
@@ -202,11 +246,16 @@ func (c *Cleaner) shouldRemoveComment(node *sitter.Node, content []byte) bool {
 
 	if c.options.PreserveDocComments {
 		commentText := content[node.StartByte():node.EndByte()]
-		docPrefix := c.handler.GetDocCommentPrefix()
+		docPrefixes := []string{c.handler.GetDocCommentPrefix()}
+		if docHandler, ok := c.handler.(handlers.DocCommentHandler); ok {
+			docPrefixes = docHandler.GetDocCommentPrefixes()
+		}
 
 		// Handle both byte slices and string prefixes
-		if bytes.HasPrefix(bytes.TrimSpace(commentText), []byte(docPrefix)) {
-			return false
+		for _, docPrefix := range docPrefixes {
+			if bytes.HasPrefix(bytes.TrimSpace(commentText), []byte(docPrefix)) {
+				return false
+			}
 		}
 
 		// Special handling for single-line comments in Go
@@ -221,12 +270,28 @@ func (c *Cleaner) shouldRemoveComment(node *sitter.Node, content []byte) bool {
 	return true
 }
 
+// isComment reports whether a node is a comment of the language
+func (c *Cleaner) isComment(node *sitter.Node) bool {
+	for _, commentType := range c.handler.GetCommentTypes() {
+		if node.Type() == commentType {
+			return true
+		}
+	}
+	return false
+}
+
 // removeNode removes a node from the content while preserving the surrounding
 // content
 func (c *Cleaner) removeNode(node *sitter.Node, content []byte) []byte {
-	// Get the start and end of the line containing the node
-	start := node.StartByte()
-	end := node.EndByte()
+	return c.removeRange(node.StartByte(), node.EndByte(), "", content)
+}
+
+// removeRange replaces the bytes from start to end with the placeholder. A
+// range that is alone on its lines is removed with them.
+func (c *Cleaner) removeRange(start, end uint32, placeholder string, content []byte) []byte {
+	if end > uint32(len(content)) || start > end {
+		return content
+	}
 
 	// Find start of line
 	lineStart := int(start)
@@ -243,18 +308,22 @@ func (c *Cleaner) removeNode(node *sitter.Node, content []byte) []byte {
 	// If the line contains only this node (plus whitespace), remove the entire line
 	line := bytes.TrimSpace(content[lineStart:lineEnd])
 	nodeContent := bytes.TrimSpace(content[start:end])
-	if bytes.Equal(line, nodeContent) {
+	if placeholder == "" && bytes.Equal(line, nodeContent) {
 		return append(content[:lineStart], content[lineEnd:]...)
 	}
 
-	// Otherwise just remove the node itself
-	return append(content[:start], content[end:]...)
+	// Otherwise just replace the node itself
+	result := append([]byte(nil), content[:start]...)
+	result = append(result, placeholder...)
+	return append(result, content[end:]...)
 }
 
 // optimizeWhitespace removes excess whitespace and optionally empty lines
 // from the content
 func (c *Cleaner) optimizeWhitespace(content []byte) []byte {
-	if !c.options.OptimizeWhitespace {
+	// Blank lines and trailing spaces can be part of a value in YAML, such as
+	// a block scalar, so they are left as they are
+	if !c.options.OptimizeWhitespace || c.language == LangYAML {
 		return content
 	}
 
@@ -124,6 +124,98 @@ func TestClean(t *testing.T) {
 			shouldContain:  []string{"otherMethod"},
 			shouldNotMatch: []string{"getName"},
 		},
+		{
+			name:  "remove Rust comments and logging",
+			lang:  LangRust,
+			input: "/// Entry point\nfn main() {\n    // note\n    println!(\"debug\");\n    run();\n}\n",
+			options: &CleanerOptions{
+				RemoveComments:      true,
+				PreserveDocComments: true,
+				RemoveLogging:       true,
+			},
+			shouldContain:  []string{"/// Entry point", "run();"},
+			shouldNotMatch: []string{"// note", "println!"},
+		},
+		{
+			name:  "remove Rust logging statements only",
+			lang:  LangRust,
+			input: "fn main() {\n    log::info!(\"start\");\n    let y = dbg!(x);\n    match y {\n        Some(v) => println!(\"{}\", v),\n        None => {}\n    }\n}\n",
+			options: &CleanerOptions{
+				RemoveLogging:      true,
+				OptimizeWhitespace: true,
+				RemoveEmptyLines:   true,
+			},
+			expected: "fn main() {\n    let y = dbg!(x);\n    match y {\n        Some(v) => println!(\"{}\", v),\n        None => {}\n    }\n}\n",
+		},
+		{
+			name:  "remove YAML comments",
+			lang:  LangYAML,
+			input: "# settings\nname: app # inline\nport: 8080\n",
+			options: &CleanerOptions{
+				RemoveComments:      true,
+				PreserveDocComments: true,
+			},
+			shouldContain:  []string{"name: app", "port: 8080"},
+			shouldNotMatch: []string{"# settings", "# inline"},
+		},
+		{
+			name:  "keep YAML doc comments and block scalars",
+			lang:  LangYAML,
+			input: "## The greeting\n# plain\nmessage: |\n  hello\n\n  world\nport: 8080\n",
+			options: &CleanerOptions{
+				RemoveComments:      true,
+				PreserveDocComments: true,
+				OptimizeWhitespace:  true,
+				RemoveEmptyLines:    true,
+			},
+			expected: "## The greeting\n\nmessage: |\n  hello\n\n  world\nport: 8080\n",
+		},
+		{
+			name:  "remove OCaml logging from sequences",
+			lang:  LangOCaml,
+			input: "let f x =\n  print_endline \"hi\";\n  x + 1\n\nlet g x =\n  f x;\n  Printf.printf \"%d\" x\n\nlet () = print_endline \"debug\"\n",
+			options: &CleanerOptions{
+				RemoveLogging:      true,
+				OptimizeWhitespace: true,
+				RemoveEmptyLines:   true,
+			},
+			expected: "let f x =\n  x + 1\nlet g x =\n  f x\nlet () = ()\n",
+		},
+		{
+			name:  "remove OCaml accessors with their definition",
+			lang:  LangOCaml,
+			input: "let get_name p = p.name\nlet area r = r.w * r.h\n",
+			options: &CleanerOptions{
+				RemoveGettersSetters: true,
+				OptimizeWhitespace:   true,
+				RemoveEmptyLines:     true,
+			},
+			expected: "let area r = r.w * r.h\n",
+		},
+		{
+			name:  "remove TSX comments and logging",
+			lang:  LangTSX,
+			input: "// helper\nexport function App(p: Props) {\n  console.log(p);\n  return <div>{p.name}</div>;\n}\n",
+			options: &CleanerOptions{
+				RemoveComments: true,
+				RemoveLogging:  true,
+			},
+			shouldContain:  []string{"<div>{p.name}</div>"},
+			shouldNotMatch: []string{"// helper", "console.log"},
+		},
+		{
+			name:  "clean C without removing accessors",
+			lang:  LangC,
+			input: "#include <stdio.h>\n/** Returns x. */\nint get_x(struct point *p) {\n    // read\n    printf(\"get\\n\");\n    return p->x;\n}\n/// Sets x.\nvoid set_x(struct point *p, int x) { p->x = x; }\n",
+			options: &CleanerOptions{
+				RemoveComments:       true,
+				PreserveDocComments:  true,
+				RemoveLogging:        true,
+				RemoveGettersSetters: true,
+			},
+			shouldContain:  []string{"#include <stdio.h>", "/** Returns x. */", "/// Sets x.", "int get_x", "void set_x"},
+			shouldNotMatch: []string{"// read", "printf"},
+		},
 		{
 			name:  "optimize whitespace",
 			lang:  LangGo,
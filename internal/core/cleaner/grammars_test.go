@@ -0,0 +1,21 @@
+package cleaner
+
+import "testing"
+
+func TestGrammars(t *testing.T) {
+	got := Grammars()
+	if len(got) != len(GetSupportedLanguages()) {
+		t.Errorf("Grammars() returned %d grammars, want one per supported language (%d)", len(got), len(GetSupportedLanguages()))
+	}
+	for _, lang := range GetSupportedLanguages() {
+		grammar, ok := grammars[lang]
+		if !ok || grammar.Version == "" || grammar.Revision == "" {
+			t.Errorf("no grammar version recorded for %s", lang)
+		}
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Language >= got[i].Language {
+			t.Errorf("Grammars() not ordered by language: %s before %s", got[i-1].Language, got[i].Language)
+		}
+	}
+}
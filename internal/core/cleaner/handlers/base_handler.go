@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"bytes"
 	"strings"
+	"unicode"
 
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -15,9 +17,100 @@ type LanguageHandler interface {
 	IsGetterSetter(node *sitter.Node, content []byte) bool
 }
 
+// SkeletonHandler is implemented by language handlers that support skeleton
+// mode, where declarations are kept and function bodies are elided
+type SkeletonHandler interface {
+	GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange
+}
+
+// SymbolHandler is implemented by language handlers that support symbol
+// extraction, where named declarations and their dependencies are selected
+type SymbolHandler interface {
+	// GetDeclaration returns the names declared by a node and, for members,
+	// the name of the enclosing type
+	GetDeclaration(node *sitter.Node, content []byte) (names []string, container string, ok bool)
+	// GetReferenceTypes returns the node types of identifiers that may refer
+	// to other declarations
+	GetReferenceTypes() []string
+	// GetPreambleTypes returns the top-level node types, such as package
+	// clauses and imports, that are kept with every extracted declaration
+	GetPreambleTypes() []string
+}
+
+// PackageHandler is implemented by language handlers whose files declare the
+// package or namespace they belong to, used to address symbols as "pkg.Name"
+type PackageHandler interface {
+	// GetPackageName returns the package declared by a top-level node, with
+	// the parts of a qualified name separated by dots
+	GetPackageName(node *sitter.Node, content []byte) string
+}
+
+// ImportHandler is implemented by language handlers that can report the
+// modules a file imports, used to follow dependencies between local files
+type ImportHandler interface {
+	// GetImportPaths returns the import specifiers declared by a node, such as
+	// a Go import path, a JavaScript module specifier or a dotted Python module
+	GetImportPaths(node *sitter.Node, content []byte) []string
+}
+
+// DocCommentHandler is implemented by language handlers that recognize more
+// than one documentation comment style
+type DocCommentHandler interface {
+	// GetDocCommentPrefixes returns every prefix that marks a documentation
+	// comment, replacing GetDocCommentPrefix
+	GetDocCommentPrefixes() []string
+}
+
+// RemovalHandler is implemented by language handlers where removing a logging
+// call or an accessor on its own would leave invalid code, such as the
+// separator after an expression in a sequence
+type RemovalHandler interface {
+	// GetRemovalRange returns the bytes removed together with a node and the
+	// text written in their place, or nil to remove the node alone
+	GetRemovalRange(node *sitter.Node, content []byte) *BodyRange
+}
+
+// BodyRange describes the part of a declaration that is replaced in skeleton mode
+type BodyRange struct {
+	Start       uint32 // First byte of the elided body
+	End         uint32 // Byte after the elided body
+	Placeholder string // Text written in place of the body
+}
+
 // BaseHandler provides common functionality for all language handlers
 type BaseHandler struct{}
 
+// BracedBody returns the range of a brace-delimited body of a declaration of one
+// of the given types. The body is the node's "body" field if present, otherwise
+// its first named child of one of the body types.
+func (h *BaseHandler) BracedBody(node *sitter.Node, content []byte, declTypes, bodyTypes []string) *BodyRange {
+	if node == nil || !containsString(declTypes, node.Type()) {
+		return nil
+	}
+
+	body := node.ChildByFieldName("body")
+	if body == nil {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); containsString(bodyTypes, child.Type()) {
+				body = child
+				break
+			}
+		}
+	}
+	if body == nil || !containsString(bodyTypes, body.Type()) {
+		return nil
+	}
+	if body.EndByte() > uint32(len(content)) || !bytes.HasPrefix(content[body.StartByte():], []byte("{")) {
+		return nil
+	}
+
+	return &BodyRange{
+		Start:       body.StartByte(),
+		End:         body.EndByte(),
+		Placeholder: "{ ... }",
+	}
+}
+
 // IsMethodNamed checks if a node represents a method/function with the given prefix
 func (h *BaseHandler) IsMethodNamed(node *sitter.Node, content []byte, prefix string) bool {
 	if node.Type() != "method_declaration" &&
@@ -36,6 +129,113 @@ func (h *BaseHandler) IsMethodNamed(node *sitter.Node, content []byte, prefix st
 	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix))
 }
 
+// NamedDeclaration returns the "name" field of a node of one of the declaration
+// types together with the name of the closest enclosing container type
+func (h *BaseHandler) NamedDeclaration(node *sitter.Node, content []byte, declTypes, containerTypes []string) ([]string, string, bool) {
+	if node == nil || !containsString(declTypes, node.Type()) {
+		return nil, "", false
+	}
+	names := fieldTexts(node, content, "name")
+	if len(names) == 0 {
+		return nil, "", false
+	}
+	return names[:1], h.ContainerName(node, content, containerTypes), true
+}
+
+// ContainerName returns the name of the closest ancestor of one of the container types
+func (h *BaseHandler) ContainerName(node *sitter.Node, content []byte, containerTypes []string) string {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if !containsString(containerTypes, parent.Type()) {
+			continue
+		}
+		if names := fieldTexts(parent, content, "name"); len(names) > 0 {
+			return names[0]
+		}
+		if name := firstChildText(parent, content, "type_identifier", "identifier", "constant", "name"); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// fieldTexts returns the text of every child stored under the given field name
+func fieldTexts(node *sitter.Node, content []byte, field string) []string {
+	var texts []string
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != field {
+			continue
+		}
+		child := node.Child(i)
+		if child.EndByte() <= uint32(len(content)) {
+			texts = append(texts, string(content[child.StartByte():child.EndByte()]))
+		}
+	}
+	return texts
+}
+
+// findFirstText returns the text of the first descendant of the given type
+func findFirstText(node *sitter.Node, content []byte, nodeType string) string {
+	if node.Type() == nodeType && node.EndByte() <= uint32(len(content)) {
+		return string(content[node.StartByte():node.EndByte()])
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if text := findFirstText(node.NamedChild(i), content, nodeType); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// firstChildText returns the text of the first named child of one of the given types
+func firstChildText(node *sitter.Node, content []byte, types ...string) string {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if containsString(types, child.Type()) && child.EndByte() <= uint32(len(content)) {
+			return string(content[child.StartByte():child.EndByte()])
+		}
+	}
+	return ""
+}
+
+// isAccessorName reports whether a name follows the get_x/set_x or getX/setX
+// naming convention, without matching words such as "settings"
+func isAccessorName(name string) bool {
+	for _, prefix := range []string{"get", "set", "Get", "Set"} {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			next := rune(name[len(prefix)])
+			if next == '_' || unicode.IsUpper(next) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeText returns the text of a node, or an empty string when it lies outside the content
+func nodeText(node *sitter.Node, content []byte) string {
+	if node == nil || node.EndByte() > uint32(len(content)) {
+		return ""
+	}
+	return string(content[node.StartByte():node.EndByte()])
+}
+
+// unquote strips the quotes or backticks surrounding a string literal
+func unquote(text string) string {
+	if len(text) >= 2 && strings.ContainsRune("\"'`", rune(text[0])) && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func stringSliceEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -76,3 +76,35 @@ func TestBaseHandler(t *testing.T) {
 		})
 	}
 }
+
+// collectNodeText returns the text of every named node of the given type below
+// root, in source order
+func collectNodeText(root *sitter.Node, nodeType string, content []byte) []string {
+	if root == nil {
+		return nil
+	}
+	var texts []string
+	if root.IsNamed() && root.Type() == nodeType {
+		texts = append(texts, root.Content(content))
+	}
+	for i := 0; i < int(root.ChildCount()); i++ {
+		texts = append(texts, collectNodeText(root.Child(i), nodeType, content)...)
+	}
+	return texts
+}
+
+// anyNode reports whether match holds for root or a node below it
+func anyNode(root *sitter.Node, match func(*sitter.Node) bool) bool {
+	if root == nil {
+		return false
+	}
+	if match(root) {
+		return true
+	}
+	for i := 0; i < int(root.ChildCount()); i++ {
+		if anyNode(root.Child(i), match) {
+			return true
+		}
+	}
+	return false
+}
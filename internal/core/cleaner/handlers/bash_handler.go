@@ -52,3 +52,21 @@ func (h *BashHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
 func (h *BashHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 	return false
 }
+
+func (h *BashHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_definition"},
+		[]string{"compound_statement"})
+}
+
+func (h *BashHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	return h.NamedDeclaration(node, content, []string{"function_definition"}, nil)
+}
+
+func (h *BashHandler) GetReferenceTypes() []string {
+	return []string{"command_name"}
+}
+
+func (h *BashHandler) GetPreambleTypes() []string {
+	return nil
+}
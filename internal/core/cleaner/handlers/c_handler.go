@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// CHandler handles C language specifics
+type CHandler struct {
+	BaseHandler
+}
+
+// cLoggingFunctions lists the standard functions that print diagnostics
+var cLoggingFunctions = []string{"printf", "vprintf", "puts", "perror", "syslog", "vsyslog"}
+
+// cStreamLoggingFunctions lists the functions that only log when they write to
+// stdout or stderr, as opposed to a file
+var cStreamLoggingFunctions = []string{"fprintf", "vfprintf", "fputs"}
+
+func (h *CHandler) GetCommentTypes() []string {
+	return []string{"comment"}
+}
+
+func (h *CHandler) GetImportTypes() []string {
+	return []string{"preproc_include"}
+}
+
+func (h *CHandler) GetDocCommentPrefix() string {
+	return "/**"
+}
+
+// GetDocCommentPrefixes accepts both Doxygen comment styles
+func (h *CHandler) GetDocCommentPrefixes() []string {
+	return []string{"/**", "///", "/*!", "//!"}
+}
+
+func (h *CHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "call_expression" {
+		return false
+	}
+
+	function := nodeText(node.ChildByFieldName("function"), content)
+	if containsString(cLoggingFunctions, function) {
+		return true
+	}
+	if !containsString(cStreamLoggingFunctions, function) {
+		return false
+	}
+
+	// The stream is the first argument of fprintf and the last one of fputs
+	arguments := node.ChildByFieldName("arguments")
+	if arguments == nil || arguments.NamedChildCount() == 0 {
+		return false
+	}
+	stream := arguments.NamedChild(0)
+	if function == "fputs" {
+		stream = arguments.NamedChild(int(arguments.NamedChildCount()) - 1)
+	}
+	switch nodeText(stream, content) {
+	case "stderr", "stdout":
+		return true
+	}
+	return false
+}
+
+// IsGetterSetter always returns false. C has no methods, so functions named
+// get_* or set_* are part of a module's API and callers would break if they
+// were removed.
+func (h *CHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	return false
+}
+
+func (h *CHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_definition"},
+		[]string{"compound_statement"})
+}
+
+// GetDeclaration handles functions, structs, unions, enums and typedefs.
+// Names are read from the innermost declarator, so "*make_point" and
+// "(*handler)" declare make_point and handler.
+func (h *CHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	switch node.Type() {
+	case "function_definition", "type_definition":
+		declarator := innermostDeclarator(node)
+		if declarator == nil {
+			return nil, "", false
+		}
+		return []string{nodeText(declarator, content)}, "", true
+	case "struct_specifier", "union_specifier", "enum_specifier":
+		// A typedef of a struct body is declared by the typedef itself
+		if node.ChildByFieldName("body") == nil || node.Parent() == nil || node.Parent().Type() == "type_definition" {
+			return nil, "", false
+		}
+		return h.NamedDeclaration(node, content, []string{node.Type()}, nil)
+	}
+	return nil, "", false
+}
+
+func (h *CHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "type_identifier", "field_identifier"}
+}
+
+func (h *CHandler) GetPreambleTypes() []string {
+	return []string{"preproc_include"}
+}
+
+// innermostDeclarator follows the "declarator" fields of a declaration down to
+// the declared identifier
+func innermostDeclarator(node *sitter.Node) *sitter.Node {
+	declarator := node.ChildByFieldName("declarator")
+	for declarator != nil && declarator.ChildByFieldName("declarator") != nil {
+		declarator = declarator.ChildByFieldName("declarator")
+	}
+	return declarator
+}
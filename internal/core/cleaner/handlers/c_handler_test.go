@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+)
+
+func TestCHandlerBasics(t *testing.T) {
+	handler := &CHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"preproc_include"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefixes
+	if prefix := handler.GetDocCommentPrefix(); prefix != "/**" {
+		t.Errorf("Expected '/**', got %s", prefix)
+	}
+	prefixes := handler.GetDocCommentPrefixes()
+	expected = []string{"/**", "///", "/*!", "//!"}
+	if !stringSliceEqual(prefixes, expected) {
+		t.Errorf("Expected %v, got %v", expected, prefixes)
+	}
+}
+
+func TestCLoggingCalls(t *testing.T) {
+	handler := &CHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(c.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "printf call",
+			input:    `void f(void) { printf("value: %d\n", x); }`,
+			expected: true,
+		},
+		{
+			name:     "fprintf to stderr",
+			input:    `void f(void) { fprintf(stderr, "error\n"); }`,
+			expected: true,
+		},
+		{
+			name:     "fputs to stdout",
+			input:    `void f(void) { fputs("done\n", stdout); }`,
+			expected: true,
+		},
+		{
+			name:     "syslog call",
+			input:    `void f(void) { syslog(LOG_ERR, "failed"); }`,
+			expected: true,
+		},
+		{
+			name:     "fprintf to a file",
+			input:    `void f(FILE *out) { fprintf(out, "%d\n", x); }`,
+			expected: false,
+		},
+		{
+			name:     "snprintf call",
+			input:    `void f(void) { snprintf(buf, sizeof buf, "%d", x); }`,
+			expected: false,
+		},
+		{
+			name:     "function with log in name",
+			input:    `void f(void) { login(user); }`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "call_expression")
+			if node == nil {
+				t.Fatalf("No call found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestCGetterSetter(t *testing.T) {
+	handler := &CHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(c.GetLanguage())
+
+	// C functions are never treated as accessors
+	inputs := []string{
+		"int get_value(struct point *p) { return p->x; }",
+		"void set_value(struct point *p, int x) { p->x = x; }",
+		"int is_valid(int x) { return x > 0; }",
+	}
+
+	for _, input := range inputs {
+		tree := parser.Parse(nil, []byte(input))
+		if tree == nil {
+			t.Fatal("Failed to parse input")
+		}
+
+		node := findNodeByType(tree.RootNode(), "function_definition")
+		if node == nil {
+			t.Fatalf("No function found in %q", input)
+		}
+		if handler.IsGetterSetter(node, []byte(input)) {
+			t.Errorf("Expected IsGetterSetter() = false for input %q", input)
+		}
+		tree.Close()
+	}
+}
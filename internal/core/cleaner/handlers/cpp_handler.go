@@ -63,3 +63,45 @@ func (h *CPPHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 	}
 	return false
 }
+
+func (h *CPPHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_definition", "lambda_expression"},
+		[]string{"compound_statement"})
+}
+
+// GetDeclaration handles functions, classes, structs and enums. Function names
+// are read from the innermost declarator.
+func (h *CPPHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	containerTypes := []string{"class_specifier", "struct_specifier"}
+	switch node.Type() {
+	case "function_definition":
+		declarator := node.ChildByFieldName("declarator")
+		for declarator != nil && declarator.ChildByFieldName("declarator") != nil {
+			declarator = declarator.ChildByFieldName("declarator")
+		}
+		if declarator == nil {
+			return nil, "", false
+		}
+		name := string(content[declarator.StartByte():declarator.EndByte()])
+		container := h.ContainerName(node, content, containerTypes)
+		if idx := strings.LastIndex(name, "::"); idx >= 0 {
+			container, name = name[:idx], name[idx+2:]
+		}
+		return []string{name}, container, true
+	case "class_specifier", "struct_specifier", "enum_specifier":
+		if node.ChildByFieldName("body") == nil {
+			return nil, "", false
+		}
+		return h.NamedDeclaration(node, content, []string{node.Type()}, containerTypes)
+	}
+	return nil, "", false
+}
+
+func (h *CPPHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "type_identifier", "field_identifier"}
+}
+
+func (h *CPPHandler) GetPreambleTypes() []string {
+	return []string{"preproc_include", "using_declaration"}
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"bytes"
-	"fmt"
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
@@ -27,27 +26,22 @@ func (h *CSharpHandler) GetDocCommentPrefix() string {
 
 func (h *CSharpHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
 	if node == nil {
-		fmt.Println("IsLoggingCall: Node is nil")
 		return false
 	}
 	if node.Type() != "invocation_expression" {
-		fmt.Printf("IsLoggingCall: Node type is not invocation_expression, got %s\n", node.Type())
 		return false
 	}
 
 	memberAccess := node.Child(0)
 	if memberAccess == nil {
-		fmt.Println("IsLoggingCall: Member access is nil")
 		return false
 	}
 
 	if memberAccess.Type() != "member_access_expression" {
-		fmt.Printf("IsLoggingCall: Member access type is not member_access_expression, got %s\n", memberAccess.Type())
 		return false
 	}
 
 	callText := content[memberAccess.StartByte():memberAccess.EndByte()]
-	fmt.Printf("IsLoggingCall: Call text is %s\n", callText)
 
 	return bytes.Contains(callText, []byte("Console.")) ||
 		bytes.Contains(callText, []byte("Debug.")) ||
@@ -137,4 +131,34 @@ func findNextSibling(node *sitter.Node, nodeType string) *sitter.Node {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (h *CSharpHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"method_declaration", "constructor_declaration", "destructor_declaration",
+			"operator_declaration", "conversion_operator_declaration", "local_function_statement",
+			"accessor_declaration"},
+		[]string{"block"})
+}
+
+func (h *CSharpHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	return h.NamedDeclaration(node, content,
+		[]string{"class_declaration", "interface_declaration", "struct_declaration", "enum_declaration",
+			"record_declaration", "method_declaration", "constructor_declaration", "property_declaration"},
+		[]string{"class_declaration", "interface_declaration", "struct_declaration", "record_declaration"})
+}
+
+func (h *CSharpHandler) GetReferenceTypes() []string {
+	return []string{"identifier"}
+}
+
+func (h *CSharpHandler) GetPreambleTypes() []string {
+	return []string{"using_directive"}
+}
+
+func (h *CSharpHandler) GetPackageName(node *sitter.Node, content []byte) string {
+	if node == nil || (node.Type() != "namespace_declaration" && node.Type() != "file_scoped_namespace_declaration") {
+		return ""
+	}
+	return nodeText(node.ChildByFieldName("name"), content)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ElixirHandler handles Elixir language specifics
+type ElixirHandler struct {
+	BaseHandler
+}
+
+func (h *ElixirHandler) GetCommentTypes() []string {
+	return []string{"comment"}
+}
+
+func (h *ElixirHandler) GetImportTypes() []string {
+	// Modules are brought into scope with these macro calls
+	return []string{"import", "alias", "require", "use"}
+}
+
+func (h *ElixirHandler) GetDocCommentPrefix() string {
+	// Documentation is written in @doc and @moduledoc attributes rather than
+	// comments, so no comment is treated as documentation
+	return "@doc"
+}
+
+func (h *ElixirHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "call" {
+		return false
+	}
+
+	target := node.ChildByFieldName("target")
+	if target == nil || target.Type() != "dot" {
+		return false
+	}
+
+	targetText := nodeText(target, content)
+	return targetText == "IO.puts" ||
+		targetText == "IO.inspect" ||
+		strings.HasPrefix(targetText, "Logger.")
+}
+
+func (h *ElixirHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "call" {
+		return false
+	}
+
+	// Functions are defined with calls to def or defp whose first argument
+	// is the function head, e.g. def get_name(user)
+	keyword := nodeText(node.ChildByFieldName("target"), content)
+	if keyword != "def" && keyword != "defp" {
+		return false
+	}
+
+	var arguments *sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "arguments" {
+			arguments = child
+			break
+		}
+	}
+	if arguments == nil || arguments.NamedChildCount() == 0 {
+		return false
+	}
+
+	head := arguments.NamedChild(0)
+	name := nodeText(head, content)
+	if head.Type() == "call" {
+		name = nodeText(head.ChildByFieldName("target"), content)
+	}
+	return isAccessorName(name)
+}
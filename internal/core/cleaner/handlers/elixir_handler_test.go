@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/elixir"
+)
+
+func TestElixirHandlerBasics(t *testing.T) {
+	handler := &ElixirHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"import", "alias", "require", "use"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "@doc" {
+		t.Errorf("Expected '@doc', got %s", prefix)
+	}
+}
+
+func TestElixirLoggingCalls(t *testing.T) {
+	handler := &ElixirHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(elixir.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "IO.puts call",
+			input:    `IO.puts("debug")`,
+			expected: true,
+		},
+		{
+			name:     "IO.inspect call",
+			input:    `IO.inspect(value)`,
+			expected: true,
+		},
+		{
+			name:     "Logger call",
+			input:    `Logger.info("started")`,
+			expected: true,
+		},
+		{
+			name:     "other module call",
+			input:    `Enum.map(list, fun)`,
+			expected: false,
+		},
+		{
+			name:     "local call",
+			input:    `process(data)`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "call")
+			if node == nil {
+				t.Fatalf("No call found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestElixirGetterSetter(t *testing.T) {
+	handler := &ElixirHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(elixir.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "getter function",
+			input:    "def get_name(user), do: user.name",
+			expected: true,
+		},
+		{
+			name:     "private setter function",
+			input:    "defp set_name(user, name), do: %{user | name: name}",
+			expected: true,
+		},
+		{
+			name:     "regular function",
+			input:    "def greet(user), do: user.name",
+			expected: false,
+		},
+		{
+			name:     "non-definition call",
+			input:    "get_name(user)",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "call")
+			if node == nil {
+				t.Fatalf("No call found in %q", tt.input)
+			}
+
+			if got := handler.IsGetterSetter(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsGetterSetter() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsGetterSetter(nil, []byte("")) {
+		t.Error("Expected IsGetterSetter to return false for nil node")
+	}
+}
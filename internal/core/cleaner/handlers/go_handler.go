@@ -50,3 +50,62 @@ func (h *GoHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 	return strings.HasPrefix(strings.ToLower(name), "get") ||
 		strings.HasPrefix(strings.ToLower(name), "set")
 }
+
+func (h *GoHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_declaration", "method_declaration", "func_literal"},
+		[]string{"block"})
+}
+
+func (h *GoHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	switch node.Type() {
+	case "function_declaration":
+		return h.NamedDeclaration(node, content, []string{"function_declaration"}, nil)
+	case "method_declaration":
+		names, _, ok := h.NamedDeclaration(node, content, []string{"method_declaration"}, nil)
+		if !ok {
+			return nil, "", false
+		}
+		receiver := ""
+		if params := node.ChildByFieldName("receiver"); params != nil {
+			receiver = findFirstText(params, content, "type_identifier")
+		}
+		return names, receiver, true
+	case "type_declaration", "const_declaration", "var_declaration":
+		if node.Parent() == nil || node.Parent().Type() != "source_file" {
+			return nil, "", false
+		}
+		var names []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			names = append(names, fieldTexts(node.NamedChild(i), content, "name")...)
+		}
+		return names, "", len(names) > 0
+	}
+	return nil, "", false
+}
+
+func (h *GoHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "type_identifier", "field_identifier"}
+}
+
+func (h *GoHandler) GetPreambleTypes() []string {
+	return []string{"package_clause", "import_declaration"}
+}
+
+func (h *GoHandler) GetPackageName(node *sitter.Node, content []byte) string {
+	if node == nil || node.Type() != "package_clause" {
+		return ""
+	}
+	return firstChildText(node, content, "package_identifier")
+}
+
+func (h *GoHandler) GetImportPaths(node *sitter.Node, content []byte) []string {
+	if node.Type() != "import_spec" {
+		return nil
+	}
+	paths := fieldTexts(node, content, "path")
+	if len(paths) == 0 {
+		return nil
+	}
+	return []string{unquote(paths[0])}
+}
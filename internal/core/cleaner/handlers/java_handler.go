@@ -77,3 +77,42 @@ func (h *JavaHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 
 	return isGetter || isSetter
 }
+
+func (h *JavaHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"method_declaration", "constructor_declaration", "lambda_expression"},
+		[]string{"block", "constructor_body"})
+}
+
+func (h *JavaHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	if node.Type() == "field_declaration" {
+		var names []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if declarator := node.NamedChild(i); declarator.Type() == "variable_declarator" {
+				names = append(names, fieldTexts(declarator, content, "name")...)
+			}
+		}
+		return names, h.ContainerName(node, content, javaContainerTypes), len(names) > 0
+	}
+	return h.NamedDeclaration(node, content,
+		[]string{"class_declaration", "interface_declaration", "enum_declaration", "record_declaration",
+			"method_declaration", "constructor_declaration"},
+		javaContainerTypes)
+}
+
+func (h *JavaHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "type_identifier"}
+}
+
+func (h *JavaHandler) GetPreambleTypes() []string {
+	return []string{"package_declaration", "import_declaration"}
+}
+
+func (h *JavaHandler) GetPackageName(node *sitter.Node, content []byte) string {
+	if node == nil || node.Type() != "package_declaration" {
+		return ""
+	}
+	return firstChildText(node, content, "scoped_identifier", "identifier")
+}
+
+var javaContainerTypes = []string{"class_declaration", "interface_declaration", "enum_declaration", "record_declaration"}
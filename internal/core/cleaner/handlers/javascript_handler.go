@@ -47,3 +47,87 @@ func (h *JavaScriptHandler) IsGetterSetter(node *sitter.Node, content []byte) bo
 		nodeType == "getter_declaration" ||
 		nodeType == "setter_declaration"
 }
+
+func (h *JavaScriptHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_declaration", "generator_function_declaration", "function",
+			"function_expression", "generator_function", "method_definition", "arrow_function"},
+		[]string{"statement_block"})
+}
+
+// GetDeclaration handles functions, classes, methods, top-level variables and,
+// for TypeScript, interfaces, type aliases and enums. Exported declarations are
+// reported as a whole so that the export keyword is kept.
+func (h *JavaScriptHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	declTypes := []string{"function_declaration", "generator_function_declaration", "class_declaration",
+		"abstract_class_declaration", "method_definition", "interface_declaration",
+		"type_alias_declaration", "enum_declaration"}
+	containerTypes := []string{"class_declaration", "abstract_class_declaration", "class"}
+
+	switch node.Type() {
+	case "export_statement":
+		declaration := node.ChildByFieldName("declaration")
+		if declaration == nil {
+			return nil, "", false
+		}
+		if names, container, ok := h.NamedDeclaration(declaration, content, declTypes, containerTypes); ok {
+			return names, container, true
+		}
+		return h.variableNames(declaration, content)
+	case "lexical_declaration", "variable_declaration":
+		if node.Parent() == nil || node.Parent().Type() != "program" {
+			return nil, "", false
+		}
+		return h.variableNames(node, content)
+	}
+
+	if node.Parent() != nil && node.Parent().Type() == "export_statement" {
+		return nil, "", false
+	}
+	return h.NamedDeclaration(node, content, declTypes, containerTypes)
+}
+
+// variableNames returns the names bound by a variable declaration
+func (h *JavaScriptHandler) variableNames(node *sitter.Node, content []byte) ([]string, string, bool) {
+	if node.Type() != "lexical_declaration" && node.Type() != "variable_declaration" {
+		return nil, "", false
+	}
+	var names []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if declarator := node.NamedChild(i); declarator.Type() == "variable_declarator" {
+			names = append(names, fieldTexts(declarator, content, "name")...)
+		}
+	}
+	return names, "", len(names) > 0
+}
+
+func (h *JavaScriptHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "property_identifier", "type_identifier"}
+}
+
+func (h *JavaScriptHandler) GetPreambleTypes() []string {
+	return []string{"import_statement"}
+}
+
+// GetImportPaths returns the module specifiers of import and re-export
+// statements as well as of require() and dynamic import() calls
+func (h *JavaScriptHandler) GetImportPaths(node *sitter.Node, content []byte) []string {
+	switch node.Type() {
+	case "import_statement", "export_statement":
+		if source := node.ChildByFieldName("source"); source != nil {
+			return []string{unquote(string(content[source.StartByte():source.EndByte()]))}
+		}
+	case "call_expression":
+		function := node.ChildByFieldName("function")
+		arguments := node.ChildByFieldName("arguments")
+		if function == nil || arguments == nil || arguments.NamedChildCount() != 1 {
+			return nil
+		}
+		name := string(content[function.StartByte():function.EndByte()])
+		argument := arguments.NamedChild(0)
+		if (name == "require" || name == "import") && argument.Type() == "string" {
+			return []string{unquote(string(content[argument.StartByte():argument.EndByte()]))}
+		}
+	}
+	return nil
+}
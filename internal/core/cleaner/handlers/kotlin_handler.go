@@ -132,3 +132,42 @@ func (h *KotlinHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 
 	return false
 }
+
+func (h *KotlinHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_declaration", "secondary_constructor", "anonymous_initializer"},
+		[]string{"function_body", "block", "statements"})
+}
+
+// GetDeclaration handles classes, objects and functions. The Kotlin grammar does
+// not use field names, so names are taken from the identifier children.
+func (h *KotlinHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	var name string
+	switch node.Type() {
+	case "class_declaration", "object_declaration":
+		name = firstChildText(node, content, "type_identifier")
+	case "function_declaration":
+		name = firstChildText(node, content, "simple_identifier")
+	default:
+		return nil, "", false
+	}
+	if name == "" {
+		return nil, "", false
+	}
+	return []string{name}, h.ContainerName(node, content, []string{"class_declaration", "object_declaration"}), true
+}
+
+func (h *KotlinHandler) GetReferenceTypes() []string {
+	return []string{"simple_identifier", "type_identifier"}
+}
+
+func (h *KotlinHandler) GetPreambleTypes() []string {
+	return []string{"package_header", "import_list"}
+}
+
+func (h *KotlinHandler) GetPackageName(node *sitter.Node, content []byte) string {
+	if node == nil || node.Type() != "package_header" {
+		return ""
+	}
+	return firstChildText(node, content, "identifier")
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// LuaHandler handles Lua language specifics
+type LuaHandler struct {
+	BaseHandler
+}
+
+func (h *LuaHandler) GetCommentTypes() []string {
+	return []string{"comment", "emmy_documentation"}
+}
+
+func (h *LuaHandler) GetImportTypes() []string {
+	// Modules are loaded with calls to require
+	return []string{"require"}
+}
+
+func (h *LuaHandler) GetDocCommentPrefix() string {
+	return "---"
+}
+
+func (h *LuaHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "function_call" {
+		return false
+	}
+
+	callText := nodeText(node, content)
+	loggingPrefixes := []string{"print(", "print ", "io.write(", "log.", "logger.", "ngx.log("}
+	for _, prefix := range loggingPrefixes {
+		if strings.HasPrefix(callText, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *LuaHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "function_statement" {
+		return false
+	}
+
+	// Function names may be qualified, as in M.get_name or M:get_name
+	name := nodeText(node.ChildByFieldName("name"), content)
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return isAccessorName(name)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/lua"
+)
+
+func TestLuaHandlerBasics(t *testing.T) {
+	handler := &LuaHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment", "emmy_documentation"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"require"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "---" {
+		t.Errorf("Expected '---', got %s", prefix)
+	}
+}
+
+func TestLuaLoggingCalls(t *testing.T) {
+	handler := &LuaHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(lua.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "print call",
+			input:    `print("debug", x)`,
+			expected: true,
+		},
+		{
+			name:     "io.write call",
+			input:    `io.write("value")`,
+			expected: true,
+		},
+		{
+			name:     "log module call",
+			input:    `log.info("started")`,
+			expected: true,
+		},
+		{
+			name:     "openresty log call",
+			input:    `ngx.log(ngx.ERR, "failed")`,
+			expected: true,
+		},
+		{
+			name:     "regular call",
+			input:    `process(data)`,
+			expected: false,
+		},
+		{
+			name:     "function with print in name",
+			input:    `printer(data)`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "function_call")
+			if node == nil {
+				t.Fatalf("No function call found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestLuaGetterSetter(t *testing.T) {
+	handler := &LuaHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(lua.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "getter on table",
+			input:    "function M.get_name(self) return self.name end",
+			expected: true,
+		},
+		{
+			name:     "setter method",
+			input:    "function M:setName(n) self.name = n end",
+			expected: true,
+		},
+		{
+			name:     "local getter",
+			input:    "local function get_value() return value end",
+			expected: true,
+		},
+		{
+			name:     "regular function",
+			input:    "function M.process(data) return data end",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "function_statement")
+			if node == nil {
+				t.Fatalf("No function found in %q", tt.input)
+			}
+
+			if got := handler.IsGetterSetter(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsGetterSetter() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsGetterSetter(nil, []byte("")) {
+		t.Error("Expected IsGetterSetter to return false for nil node")
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// OCamlHandler handles OCaml language specifics
+type OCamlHandler struct {
+	BaseHandler
+}
+
+func (h *OCamlHandler) GetCommentTypes() []string {
+	return []string{"comment"}
+}
+
+func (h *OCamlHandler) GetImportTypes() []string {
+	return []string{"open_module", "include_module"}
+}
+
+func (h *OCamlHandler) GetDocCommentPrefix() string {
+	return "(**"
+}
+
+func (h *OCamlHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "application_expression" {
+		return false
+	}
+
+	function := nodeText(node.ChildByFieldName("function"), content)
+	switch function {
+	case "print_endline", "print_string", "print_newline", "prerr_endline", "prerr_string",
+		"Printf.printf", "Printf.eprintf", "Format.printf", "Format.eprintf":
+		return true
+	}
+	return strings.HasPrefix(function, "Log.") ||
+		strings.HasPrefix(function, "Logs.")
+}
+
+// GetRemovalRange keeps the code around a removed expression valid. An
+// operand of a sequence goes with its ";", any other expression is replaced
+// by (), which logging functions return. A definition goes with its "let".
+func (h *OCamlHandler) GetRemovalRange(node *sitter.Node, content []byte) *BodyRange {
+	if node == nil {
+		return nil
+	}
+	parent := node.Parent()
+	if parent == nil {
+		return nil
+	}
+
+	switch node.Type() {
+	case "application_expression":
+		if parent.Type() == "sequence_expression" {
+			if next := node.NextSibling(); next != nil && next.Type() == ";" {
+				return &BodyRange{Start: node.StartByte(), End: next.EndByte()}
+			}
+			if prev := node.PrevSibling(); prev != nil && prev.Type() == ";" {
+				if left := prev.PrevSibling(); left != nil {
+					return &BodyRange{Start: left.EndByte(), End: node.EndByte()}
+				}
+			}
+		}
+		return &BodyRange{Start: node.StartByte(), End: node.EndByte(), Placeholder: "()"}
+	case "let_binding":
+		if parent.Type() == "value_definition" && parent.NamedChildCount() == 1 {
+			return &BodyRange{Start: parent.StartByte(), End: parent.EndByte()}
+		}
+	}
+	return nil
+}
+
+func (h *OCamlHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "let_binding" {
+		return false
+	}
+	return isAccessorName(nodeText(node.ChildByFieldName("pattern"), content))
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/ocaml"
+)
+
+func TestOCamlHandlerBasics(t *testing.T) {
+	handler := &OCamlHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"open_module", "include_module"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "(**" {
+		t.Errorf("Expected '(**', got %s", prefix)
+	}
+}
+
+func TestOCamlLoggingCalls(t *testing.T) {
+	handler := &OCamlHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(ocaml.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "print_endline call",
+			input:    `let () = print_endline "debug"`,
+			expected: true,
+		},
+		{
+			name:     "Printf.printf call",
+			input:    `let () = Printf.printf "%d\n" x`,
+			expected: true,
+		},
+		{
+			name:     "Logs call",
+			input:    `let () = Logs.info (fun m -> m "started")`,
+			expected: true,
+		},
+		{
+			name:     "Printf.sprintf call",
+			input:    `let s = Printf.sprintf "%d" x`,
+			expected: false,
+		},
+		{
+			name:     "regular call",
+			input:    `let y = process x`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "application_expression")
+			if node == nil {
+				t.Fatalf("No application found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestOCamlGetterSetter(t *testing.T) {
+	handler := &OCamlHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(ocaml.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "getter binding",
+			input:    "let get_name p = p.name",
+			expected: true,
+		},
+		{
+			name:     "setter binding",
+			input:    "let set_name p n = { p with name = n }",
+			expected: true,
+		},
+		{
+			name:     "regular binding",
+			input:    "let greet p = p.name",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "let_binding")
+			if node == nil {
+				t.Fatalf("No binding found in %q", tt.input)
+			}
+
+			if got := handler.IsGetterSetter(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsGetterSetter() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsGetterSetter(nil, []byte("")) {
+		t.Error("Expected IsGetterSetter to return false for nil node")
+	}
+}
@@ -83,3 +83,32 @@ func (h *PHPHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 	return strings.Contains(methodText, "public function get") ||
 		strings.Contains(methodText, "public function set")
 }
+
+func (h *PHPHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_definition", "method_declaration", "anonymous_function_creation_expression"},
+		[]string{"compound_statement"})
+}
+
+func (h *PHPHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	return h.NamedDeclaration(node, content,
+		[]string{"function_definition", "class_declaration", "interface_declaration", "trait_declaration",
+			"method_declaration"},
+		[]string{"class_declaration", "interface_declaration", "trait_declaration"})
+}
+
+func (h *PHPHandler) GetReferenceTypes() []string {
+	return []string{"name"}
+}
+
+func (h *PHPHandler) GetPreambleTypes() []string {
+	return []string{"php_tag", "namespace_definition", "namespace_use_declaration"}
+}
+
+// GetPackageName returns the namespace with dots in place of backslashes
+func (h *PHPHandler) GetPackageName(node *sitter.Node, content []byte) string {
+	if node == nil || node.Type() != "namespace_definition" {
+		return ""
+	}
+	return strings.ReplaceAll(nodeText(node.ChildByFieldName("name"), content), `\`, ".")
+}
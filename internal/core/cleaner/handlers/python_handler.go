@@ -61,3 +61,121 @@ func (h *PythonHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 	return strings.HasPrefix(name, "get_") ||
 		strings.HasPrefix(name, "set_")
 }
+
+// GetSkeletonBody elides function bodies while keeping a leading docstring
+func (h *PythonHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	if node == nil || node.Type() != "function_definition" {
+		return nil
+	}
+	body := node.ChildByFieldName("body")
+	if body == nil || body.EndByte() > uint32(len(content)) {
+		return nil
+	}
+
+	// Indentation of the first statement, reused for the placeholder
+	lineStart := int(body.StartByte())
+	for lineStart > 0 && content[lineStart-1] != '\n' {
+		lineStart--
+	}
+	indent := string(content[lineStart:body.StartByte()])
+	if strings.TrimSpace(indent) != "" {
+		// Single-line body such as "def f(): return 1"
+		return &BodyRange{Start: body.StartByte(), End: body.EndByte(), Placeholder: "..."}
+	}
+
+	start := body.StartByte()
+	placeholder := "..."
+	if first := body.NamedChild(0); first != nil && first.Type() == "expression_statement" &&
+		first.NamedChildCount() == 1 && first.NamedChild(0).Type() == "string" {
+		start = first.EndByte()
+		placeholder = "\n" + indent + "..."
+	}
+
+	return &BodyRange{Start: start, End: body.EndByte(), Placeholder: placeholder}
+}
+
+// GetDeclaration handles functions, classes and module-level assignments.
+// Decorated definitions are reported as a whole so that decorators are kept.
+func (h *PythonHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	definitionTypes := []string{"function_definition", "class_definition"}
+	switch node.Type() {
+	case "decorated_definition":
+		definition := node.ChildByFieldName("definition")
+		if definition == nil {
+			return nil, "", false
+		}
+		return h.NamedDeclaration(definition, content, definitionTypes, []string{"class_definition"})
+	case "function_definition", "class_definition":
+		if node.Parent() != nil && node.Parent().Type() == "decorated_definition" {
+			return nil, "", false
+		}
+		return h.NamedDeclaration(node, content, definitionTypes, []string{"class_definition"})
+	case "expression_statement":
+		if node.Parent() == nil || node.Parent().Type() != "module" || node.NamedChildCount() != 1 {
+			return nil, "", false
+		}
+		assignment := node.NamedChild(0)
+		if assignment.Type() != "assignment" {
+			return nil, "", false
+		}
+		if left := assignment.ChildByFieldName("left"); left != nil && left.Type() == "identifier" {
+			return []string{string(content[left.StartByte():left.EndByte()])}, "", true
+		}
+	}
+	return nil, "", false
+}
+
+func (h *PythonHandler) GetReferenceTypes() []string {
+	return []string{"identifier"}
+}
+
+func (h *PythonHandler) GetPreambleTypes() []string {
+	return []string{"import_statement", "import_from_statement", "future_import_statement"}
+}
+
+// GetImportPaths returns dotted module names. For "from x import y" the names
+// are returned as "x.y", since y may be either a submodule or a member of x.
+func (h *PythonHandler) GetImportPaths(node *sitter.Node, content []byte) []string {
+	switch node.Type() {
+	case "import_statement":
+		return importedNames(node, content)
+	case "import_from_statement":
+		modules := fieldTexts(node, content, "module_name")
+		if len(modules) == 0 {
+			return nil
+		}
+		module := modules[0]
+		names := importedNames(node, content)
+		if len(names) == 0 {
+			return []string{module}
+		}
+		paths := make([]string, 0, len(names))
+		for _, name := range names {
+			if strings.HasSuffix(module, ".") {
+				paths = append(paths, module+name)
+			} else {
+				paths = append(paths, module+"."+name)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// importedNames returns the dotted names of an import statement, without aliases
+func importedNames(node *sitter.Node, content []byte) []string {
+	var names []string
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != "name" {
+			continue
+		}
+		child := node.Child(i)
+		if child.Type() == "aliased_import" {
+			child = child.ChildByFieldName("name")
+		}
+		if child != nil && child.EndByte() <= uint32(len(content)) {
+			names = append(names, string(content[child.StartByte():child.EndByte()]))
+		}
+	}
+	return names
+}
@@ -58,3 +58,32 @@ func (h *RubyHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 		strings.HasPrefix(methodText, "def get_") ||
 		strings.HasPrefix(methodText, "def set_")
 }
+
+func (h *RubyHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	if node == nil || (node.Type() != "method" && node.Type() != "singleton_method") {
+		return nil
+	}
+	body := node.ChildByFieldName("body")
+	if body == nil || body.EndByte() > uint32(len(content)) {
+		return nil
+	}
+	return &BodyRange{
+		Start:       body.StartByte(),
+		End:         body.EndByte(),
+		Placeholder: "...",
+	}
+}
+
+func (h *RubyHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	return h.NamedDeclaration(node, content,
+		[]string{"class", "module", "method", "singleton_method"},
+		[]string{"class", "module"})
+}
+
+func (h *RubyHandler) GetReferenceTypes() []string {
+	return []string{"identifier", "constant"}
+}
+
+func (h *RubyHandler) GetPreambleTypes() []string {
+	return nil
+}
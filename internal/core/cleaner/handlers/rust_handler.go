@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// RustHandler handles Rust language specifics
+type RustHandler struct {
+	BaseHandler
+}
+
+// rustLoggingMacros lists the printing macros of the standard library and the
+// level macros of the log and tracing crates. dbg! is left out because it
+// returns its argument and is used inside expressions.
+var rustLoggingMacros = []string{
+	"println", "print", "eprintln", "eprint",
+	"trace", "debug", "info", "warn", "error",
+}
+
+func (h *RustHandler) GetCommentTypes() []string {
+	return []string{"line_comment", "block_comment"}
+}
+
+func (h *RustHandler) GetImportTypes() []string {
+	return []string{"use_declaration", "extern_crate_declaration"}
+}
+
+func (h *RustHandler) GetDocCommentPrefix() string {
+	return "///"
+}
+
+// IsLoggingCall reports logging macros that make up a whole expression
+// statement. A call used as a value, such as a match arm or the last
+// expression of a block, is kept so the code around it stays valid.
+func (h *RustHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "macro_invocation" {
+		return false
+	}
+	if parent := node.Parent(); parent == nil || parent.Type() != "expression_statement" {
+		return false
+	}
+
+	macro := nodeText(node.ChildByFieldName("macro"), content)
+	if macro == "" {
+		return false
+	}
+
+	// Accept both info!(...) and log::info!(...) or tracing::info!(...)
+	if i := strings.LastIndex(macro, "::"); i >= 0 {
+		path := macro[:i]
+		if path != "log" && path != "tracing" {
+			return false
+		}
+		macro = macro[i+2:]
+	}
+	return containsString(rustLoggingMacros, macro)
+}
+
+// GetRemovalRange removes a logging statement together with its ";"
+func (h *RustHandler) GetRemovalRange(node *sitter.Node, content []byte) *BodyRange {
+	if node == nil || node.Type() != "macro_invocation" {
+		return nil
+	}
+	parent := node.Parent()
+	if parent == nil || parent.Type() != "expression_statement" {
+		return nil
+	}
+	return &BodyRange{Start: parent.StartByte(), End: parent.EndByte()}
+}
+
+func (h *RustHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "function_item" {
+		return false
+	}
+	return isAccessorName(nodeText(node.ChildByFieldName("name"), content))
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/rust"
+)
+
+func TestRustHandlerBasics(t *testing.T) {
+	handler := &RustHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"line_comment", "block_comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"use_declaration", "extern_crate_declaration"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "///" {
+		t.Errorf("Expected '///', got %s", prefix)
+	}
+}
+
+func TestRustLoggingCalls(t *testing.T) {
+	handler := &RustHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(rust.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "println macro",
+			input:    `fn main() { println!("value: {}", x); }`,
+			expected: true,
+		},
+		{
+			name:     "eprintln macro",
+			input:    `fn main() { eprintln!("error"); }`,
+			expected: true,
+		},
+		{
+			name:     "dbg macro",
+			input:    `fn main() { dbg!(x); }`,
+			expected: false,
+		},
+		{
+			name:     "macro used as a value",
+			input:    `fn main() { let y = info!("x"); }`,
+			expected: false,
+		},
+		{
+			name:     "macro in a match arm",
+			input:    `fn main() { match v { Some(x) => println!("{}", x), None => {} } }`,
+			expected: false,
+		},
+		{
+			name:     "log crate macro",
+			input:    `fn main() { log::info!("started"); }`,
+			expected: true,
+		},
+		{
+			name:     "imported level macro",
+			input:    `fn main() { warn!("careful"); }`,
+			expected: true,
+		},
+		{
+			name:     "tracing macro",
+			input:    `fn main() { tracing::debug!("span"); }`,
+			expected: true,
+		},
+		{
+			name:     "vec macro",
+			input:    `fn main() { vec![1, 2, 3]; }`,
+			expected: false,
+		},
+		{
+			name:     "macro from another crate",
+			input:    `fn main() { other::info!("x"); }`,
+			expected: false,
+		},
+		{
+			name:     "format macro",
+			input:    `fn main() { format!("{}", x); }`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "macro_invocation")
+			if node == nil {
+				t.Fatalf("No macro invocation found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestRustGetterSetter(t *testing.T) {
+	handler := &RustHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(rust.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "getter method",
+			input:    "impl Point { fn get_x(&self) -> i32 { self.x } }",
+			expected: true,
+		},
+		{
+			name:     "setter method",
+			input:    "impl Point { fn set_x(&mut self, x: i32) { self.x = x; } }",
+			expected: true,
+		},
+		{
+			name:     "regular method",
+			input:    "impl Point { fn distance(&self) -> f64 { 0.0 } }",
+			expected: false,
+		},
+		{
+			name:     "name starting with set",
+			input:    "fn settings() -> Settings { Settings::default() }",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "function_item")
+			if node == nil {
+				t.Fatalf("No function found in %q", tt.input)
+			}
+
+			if got := handler.IsGetterSetter(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsGetterSetter() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsGetterSetter(nil, []byte("")) {
+		t.Error("Expected IsGetterSetter to return false for nil node")
+	}
+}
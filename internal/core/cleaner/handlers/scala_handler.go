@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ScalaHandler handles Scala language specifics
+type ScalaHandler struct {
+	BaseHandler
+}
+
+func (h *ScalaHandler) GetCommentTypes() []string {
+	return []string{"comment", "block_comment"}
+}
+
+func (h *ScalaHandler) GetImportTypes() []string {
+	return []string{"import_declaration"}
+}
+
+func (h *ScalaHandler) GetDocCommentPrefix() string {
+	return "/**"
+}
+
+func (h *ScalaHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "call_expression" {
+		return false
+	}
+
+	function := nodeText(node.ChildByFieldName("function"), content)
+	switch function {
+	case "println", "print", "printf", "Console.println", "System.out.println", "System.err.println":
+		return true
+	}
+	return strings.HasPrefix(function, "log.") ||
+		strings.HasPrefix(function, "logger.") ||
+		strings.HasPrefix(function, "Logger.")
+}
+
+func (h *ScalaHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	if node == nil || node.Type() != "function_definition" {
+		return false
+	}
+	return isAccessorName(nodeText(node.ChildByFieldName("name"), content))
+}
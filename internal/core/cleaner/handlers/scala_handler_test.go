@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/scala"
+)
+
+func TestScalaHandlerBasics(t *testing.T) {
+	handler := &ScalaHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment", "block_comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{"import_declaration"}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "/**" {
+		t.Errorf("Expected '/**', got %s", prefix)
+	}
+}
+
+func TestScalaLoggingCalls(t *testing.T) {
+	handler := &ScalaHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(scala.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "println call",
+			input:    `object A { def f(): Unit = { println("debug") } }`,
+			expected: true,
+		},
+		{
+			name:     "logger call",
+			input:    `object A { def f(): Unit = { logger.info("started") } }`,
+			expected: true,
+		},
+		{
+			name:     "log call",
+			input:    `object A { def f(): Unit = { log.debug("value") } }`,
+			expected: true,
+		},
+		{
+			name:     "console println",
+			input:    `object A { def f(): Unit = { Console.println("x") } }`,
+			expected: true,
+		},
+		{
+			name:     "regular call",
+			input:    `object A { def f(): Unit = { process("data") } }`,
+			expected: false,
+		},
+		{
+			name:     "method on other object",
+			input:    `object A { def f(): Unit = { catalog.info("x") } }`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "call_expression")
+			if node == nil {
+				t.Fatalf("No call found in %q", tt.input)
+			}
+
+			if got := handler.IsLoggingCall(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsLoggingCall() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsLoggingCall(nil, []byte("")) {
+		t.Error("Expected IsLoggingCall to return false for nil node")
+	}
+}
+
+func TestScalaGetterSetter(t *testing.T) {
+	handler := &ScalaHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(scala.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "getter method",
+			input:    "class Person { def getName: String = name }",
+			expected: true,
+		},
+		{
+			name:     "setter method",
+			input:    "class Person { def setName(n: String): Unit = { name = n } }",
+			expected: true,
+		},
+		{
+			name:     "regular method",
+			input:    "class Person { def greet(): Unit = {} }",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			node := findNodeByType(tree.RootNode(), "function_definition")
+			if node == nil {
+				t.Fatalf("No function found in %q", tt.input)
+			}
+
+			if got := handler.IsGetterSetter(node, []byte(tt.input)); got != tt.expected {
+				t.Errorf("Expected IsGetterSetter() = %v for input %q", tt.expected, tt.input)
+			}
+		})
+	}
+
+	// Test nil node
+	if handler.IsGetterSetter(nil, []byte("")) {
+		t.Error("Expected IsGetterSetter to return false for nil node")
+	}
+}
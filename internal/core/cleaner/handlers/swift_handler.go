@@ -86,3 +86,23 @@ func (h *SwiftHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
 
 	return false
 }
+
+func (h *SwiftHandler) GetSkeletonBody(node *sitter.Node, content []byte) *BodyRange {
+	return h.BracedBody(node, content,
+		[]string{"function_declaration", "init_declaration", "deinit_declaration"},
+		[]string{"function_body"})
+}
+
+func (h *SwiftHandler) GetDeclaration(node *sitter.Node, content []byte) ([]string, string, bool) {
+	return h.NamedDeclaration(node, content,
+		[]string{"class_declaration", "protocol_declaration", "function_declaration"},
+		[]string{"class_declaration", "protocol_declaration"})
+}
+
+func (h *SwiftHandler) GetReferenceTypes() []string {
+	return []string{"simple_identifier", "type_identifier"}
+}
+
+func (h *SwiftHandler) GetPreambleTypes() []string {
+	return []string{"import_declaration"}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// YAMLHandler handles YAML specifics
+type YAMLHandler struct {
+	BaseHandler
+}
+
+func (h *YAMLHandler) GetCommentTypes() []string {
+	return []string{"comment"}
+}
+
+func (h *YAMLHandler) GetImportTypes() []string {
+	// YAML has no imports
+	return []string{}
+}
+
+// GetDocCommentPrefix returns "##", which marks documentation in Helm charts
+// and other YAML files, so plain "#" comments are not kept as doc comments
+func (h *YAMLHandler) GetDocCommentPrefix() string {
+	return "##"
+}
+
+func (h *YAMLHandler) IsLoggingCall(node *sitter.Node, content []byte) bool {
+	// YAML doesn't have logging calls
+	return false
+}
+
+func (h *YAMLHandler) IsGetterSetter(node *sitter.Node, content []byte) bool {
+	// YAML doesn't have getters/setters
+	return false
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/yaml"
+)
+
+func TestYAMLHandlerBasics(t *testing.T) {
+	handler := &YAMLHandler{}
+
+	// Test comment types
+	commentTypes := handler.GetCommentTypes()
+	expected := []string{"comment"}
+	if !stringSliceEqual(commentTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, commentTypes)
+	}
+
+	// Test import types
+	importTypes := handler.GetImportTypes()
+	expected = []string{}
+	if !stringSliceEqual(importTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, importTypes)
+	}
+
+	// Test doc comment prefix
+	if prefix := handler.GetDocCommentPrefix(); prefix != "##" {
+		t.Errorf("Expected '##', got %s", prefix)
+	}
+}
+
+func TestYAMLComments(t *testing.T) {
+	handler := &YAMLHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(yaml.GetLanguage())
+
+	tests := []struct {
+		name     string
+		input    string
+		comments []string
+	}{
+		{
+			name:     "full line and trailing comments",
+			input:    "# settings\nname: app # the service\nport: 8080\n",
+			comments: []string{"# settings", "# the service"},
+		},
+		{
+			name:     "comments between documents",
+			input:    "a: 1\n---\n# second\nb: [1, 2] # flow\n",
+			comments: []string{"# second", "# flow"},
+		},
+		{
+			name:     "hash inside a string",
+			input:    "color: \"#ff0000\"\nurl: http://host/#anchor\n",
+			comments: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parser.Parse(nil, []byte(tt.input))
+			if tree == nil {
+				t.Fatal("Failed to parse input")
+			}
+			defer tree.Close()
+
+			got := collectNodeText(tree.RootNode(), handler.GetCommentTypes()[0], []byte(tt.input))
+			if !stringSliceEqual(got, tt.comments) {
+				t.Errorf("comments = %q, want %q", got, tt.comments)
+			}
+		})
+	}
+}
+
+func TestYAMLNoCodeConstructs(t *testing.T) {
+	handler := &YAMLHandler{}
+	parser := sitter.NewParser()
+	parser.SetLanguage(yaml.GetLanguage())
+
+	// Keys named like logging calls and accessors are plain data
+	input := []byte("logger.info: enabled\nprint: true\ngetName: alice\nsetName: [bob]\n")
+	tree := parser.Parse(nil, input)
+	if tree == nil {
+		t.Fatal("Failed to parse input")
+	}
+	defer tree.Close()
+
+	if anyNode(tree.RootNode(), func(n *sitter.Node) bool { return handler.IsLoggingCall(n, input) }) {
+		t.Error("Expected no node to be a logging call")
+	}
+	if anyNode(tree.RootNode(), func(n *sitter.Node) bool { return handler.IsGetterSetter(n, input) }) {
+		t.Error("Expected no node to be an accessor")
+	}
+}
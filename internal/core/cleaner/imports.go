@@ -0,0 +1,56 @@
+package cleaner
+
+import (
+	"fmt"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner/handlers"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SupportsImports reports whether import extraction is available for the language
+func SupportsImports(lang Language) bool {
+	_, handler, err := getLanguageAndHandler(lang)
+	if err != nil {
+		return false
+	}
+	_, ok := handler.(handlers.ImportHandler)
+	return ok
+}
+
+// Imports parses the input and returns the import specifiers it declares, in
+// source order and without duplicates
+func (c *Cleaner) Imports(input []byte) ([]string, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	importHandler, ok := c.handler.(handlers.ImportHandler)
+	if !ok {
+		return nil, fmt.Errorf("import extraction is not supported for %s", c.language)
+	}
+
+	tree, root, err := c.parse(input)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	seen := make(map[string]bool)
+	var imports []string
+
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		for _, path := range importHandler.GetImportPaths(node, input) {
+			if path != "" && !seen[path] {
+				seen[path] = true
+				imports = append(imports, path)
+			}
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(root)
+
+	return imports, nil
+}
@@ -0,0 +1,93 @@
+package cleaner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		language Language
+		input    string
+		expected []string
+	}{
+		{
+			name:     "go imports",
+			language: LangGo,
+			input: `package main
+
+import (
+	"fmt"
+	core "github.com/drgsn/filefusion/internal/core"
+)
+
+import "os"
+
+func main() { fmt.Println(os.Args, core.Logger()) }
+`,
+			expected: []string{"fmt", "github.com/drgsn/filefusion/internal/core", "os"},
+		},
+		{
+			name:     "python imports",
+			language: LangPython,
+			input: `import os, sys as system
+import pkg.sub
+from . import sibling
+from ..parent import helper as h
+from .local import *
+from requests import get, post
+`,
+			expected: []string{"os", "sys", "pkg.sub", ".sibling", "..parent.helper", ".local", "requests.get", "requests.post"},
+		},
+		{
+			name:     "javascript imports",
+			language: LangJavaScript,
+			input: `import React from "react";
+import { a } from './a';
+export { b } from "./b";
+const c = require("./c");
+const d = import("./d");
+const x = require(name);
+`,
+			expected: []string{"react", "./a", "./b", "./c", "./d"},
+		},
+		{
+			name:     "typescript imports",
+			language: LangTypeScript,
+			input: `import type { Props } from "@app/types";
+import * as util from "../util";
+`,
+			expected: []string{"@app/types", "../util"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCleaner(tt.language, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Failed to create cleaner: %v", err)
+			}
+			imports, err := c.Imports([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Imports() error = %v", err)
+			}
+			if !reflect.DeepEqual(imports, tt.expected) {
+				t.Errorf("Imports() = %v, want %v", imports, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSupportsImports(t *testing.T) {
+	for _, lang := range []Language{LangGo, LangPython, LangJavaScript, LangTypeScript} {
+		if !SupportsImports(lang) {
+			t.Errorf("Expected %s to support import extraction", lang)
+		}
+	}
+	for _, lang := range []Language{LangHTML, LangCSS, LangSQL, "unknown"} {
+		if SupportsImports(lang) {
+			t.Errorf("Expected %s not to support import extraction", lang)
+		}
+	}
+}
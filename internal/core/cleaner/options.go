@@ -43,6 +43,8 @@ func DefaultOptions() *CleanerOptions {
 			LangPython:     {"logging.", "logger.", "print(", "print ("},
 			LangJavaScript: {"console.", "logger."},
 			LangTypeScript: {"console.", "logger."},
+			LangTSX:        {"console.", "logger."},
+			LangC:          {"printf(", "fprintf(", "syslog("},
 			LangPHP:        {"error_log(", "print_r(", "var_dump("},
 			LangRuby:       {"puts ", "print ", "p ", "logger."},
 			LangCSharp:     {"Console.", "Debug.", "Logger."},
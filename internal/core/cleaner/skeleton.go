@@ -0,0 +1,70 @@
+package cleaner
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner/handlers"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SupportsSkeleton reports whether skeleton mode is available for the language
+func SupportsSkeleton(lang Language) bool {
+	_, handler, err := getLanguageAndHandler(lang)
+	if err != nil {
+		return false
+	}
+	_, ok := handler.(handlers.SkeletonHandler)
+	return ok
+}
+
+// Skeleton reduces the input to its declarations. Package and import clauses,
+// type and field declarations, signatures and doc comments are kept, while
+// function and method bodies are replaced by a short placeholder such as
+// "{ ... }" or "...". Languages without skeleton support are returned unchanged.
+func (c *Cleaner) Skeleton(input []byte) ([]byte, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+
+	skeletonHandler, ok := c.handler.(handlers.SkeletonHandler)
+	if !ok {
+		return append([]byte(nil), input...), nil
+	}
+
+	tree, root, err := c.parse(input)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	var bodies []*handlers.BodyRange
+	var collect func(node *sitter.Node)
+	collect = func(node *sitter.Node) {
+		if body := skeletonHandler.GetSkeletonBody(node, input); body != nil {
+			bodies = append(bodies, body)
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			collect(node.NamedChild(i))
+		}
+	}
+	collect(root)
+
+	// Bodies are collected in document order and never overlap, so the output
+	// can be assembled in a single pass
+	var output bytes.Buffer
+	output.Grow(len(input))
+	last := uint32(0)
+	for _, body := range bodies {
+		if body.Start < last || body.End < body.Start {
+			continue
+		}
+		output.Write(input[last:body.Start])
+		output.WriteString(body.Placeholder)
+		last = body.End
+	}
+	output.Write(input[last:])
+
+	return output.Bytes(), nil
+}
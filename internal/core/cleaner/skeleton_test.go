@@ -0,0 +1,214 @@
+package cleaner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name     string
+		language Language
+		input    string
+		expected string
+	}{
+		{
+			name:     "go functions and methods",
+			language: LangGo,
+			input: `package main
+
+import "fmt"
+
+// Server handles requests
+type Server struct {
+	Name string
+}
+
+// Start starts the server
+func (s *Server) Start(port int) error {
+	fmt.Println("starting", port)
+	return nil
+}
+
+func helper() {
+	go func() { fmt.Println("x") }()
+}
+`,
+			expected: `package main
+
+import "fmt"
+
+// Server handles requests
+type Server struct {
+	Name string
+}
+
+// Start starts the server
+func (s *Server) Start(port int) error { ... }
+
+func helper() { ... }
+`,
+		},
+		{
+			name:     "python keeps docstrings",
+			language: LangPython,
+			input: `class Greeter:
+    """Greets people."""
+
+    def greet(self, name):
+        """Return a greeting."""
+        message = "Hello " + name
+        return message
+
+    def shout(self, name):
+        return self.greet(name).upper()
+`,
+			expected: `class Greeter:
+    """Greets people."""
+
+    def greet(self, name):
+        """Return a greeting."""
+        ...
+
+    def shout(self, name):
+        ...
+`,
+		},
+		{
+			name:     "javascript classes and arrow functions",
+			language: LangJavaScript,
+			input: `class A {
+  m(x) {
+    return x * 2;
+  }
+}
+const f = (x) => {
+  return x;
+};
+const g = x => x + 1;
+`,
+			expected: `class A {
+  m(x) { ... }
+}
+const f = (x) => { ... };
+const g = x => x + 1;
+`,
+		},
+		{
+			name:     "java methods and constructors",
+			language: LangJava,
+			input: `public class A {
+    private int x;
+
+    public A(int x) {
+        this.x = x;
+    }
+
+    /** Doubles x. */
+    public int twice() {
+        return x * 2;
+    }
+}
+`,
+			expected: `public class A {
+    private int x;
+
+    public A(int x) { ... }
+
+    /** Doubles x. */
+    public int twice() { ... }
+}
+`,
+		},
+		{
+			name:     "ruby methods",
+			language: LangRuby,
+			input: `class Foo
+  def bar(x)
+    puts x
+    x + 1
+  end
+end
+`,
+			expected: `class Foo
+  def bar(x)
+    ...
+  end
+end
+`,
+		},
+		{
+			name:     "css is unchanged",
+			language: LangCSS,
+			input:    "body { color: red; }\n",
+			expected: "body { color: red; }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCleaner(tt.language, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Failed to create cleaner: %v", err)
+			}
+
+			got, err := c.Skeleton([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Skeleton() error = %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Skeleton() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSkeletonAllLanguages(t *testing.T) {
+	inputs := map[Language]string{
+		LangC:          "int f(int x) {\n  return x;\n}\n",
+		LangCPP:        "int f(int x) {\n  return x;\n}\n",
+		LangCSharp:     "class A {\n  public int F(int x) {\n    return x;\n  }\n}\n",
+		LangPHP:        "<?php\nfunction g() {\n  return 1;\n}\n",
+		LangBash:       "f() {\n  echo hi\n}\n",
+		LangSwift:      "func f(x: Int) -> Int {\n  return x\n}\n",
+		LangKotlin:     "fun f(x: Int): Int {\n  return x\n}\n",
+		LangTypeScript: "function f(x: number): number {\n  return x;\n}\n",
+		LangTSX:        "function App(p: Props) {\n  return <div>{p.name}</div>;\n}\n",
+	}
+
+	for lang, input := range inputs {
+		t.Run(string(lang), func(t *testing.T) {
+			if !SupportsSkeleton(lang) {
+				t.Fatalf("Expected %s to support skeleton mode", lang)
+			}
+			c, err := NewCleaner(lang, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Failed to create cleaner: %v", err)
+			}
+			got, err := c.Skeleton([]byte(input))
+			if err != nil {
+				t.Fatalf("Skeleton() error = %v", err)
+			}
+			if !strings.Contains(string(got), "{ ... }") || strings.Contains(string(got), "return") {
+				t.Errorf("Expected body to be elided, got:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestSkeletonErrors(t *testing.T) {
+	c, err := NewCleaner(LangGo, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Skeleton(nil); err == nil {
+		t.Error("Expected error for empty input")
+	}
+	if _, err := c.Skeleton([]byte("package main\nfunc {")); err == nil {
+		t.Error("Expected error for invalid syntax")
+	}
+	if SupportsSkeleton(LangSQL) {
+		t.Error("Expected SQL not to support skeleton mode")
+	}
+}
@@ -0,0 +1,177 @@
+package cleaner
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner/handlers"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Declaration is a named top-level or member declaration found in a file
+type Declaration struct {
+	Names      []string // Names declared by the node, e.g. a function name or the constants of a block
+	Container  string   // Enclosing type for methods, empty otherwise
+	Start      uint32   // First byte, including leading doc comments
+	End        uint32   // Byte after the declaration
+	References []string // Identifiers used inside the declaration
+}
+
+// SymbolTable lists the declarations of a file together with the preamble,
+// such as package clauses and imports, that is kept whenever any declaration
+// of the file is extracted
+type SymbolTable struct {
+	Package      string // Package or namespace the file declares, if any
+	Preamble     [][2]uint32
+	Declarations []Declaration
+}
+
+// SupportsSymbols reports whether symbol extraction is available for the language
+func SupportsSymbols(lang Language) bool {
+	_, handler, err := getLanguageAndHandler(lang)
+	if err != nil {
+		return false
+	}
+	_, ok := handler.(handlers.SymbolHandler)
+	return ok
+}
+
+// Declarations parses the input and returns its symbol table
+func (c *Cleaner) Declarations(input []byte) (*SymbolTable, error) {
+	if len(input) == 0 {
+		return &SymbolTable{}, nil
+	}
+
+	symbolHandler, ok := c.handler.(handlers.SymbolHandler)
+	if !ok {
+		return nil, fmt.Errorf("symbol extraction is not supported for %s", c.language)
+	}
+
+	tree, root, err := c.parse(input)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	table := &SymbolTable{}
+	preambleTypes := symbolHandler.GetPreambleTypes()
+	referenceTypes := symbolHandler.GetReferenceTypes()
+
+	packageHandler, _ := c.handler.(handlers.PackageHandler)
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		if containsType(preambleTypes, child.Type()) {
+			table.Preamble = append(table.Preamble, [2]uint32{child.StartByte(), child.EndByte()})
+		}
+		if packageHandler != nil && table.Package == "" {
+			table.Package = packageHandler.GetPackageName(child, input)
+		}
+	}
+
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if names, container, ok := symbolHandler.GetDeclaration(node, input); ok {
+			table.Declarations = append(table.Declarations, Declaration{
+				Names:      names,
+				Container:  container,
+				Start:      c.docCommentStart(node),
+				End:        node.EndByte(),
+				References: collectReferences(node, input, referenceTypes, names),
+			})
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(root)
+
+	return table, nil
+}
+
+// Extract assembles the preamble and the selected declarations, in source order.
+// Declarations nested inside another selected declaration are emitted only once.
+func (t *SymbolTable) Extract(input []byte, selected []int) []byte {
+	if len(selected) == 0 {
+		return nil
+	}
+
+	indexes := append([]int(nil), selected...)
+	sort.Slice(indexes, func(i, j int) bool {
+		return t.Declarations[indexes[i]].Start < t.Declarations[indexes[j]].Start
+	})
+
+	var parts [][]byte
+	for _, span := range t.Preamble {
+		parts = append(parts, input[span[0]:span[1]])
+	}
+
+	var lastEnd uint32
+	for _, idx := range indexes {
+		decl := t.Declarations[idx]
+		if decl.End <= lastEnd {
+			continue
+		}
+		start := decl.Start
+		if start < lastEnd {
+			start = lastEnd
+		}
+		parts = append(parts, bytes.TrimSpace(input[start:decl.End]))
+		lastEnd = decl.End
+	}
+
+	return append(bytes.Join(parts, []byte("\n\n")), '\n')
+}
+
+// docCommentStart returns the start of the comments directly preceding a node,
+// so that extracted declarations keep their documentation
+func (c *Cleaner) docCommentStart(node *sitter.Node) uint32 {
+	start := node.StartByte()
+	row := node.StartPoint().Row
+	commentTypes := c.handler.GetCommentTypes()
+
+	for prev := node.PrevSibling(); prev != nil; prev = prev.PrevSibling() {
+		if !containsType(commentTypes, prev.Type()) || prev.EndPoint().Row+1 < row {
+			break
+		}
+		start = prev.StartByte()
+		row = prev.StartPoint().Row
+	}
+	return start
+}
+
+// collectReferences returns the distinct identifiers used inside a node,
+// leaving out the names the node declares itself
+func collectReferences(node *sitter.Node, content []byte, referenceTypes, declared []string) []string {
+	seen := make(map[string]bool)
+	for _, name := range declared {
+		seen[name] = true
+	}
+	var refs []string
+
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if containsType(referenceTypes, n.Type()) {
+			name := string(content[n.StartByte():n.EndByte()])
+			if !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i))
+		}
+	}
+	walk(node)
+
+	return refs
+}
+
+func containsType(types []string, nodeType string) bool {
+	for _, t := range types {
+		if t == nodeType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package cleaner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		language Language
+		input    string
+		expected []string // Qualified names in source order
+		preamble int
+	}{
+		{
+			name:     "go declarations",
+			language: LangGo,
+			input: `package main
+
+import "fmt"
+
+const (
+	A = 1
+	B = 2
+)
+
+type Server struct{}
+
+func (s *Server) Start() { fmt.Println(A) }
+
+func main() {}
+`,
+			expected: []string{"A,B", "Server", "Server.Start", "main"},
+			preamble: 2,
+		},
+		{
+			name:     "python declarations",
+			language: LangPython,
+			input: `import os
+
+LIMIT = 10
+
+class Greeter:
+    @staticmethod
+    def greet(name):
+        return name
+
+def main():
+    pass
+`,
+			expected: []string{"LIMIT", "Greeter", "Greeter.greet", "main"},
+			preamble: 1,
+		},
+		{
+			name:     "javascript declarations",
+			language: LangJavaScript,
+			input: `import x from "x";
+export const LIMIT = 10;
+export class A {
+  m() { return LIMIT; }
+}
+function f() {}
+`,
+			expected: []string{"LIMIT", "A", "A.m", "f"},
+			preamble: 1,
+		},
+		{
+			name:     "java declarations",
+			language: LangJava,
+			input: `package a;
+public class A {
+    private int x;
+    public int get() { return x; }
+}
+`,
+			expected: []string{"A", "A.x", "A.get"},
+			preamble: 1,
+		},
+		{
+			name:     "c declarations",
+			language: LangC,
+			input: `#include <stdio.h>
+
+typedef struct point { int x; } point_t;
+
+struct list { int n; };
+
+static int *make(int n) { return 0; }
+
+int main(void) { return *make(1); }
+`,
+			expected: []string{"point_t", "list", "make", "main"},
+			preamble: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCleaner(tt.language, DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			table, err := c.Declarations([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Declarations() error = %v", err)
+			}
+
+			var got []string
+			for _, decl := range table.Declarations {
+				name := strings.Join(decl.Names, ",")
+				if decl.Container != "" {
+					name = decl.Container + "." + name
+				}
+				got = append(got, name)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Declarations() = %v, want %v", got, tt.expected)
+			}
+			if len(table.Preamble) != tt.preamble {
+				t.Errorf("Expected %d preamble nodes, got %d", tt.preamble, len(table.Preamble))
+			}
+		})
+	}
+}
+
+func TestSymbolTableExtract(t *testing.T) {
+	input := `package main
+
+import "fmt"
+
+// helper prints a value
+func helper(v int) {
+	fmt.Println(v)
+}
+
+func unused() {}
+
+func main() {
+	helper(1)
+}
+`
+	c, err := NewCleaner(LangGo, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := c.Declarations([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var selected []int
+	for i, decl := range table.Declarations {
+		if decl.Names[0] == "main" || decl.Names[0] == "helper" {
+			selected = append(selected, i)
+		}
+		if decl.Names[0] == "main" && strings.Join(decl.References, ",") != "helper" {
+			t.Errorf("Expected main to reference helper, got %v", decl.References)
+		}
+	}
+
+	expected := `package main
+
+import "fmt"
+
+// helper prints a value
+func helper(v int) {
+	fmt.Println(v)
+}
+
+func main() {
+	helper(1)
+}
+`
+	if got := string(table.Extract([]byte(input), selected)); got != expected {
+		t.Errorf("Extract() =\n%s\nwant:\n%s", got, expected)
+	}
+	if got := table.Extract([]byte(input), nil); got != nil {
+		t.Errorf("Expected nil output for no selection, got %q", got)
+	}
+}
+
+func TestSupportsSymbols(t *testing.T) {
+	for _, lang := range []Language{LangGo, LangPython, LangJavaScript, LangTypeScript, LangJava,
+		LangCSharp, LangPHP, LangRuby, LangSwift, LangKotlin, LangC, LangCPP, LangBash} {
+		if !SupportsSymbols(lang) {
+			t.Errorf("Expected %s to support symbol extraction", lang)
+		}
+	}
+	for _, lang := range []Language{LangHTML, LangCSS, LangSQL, "unknown"} {
+		if SupportsSymbols(lang) {
+			t.Errorf("Expected %s not to support symbol extraction", lang)
+		}
+	}
+}
@@ -13,19 +13,29 @@ const (
 	LangHTML       Language = "html"
 	LangJavaScript Language = "javascript"
 	LangTypeScript Language = "typescript"
+	LangTSX        Language = "tsx"
 	LangCSS        Language = "css"
+	LangC          Language = "c"
 	LangCPP        Language = "cpp"
 	LangCSharp     Language = "csharp"
 	LangPHP        Language = "php"
 	LangRuby       Language = "ruby"
 	LangBash       Language = "bash"
+	LangRust       Language = "rust"
+	LangLua        Language = "lua"
+	LangScala      Language = "scala"
+	LangElixir     Language = "elixir"
+	LangOCaml      Language = "ocaml"
+	LangYAML       Language = "yaml"
 )
 
 // GetSupportedLanguages returns a list of all supported languages
 func GetSupportedLanguages() []Language {
 	return []Language{
 		LangGo, LangJava, LangPython, LangSwift, LangKotlin,
-		LangSQL, LangHTML, LangJavaScript, LangTypeScript, LangCSS,
-		LangCPP, LangCSharp, LangPHP, LangRuby, LangBash,
+		LangSQL, LangHTML, LangJavaScript, LangTypeScript, LangTSX, LangCSS,
+		LangC, LangCPP, LangCSharp, LangPHP, LangRuby, LangBash,
+		LangRust, LangLua, LangScala, LangElixir, LangOCaml,
+		LangYAML,
 	}
 }
@@ -0,0 +1,538 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// DependencyGraph is the local closure of one or more entry files
+type DependencyGraph struct {
+	Files    []string // Local files in breadth-first order, starting with the entries
+	External []string // Imports that do not resolve to local files, sorted and deduplicated
+}
+
+// jsExtensions lists the extensions tried when resolving JavaScript and TypeScript imports
+var jsExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+// dependencyResolver follows imports from file to file, caching the module
+// and project configuration it reads along the way
+type dependencyResolver struct {
+	cleaners  map[cleaner.Language]*cleaner.Cleaner
+	goModules map[string]*goModule
+	tsConfigs map[string]*tsConfig
+	roots     []string
+	external  map[string]bool
+}
+
+// goModule is the module declared by a go.mod file
+type goModule struct {
+	root string
+	path string
+}
+
+// tsConfig holds the module resolution settings of a tsconfig.json or jsconfig.json
+type tsConfig struct {
+	dir     string
+	baseURL string
+	paths   map[string][]string
+}
+
+// ResolveDependencies parses the imports of the entry files and follows those
+// that resolve to local files, up to depth levels of imports (a negative depth
+// follows the whole closure). Go imports are resolved through go.mod, including
+// the other files of the imported package; JavaScript and TypeScript imports
+// through relative paths and tsconfig.json paths; Python imports through
+// relative and package-relative module paths. Third-party imports are recorded
+// in External but not expanded.
+func ResolveDependencies(entries []string, depth int) (*DependencyGraph, error) {
+	r := &dependencyResolver{
+		cleaners:  make(map[cleaner.Language]*cleaner.Cleaner),
+		goModules: make(map[string]*goModule),
+		tsConfigs: make(map[string]*tsConfig),
+		external:  make(map[string]bool),
+	}
+
+	graph := &DependencyGraph{}
+	visited := make(map[string]bool)
+	var queue []string
+
+	for _, entry := range entries {
+		abs, err := filepath.Abs(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %s: %w", entry, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %s: %w", entry, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("invalid entry %s: is a directory", entry)
+		}
+		r.roots = append(r.roots, filepath.Dir(abs))
+		if !visited[abs] {
+			visited[abs] = true
+			graph.Files = append(graph.Files, displayPath(abs))
+			queue = append(queue, abs)
+		}
+	}
+
+	for level := 0; len(queue) > 0 && (depth < 0 || level < depth); level++ {
+		var next []string
+		for _, file := range queue {
+			for _, dep := range r.dependencies(file) {
+				if visited[dep] {
+					continue
+				}
+				visited[dep] = true
+				graph.Files = append(graph.Files, displayPath(dep))
+				next = append(next, dep)
+			}
+		}
+		queue = next
+	}
+
+	for name := range r.external {
+		graph.External = append(graph.External, name)
+	}
+	sort.Strings(graph.External)
+
+	return graph, nil
+}
+
+// OrderContents sorts contents to follow the order of the given files, which
+// keeps entry files first in bundles built from a dependency graph
+func OrderContents(contents []FileContent, files []string) {
+	order := make(map[string]int, len(files))
+	for i, file := range files {
+		order[filepath.ToSlash(file)] = i
+	}
+	position := func(path string) int {
+		if i, ok := order[path]; ok {
+			return i
+		}
+		return len(files)
+	}
+	sort.SliceStable(contents, func(i, j int) bool {
+		return position(contents[i].key()) < position(contents[j].key())
+	})
+}
+
+// dependencies returns the local files imported by a file
+func (r *dependencyResolver) dependencies(file string) []string {
+	lang := languageForPath(file)
+	if lang == "" || !cleaner.SupportsImports(lang) {
+		return nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logEvent(slog.LevelWarn, EventDependencyFailed, displayPath(file), err.Error())
+		return nil
+	}
+
+	c, ok := r.cleaners[lang]
+	if !ok {
+		c, err = cleaner.NewCleaner(lang, cleaner.DefaultOptions())
+		if err != nil {
+			return nil
+		}
+		r.cleaners[lang] = c
+	}
+
+	imports, err := c.Imports(content)
+	if err != nil {
+		logEvent(slog.LevelWarn, EventDependencyFailed, displayPath(file), err.Error())
+		return nil
+	}
+
+	var deps []string
+	if lang == cleaner.LangGo {
+		// Files of the same package depend on each other without imports
+		deps = append(deps, goPackageFiles(filepath.Dir(file))...)
+	}
+
+	for _, spec := range imports {
+		var resolved []string
+		var external string
+		switch lang {
+		case cleaner.LangGo:
+			resolved, external = r.resolveGo(file, spec)
+		case cleaner.LangPython:
+			resolved, external = r.resolvePython(file, spec)
+		case cleaner.LangJavaScript, cleaner.LangTypeScript, cleaner.LangTSX:
+			resolved, external = r.resolveJS(file, spec)
+		}
+
+		if len(resolved) == 0 {
+			if external != "" {
+				r.external[external] = true
+				logEvent(LevelTrace, EventExternalImport, displayPath(file), "", "import", external)
+			}
+			continue
+		}
+		logEvent(LevelTrace, EventDependencyResolved, displayPath(file), "", "import", spec)
+		deps = append(deps, resolved...)
+	}
+
+	return deps
+}
+
+// resolveGo maps an import path inside the file's module to the files of the
+// imported package. Standard library packages are neither expanded nor recorded.
+func (r *dependencyResolver) resolveGo(file, spec string) ([]string, string) {
+	if mod := r.goModuleFor(filepath.Dir(file)); mod != nil && (spec == mod.path || strings.HasPrefix(spec, mod.path+"/")) {
+		dir := filepath.Join(mod.root, filepath.FromSlash(strings.TrimPrefix(spec, mod.path)))
+		return goPackageFiles(dir), ""
+	}
+	if first := strings.SplitN(spec, "/", 2)[0]; !strings.Contains(first, ".") {
+		return nil, ""
+	}
+	return nil, spec
+}
+
+// goModuleFor returns the module containing dir, if any
+func (r *dependencyResolver) goModuleFor(dir string) *goModule {
+	if mod, ok := r.goModules[dir]; ok {
+		return mod
+	}
+
+	var mod *goModule
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "module" {
+				mod = &goModule{root: dir, path: strings.Trim(fields[1], "\"`")}
+				break
+			}
+		}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = r.goModuleFor(parent)
+	}
+
+	r.goModules[dir] = mod
+	return mod
+}
+
+// goPackageFiles lists the non-test Go files of a package directory
+func goPackageFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files
+}
+
+// resolvePython maps a dotted module name to a module file or package
+// __init__.py. Relative imports are resolved from the importing file, absolute
+// ones from the enclosing package root and the entry directories. Since names
+// imported with "from x import y" may be members rather than submodules, the
+// longest prefix of the name that exists is used.
+func (r *dependencyResolver) resolvePython(file, spec string) ([]string, string) {
+	module := strings.TrimLeft(spec, ".")
+	dots := len(spec) - len(module)
+
+	var parts []string
+	if module != "" {
+		parts = strings.Split(module, ".")
+	}
+
+	if dots > 0 {
+		base := filepath.Dir(file)
+		for i := 1; i < dots; i++ {
+			base = filepath.Dir(base)
+		}
+		if found := findPythonModule(base, parts, 0); found != "" {
+			return []string{found}, ""
+		}
+		return nil, ""
+	}
+
+	for _, root := range r.pythonRoots(file) {
+		if found := findPythonModule(root, parts, 1); found != "" {
+			return []string{found}, ""
+		}
+	}
+	if len(parts) == 0 {
+		return nil, ""
+	}
+	return nil, parts[0]
+}
+
+// pythonRoots returns the directories absolute imports are resolved against:
+// the parent of the top-level package containing the file and the entry directories
+func (r *dependencyResolver) pythonRoots(file string) []string {
+	root := filepath.Dir(file)
+	for isFile(filepath.Join(root, "__init__.py")) {
+		parent := filepath.Dir(root)
+		if parent == root {
+			break
+		}
+		root = parent
+	}
+
+	roots := []string{root}
+	for _, dir := range r.roots {
+		if dir != root {
+			roots = append(roots, dir)
+		}
+	}
+	return roots
+}
+
+// findPythonModule returns the file for the longest prefix of parts, with at
+// least minParts elements, that names a module or package under base
+func findPythonModule(base string, parts []string, minParts int) string {
+	for n := len(parts); n >= minParts; n-- {
+		path := filepath.Join(append([]string{base}, parts[:n]...)...)
+		if n > 0 && isFile(path+".py") {
+			return path + ".py"
+		}
+		if isFile(filepath.Join(path, "__init__.py")) {
+			return filepath.Join(path, "__init__.py")
+		}
+	}
+	return ""
+}
+
+// resolveJS maps a module specifier to a local file. Relative specifiers are
+// resolved from the importing file and bare ones through the paths and baseUrl
+// of the closest tsconfig.json or jsconfig.json; anything else is recorded as
+// a package name.
+func (r *dependencyResolver) resolveJS(file, spec string) ([]string, string) {
+	if spec == "." || spec == ".." || strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") {
+		if found := findJSFile(filepath.Join(filepath.Dir(file), filepath.FromSlash(spec))); found != "" {
+			return []string{found}, ""
+		}
+		return nil, ""
+	}
+
+	if config := r.tsConfigFor(filepath.Dir(file)); config != nil {
+		for _, candidate := range config.candidates(spec) {
+			if found := findJSFile(candidate); found != "" {
+				return []string{found}, ""
+			}
+		}
+	}
+	return nil, jsPackageName(spec)
+}
+
+// tsConfigFor returns the closest tsconfig.json or jsconfig.json above dir
+func (r *dependencyResolver) tsConfigFor(dir string) *tsConfig {
+	if config, ok := r.tsConfigs[dir]; ok {
+		return config
+	}
+
+	var config *tsConfig
+	found := false
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		found = true
+		config, err = parseTSConfig(dir, data)
+		if err != nil {
+			logEvent(slog.LevelWarn, EventDependencyFailed, displayPath(path), err.Error())
+		}
+		break
+	}
+	if !found {
+		if parent := filepath.Dir(dir); parent != dir {
+			config = r.tsConfigFor(parent)
+		}
+	}
+
+	r.tsConfigs[dir] = config
+	return config
+}
+
+// parseTSConfig reads the module resolution settings of a tsconfig file,
+// which may contain comments and trailing commas
+func parseTSConfig(dir string, data []byte) (*tsConfig, error) {
+	var raw struct {
+		CompilerOptions struct {
+			BaseURL string              `json:"baseUrl"`
+			Paths   map[string][]string `json:"paths"`
+		} `json:"compilerOptions"`
+	}
+	if err := json.Unmarshal(stripJSONComments(data), &raw); err != nil {
+		return nil, fmt.Errorf("error parsing config: %w", err)
+	}
+	return &tsConfig{
+		dir:     dir,
+		baseURL: raw.CompilerOptions.BaseURL,
+		paths:   raw.CompilerOptions.Paths,
+	}, nil
+}
+
+// candidates returns the paths a bare specifier may refer to. Path patterns
+// are tried with the longest prefix first, followed by the baseUrl itself.
+func (c *tsConfig) candidates(spec string) []string {
+	base := c.dir
+	if c.baseURL != "" {
+		base = filepath.Join(c.dir, filepath.FromSlash(c.baseURL))
+	}
+
+	patterns := make([]string, 0, len(c.paths))
+	for pattern := range c.paths {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		pi := strings.SplitN(patterns[i], "*", 2)[0]
+		pj := strings.SplitN(patterns[j], "*", 2)[0]
+		if len(pi) != len(pj) {
+			return len(pi) > len(pj)
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	var candidates []string
+	for _, pattern := range patterns {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		var match string
+		switch {
+		case !wildcard && pattern == spec:
+		case wildcard && len(spec) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(spec, prefix) && strings.HasSuffix(spec, suffix):
+			match = spec[len(prefix) : len(spec)-len(suffix)]
+		default:
+			continue
+		}
+		for _, target := range c.paths[pattern] {
+			target = strings.Replace(target, "*", match, 1)
+			candidates = append(candidates, filepath.Join(base, filepath.FromSlash(target)))
+		}
+	}
+
+	if c.baseURL != "" {
+		candidates = append(candidates, filepath.Join(base, filepath.FromSlash(spec)))
+	}
+	return candidates
+}
+
+// findJSFile resolves a module path the way bundlers do: the exact file, the
+// path with a known extension, a TypeScript source imported through its
+// compiled .js name, or an index file of a directory
+func findJSFile(path string) string {
+	if isFile(path) {
+		return path
+	}
+	for _, ext := range jsExtensions {
+		if isFile(path + ext) {
+			return path + ext
+		}
+	}
+	switch filepath.Ext(path) {
+	case ".js", ".jsx", ".mjs", ".cjs":
+		stem := strings.TrimSuffix(path, filepath.Ext(path))
+		for _, ext := range []string{".ts", ".tsx"} {
+			if isFile(stem + ext) {
+				return stem + ext
+			}
+		}
+	}
+	for _, ext := range jsExtensions {
+		if index := filepath.Join(path, "index"+ext); isFile(index) {
+			return index
+		}
+	}
+	return ""
+}
+
+// jsPackageName returns the package part of a bare specifier, keeping the
+// scope of scoped packages such as "@types/node"
+func jsPackageName(spec string) string {
+	parts := strings.SplitN(spec, "/", 3)
+	if strings.HasPrefix(spec, "@") && len(parts) >= 2 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+// stripJSONComments removes comments and trailing commas, leaving strings intact
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		if inString {
+			out = append(out, ch)
+			if ch == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case ch == '"':
+			inString = true
+			out = append(out, ch)
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case ch == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case ch == '}' || ch == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// displayPath returns path relative to the working directory when it lies
+// below it, so that bundles built from entries use the same paths as walks
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// isFile reports whether path exists and is a regular file
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree creates the given files below dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		// Go module
+		"go/go.mod":               "module example.com/app\n\ngo 1.23\n",
+		"go/cmd/main.go":          "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/a\"\n\t\"github.com/spf13/cobra\"\n)\n\nfunc main() { fmt.Println(a.A, cobra.Command{}) }\n",
+		"go/cmd/flags.go":         "package main\n",
+		"go/internal/a/a.go":      "package a\n\nimport \"example.com/app/internal/b\"\n\nvar A = b.B\n",
+		"go/internal/a/a_test.go": "package a\n",
+		"go/internal/b/b.go":      "package b\n\nconst B = 1\n",
+		"go/internal/unused/u.go": "package unused\n",
+
+		// TypeScript project with path aliases
+		"ts/tsconfig.json":         "{\n  // comment\n  \"compilerOptions\": {\n    \"baseUrl\": \".\",\n    \"paths\": { \"@lib/*\": [\"src/lib/*\"], },\n  },\n}\n",
+		"ts/src/index.ts":          "import { util } from \"@lib/util\";\nimport { view } from './view.js';\nimport React from \"react\";\nimport { x } from \"@scope/pkg/sub\";\n",
+		"ts/src/view.ts":           "export const view = 1;\n",
+		"ts/src/lib/util/index.ts": "export const util = require('../../helpers');\n",
+		"ts/src/helpers.js":        "module.exports = {};\n",
+
+		// Python package
+		"py/main.py":         "import os\nfrom app import service\nfrom app.models import User\n",
+		"py/app/__init__.py": "",
+		"py/app/service.py":  "from .models import User\nfrom . import config\n",
+		"py/app/models.py":   "import requests.adapters\n",
+		"py/app/config.py":   "",
+	})
+
+	abs := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, name := range names {
+			paths[i] = filepath.Join(dir, filepath.FromSlash(name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name         string
+		entry        string
+		depth        int
+		wantFiles    []string
+		wantExternal []string
+	}{
+		{
+			name:  "go closure",
+			entry: "go/cmd/main.go",
+			depth: -1,
+			wantFiles: abs("go/cmd/main.go", "go/cmd/flags.go",
+				"go/internal/a/a.go", "go/internal/b/b.go"),
+			wantExternal: []string{"github.com/spf13/cobra"},
+		},
+		{
+			name:         "go depth one",
+			entry:        "go/cmd/main.go",
+			depth:        1,
+			wantFiles:    abs("go/cmd/main.go", "go/cmd/flags.go", "go/internal/a/a.go"),
+			wantExternal: []string{"github.com/spf13/cobra"},
+		},
+		{
+			name:         "depth zero keeps only the entry",
+			entry:        "go/cmd/main.go",
+			depth:        0,
+			wantFiles:    abs("go/cmd/main.go"),
+			wantExternal: nil,
+		},
+		{
+			name:  "typescript paths and relative imports",
+			entry: "ts/src/index.ts",
+			depth: -1,
+			wantFiles: abs("ts/src/index.ts", "ts/src/lib/util/index.ts",
+				"ts/src/view.ts", "ts/src/helpers.js"),
+			wantExternal: []string{"@scope/pkg", "react"},
+		},
+		{
+			name:  "python modules",
+			entry: "py/main.py",
+			depth: -1,
+			wantFiles: abs("py/main.py", "py/app/service.py", "py/app/models.py",
+				"py/app/config.py"),
+			wantExternal: []string{"os", "requests"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := ResolveDependencies([]string{filepath.Join(dir, tt.entry)}, tt.depth)
+			if err != nil {
+				t.Fatalf("ResolveDependencies() error = %v", err)
+			}
+			if !reflect.DeepEqual(graph.Files, tt.wantFiles) {
+				t.Errorf("Files = %v, want %v", graph.Files, tt.wantFiles)
+			}
+			if !reflect.DeepEqual(graph.External, tt.wantExternal) {
+				t.Errorf("External = %v, want %v", graph.External, tt.wantExternal)
+			}
+		})
+	}
+
+	if _, err := ResolveDependencies([]string{filepath.Join(dir, "missing.go")}, -1); err == nil {
+		t.Error("Expected error for missing entry")
+	}
+	if _, err := ResolveDependencies([]string{filepath.Join(dir, "go")}, -1); err == nil {
+		t.Error("Expected error for directory entry")
+	}
+}
+
+func TestOrderContents(t *testing.T) {
+	contents := []FileContent{{Path: "c.go"}, {Path: "other.go"}, {Path: "a/b.go"}, {Path: "main.go"}}
+	OrderContents(contents, []string{"main.go", filepath.FromSlash("a/b.go"), "c.go"})
+
+	var got []string
+	for _, content := range contents {
+		got = append(got, content.Path)
+	}
+	want := []string{"main.go", "a/b.go", "c.go", "other.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderContents() = %v, want %v", got, want)
+	}
+}
+
+func TestStripJSONComments(t *testing.T) {
+	input := `{
+  // line comment
+  "url": "http://example.com/*", /* block */
+  "list": [1, 2,],
+}`
+	want := "{\n  \n  \"url\": \"http://example.com/*\", \n  \"list\": [1, 2]\n}"
+	if got := string(stripJSONComments([]byte(input))); got != want {
+		t.Errorf("stripJSONComments() = %q, want %q", got, want)
+	}
+}
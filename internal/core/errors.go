@@ -0,0 +1,137 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Stage names the step of a run in which a file failed
+type Stage string
+
+const (
+	StageFind     Stage = "find"     // Walking the input paths
+	StageValidate Stage = "validate" // Checking the size limits
+	StageProcess  Stage = "process"  // Reading and transforming the file
+)
+
+// FileError is the failure of one file at one stage of a run
+type FileError struct {
+	Path  string
+	Stage Stage
+	Err   error
+}
+
+func (e *FileError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", e.Stage, e.cause())
+	}
+	return fmt.Sprintf("%s %s: %s", e.Stage, e.Path, e.cause())
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// cause returns the message of the underlying error without the path when
+// it already names the file
+func (e *FileError) cause() string {
+	var mixErr *MixError
+	if errors.As(e.Err, &mixErr) && mixErr.File == e.Path {
+		return mixErr.Message
+	}
+	return e.Err.Error()
+}
+
+// MultiError collects the files that failed during a run, so one unreadable
+// file does not hide the others
+type MultiError struct {
+	Errors []*FileError
+}
+
+// Add records a failure. A MultiError adds all of its failures, a MixError
+// is recorded with its file and other errors are recorded without a path.
+func (m *MultiError) Add(stage Stage, err error) {
+	var multi *MultiError
+	var fileErr *FileError
+	var mixErr *MixError
+	switch {
+	case err == nil:
+	case errors.As(err, &multi):
+		m.Errors = append(m.Errors, multi.Errors...)
+	case errors.As(err, &fileErr):
+		m.Errors = append(m.Errors, fileErr)
+	case errors.As(err, &mixErr):
+		m.Errors = append(m.Errors, &FileError{Path: mixErr.File, Stage: stage, Err: err})
+	default:
+		m.Errors = append(m.Errors, &FileError{Stage: stage, Err: err})
+	}
+}
+
+// Len returns the number of failures
+func (m *MultiError) Len() int {
+	return len(m.Errors)
+}
+
+// Err returns the collected failures as an error, or nil when there are none
+func (m *MultiError) Err() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *MultiError) Error() string {
+	switch m.Len() {
+	case 0:
+		return "no files failed"
+	case 1:
+		return m.Errors[0].Error()
+	default:
+		return fmt.Sprintf("%d files failed, the first: %v", m.Len(), m.Errors[0])
+	}
+}
+
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, err := range m.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ReportEntries returns the failures as report entries
+func (m *MultiError) ReportEntries() []ReportEntry {
+	entries := make([]ReportEntry, len(m.Errors))
+	for i, err := range m.Errors {
+		entries[i] = ReportEntry{
+			Path:   err.Path,
+			Status: FileStatusFailed,
+			Reason: fmt.Sprintf("%s: %s", err.Stage, err.cause()),
+		}
+	}
+	return entries
+}
+
+// WriteTable prints the failures as a table ordered by path
+func (m *MultiError) WriteTable(w io.Writer) error {
+	sorted := append([]*FileError(nil), m.Errors...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "STAGE\tPATH\tERROR\n")
+	for _, err := range sorted {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", err.Stage, err.Path, err.cause())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	noun := "files"
+	if m.Len() == 1 {
+		noun = "file"
+	}
+	_, err := fmt.Fprintf(w, "\n%d %s failed\n", m.Len(), noun)
+	return err
+}
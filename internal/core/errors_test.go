@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiErrorAdd(t *testing.T) {
+	var inner MultiError
+	inner.Add(StageFind, &FileError{Path: "a.go", Stage: StageFind, Err: errors.New("denied")})
+	inner.Add(StageFind, &FileError{Path: "b.go", Stage: StageFind, Err: errors.New("denied")})
+
+	var m MultiError
+	m.Add(StageProcess, nil)
+	if m.Err() != nil {
+		t.Fatalf("Err() = %v, want nil without failures", m.Err())
+	}
+
+	m.Add(StageProcess, &inner)
+	m.Add(StageProcess, &MixError{File: "c.go", Message: "error reading file"})
+	m.Add(StageProcess, errors.New("no files to process"))
+	m.Add(StageValidate, fmt.Errorf("wrapped: %w", &FileError{Path: "d.go", Stage: StageValidate, Err: errors.New("gone")}))
+
+	want := []string{
+		"find a.go: denied",
+		"find b.go: denied",
+		"process c.go: error reading file",
+		"process: no files to process",
+		"validate d.go: gone",
+	}
+	if m.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(want))
+	}
+	for i, err := range m.Errors {
+		if err.Error() != want[i] {
+			t.Errorf("Errors[%d] = %q, want %q", i, err.Error(), want[i])
+		}
+	}
+
+	if got := m.Error(); got != "5 files failed, the first: find a.go: denied" {
+		t.Errorf("Error() = %q", got)
+	}
+	var mixErr *MixError
+	if !errors.As(m.Err(), &mixErr) || mixErr.File != "c.go" {
+		t.Errorf("errors.As(MixError) = %v, want the error of c.go", mixErr)
+	}
+}
+
+func TestMultiErrorReport(t *testing.T) {
+	var m MultiError
+	m.Add(StageProcess, &FileError{Path: "z.go", Stage: StageProcess, Err: &MixError{File: "z.go", Message: "error reading file"}})
+	m.Add(StageFind, &FileError{Path: "a.go", Stage: StageFind, Err: errors.New("permission denied")})
+
+	entries := m.ReportEntries()
+	if len(entries) != 2 || entries[0].Status != FileStatusFailed || entries[0].Reason != "process: error reading file" {
+		t.Errorf("ReportEntries() = %+v", entries)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("WriteTable() = %q, want a header, two rows and a summary", buf.String())
+	}
+	for i, want := range [][]string{{"STAGE", "PATH", "ERROR"}, {"find", "a.go", "permission denied"}, {"process", "z.go", "error reading file"}} {
+		if !strings.HasPrefix(strings.Join(strings.Fields(lines[i]), " "), strings.Join(want, " ")) {
+			t.Errorf("row %d = %q, want %v", i, lines[i], want)
+		}
+	}
+	if lines[4] != "2 files failed" {
+		t.Errorf("summary = %q, want %q", lines[4], "2 files failed")
+	}
+}
+
+func TestProcessFilesFailures(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+		// Every other file is missing and fails
+		if i%2 == 0 {
+			if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		files = append(files, path)
+	}
+
+	tests := []struct {
+		name     string
+		failFast bool
+		check    func(failed int) bool
+	}{
+		{name: "keep going", check: func(failed int) bool { return failed == 10 }},
+		{name: "fail fast", failFast: true, check: func(failed int) bool { return failed >= 1 && failed <= 10 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewFileProcessor(&MixOptions{
+				InputPath:   dir,
+				MaxFileSize: 1024,
+				FailFast:    tt.failFast,
+			})
+			contents, err := processor.ProcessFiles(files)
+
+			var failures *MultiError
+			if !errors.As(err, &failures) {
+				t.Fatalf("ProcessFiles() error = %v, want a MultiError", err)
+			}
+			if !tt.check(failures.Len()) {
+				t.Errorf("ProcessFiles() failed %d files", failures.Len())
+			}
+			for _, fileErr := range failures.Errors {
+				if fileErr.Stage != StageProcess || fileErr.Path == "" {
+					t.Errorf("failure = %+v, want the process stage and the path", fileErr)
+				}
+			}
+			if !tt.failFast && len(contents) != 10 {
+				t.Errorf("ProcessFiles() = %d files, want the 10 that exist", len(contents))
+			}
+		})
+	}
+}
+
+func TestValidateFilesFailures(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present.txt")
+	if err := os.WriteFile(present, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fm := NewFileManager(1024, 4096, OutputTypeXML)
+	valid, err := fm.ValidateFiles([]string{present, filepath.Join(dir, "gone1.txt"), filepath.Join(dir, "gone2.txt")})
+	if err != nil || len(valid) != 1 {
+		t.Fatalf("ValidateFiles() = %v, %v, want the present file", valid, err)
+	}
+
+	var failures *MultiError
+	if !errors.As(fm.Errors(), &failures) || failures.Len() != 2 {
+		t.Fatalf("Errors() = %v, want both missing files", fm.Errors())
+	}
+	if failures.Errors[0].Stage != StageValidate {
+		t.Errorf("Stage = %q, want %q", failures.Errors[0].Stage, StageValidate)
+	}
+}
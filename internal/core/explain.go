@@ -0,0 +1,289 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExplainStep is one decision the selection pipeline took for a file
+type ExplainStep struct {
+	Stage  string `json:"stage"`            // Pipeline stage, such as "pattern" or "size"
+	Result string `json:"result"`           // Outcome of the stage, such as "matched" or "skipped"
+	Detail string `json:"detail,omitempty"` // What the outcome is based on
+}
+
+// Explanation is the decision chain for one file, from the input it was found
+// below to the transformers that processed it
+type Explanation struct {
+	Path     string        `json:"path"`
+	Included bool          `json:"included"`
+	Steps    []ExplainStep `json:"steps"`
+}
+
+func (e *Explanation) add(stage, result, detail string) {
+	e.Steps = append(e.Steps, ExplainStep{Stage: stage, Result: result, Detail: detail})
+}
+
+// Explainer traces a file through the finder, file manager and processor of a
+// run. The finder and manager make the actual decisions, the trace shows
+// which patterns, limits and links they were based on.
+type Explainer struct {
+	Finder    *FileFinder
+	Manager   *FileManager
+	Processor *FileProcessor
+}
+
+// Explain returns why a file is included in or left out of a bundle of the
+// given input paths. The decision chain stops at the first stage that
+// rejects the file.
+func (e *Explainer) Explain(path string, inputs []string) (*Explanation, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving path %q: %w", path, err)
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting file info: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, explain a file below it", path)
+	}
+	x := &Explanation{Path: absPath}
+
+	entry, ok := e.locate(x, absPath, inputs)
+	if !ok || !e.explainRules(x, entry) || !e.explainLink(x, entry) {
+		return x, nil
+	}
+	if !e.explainFinder(x, absPath, inputs) || !e.explainSize(x, absPath) {
+		return x, nil
+	}
+
+	result := e.Processor.traceFile(context.Background(), absPath, func(step ExplainStep) {
+		x.Steps = append(x.Steps, step)
+	})
+	switch {
+	case result.Error != nil:
+		x.add("process", "failed", result.Error.Error())
+	case result.Skipped != nil:
+		x.add("process", "skipped", result.Skipped.Reason)
+	case result.Content.Size == 0 && result.Content.Target == "":
+		x.add("process", "skipped", "the processed content is empty")
+	default:
+		x.Included = true
+	}
+	return x, nil
+}
+
+// locate finds the input path the file is walked from, together with its
+// path relative to that input
+func (e *Explainer) locate(x *Explanation, absPath string, inputs []string) (walkEntry, bool) {
+	for _, input := range inputs {
+		root, err := filepath.Abs(input)
+		if err != nil {
+			continue
+		}
+		if root == absPath {
+			x.add("input", "input path", "the file is given as an input and matched by its name")
+			return walkEntry{path: absPath, rel: filepath.Base(absPath), input: true}, true
+		}
+		rel, err := filepath.Rel(root, absPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		x.add("input", "found", fmt.Sprintf("below %s as %s", root, rel))
+		return walkEntry{path: absPath, rel: rel}, true
+	}
+	x.add("input", "outside", "the file is not below any input path: "+strings.Join(inputs, ", "))
+	return walkEntry{}, false
+}
+
+// explainRules traces the directories above the file, every pattern rule,
+// the walk limits and the filters
+func (e *Explainer) explainRules(x *Explanation, entry walkEntry) bool {
+	ff := e.Finder
+	if !entry.input {
+		dirs := strings.Split(entry.rel, "/")
+		for i := 1; i < len(dirs); i++ {
+			dir := strings.Join(dirs[:i], "/")
+			if reason := ff.walkLimit(dir, true); reason != "" {
+				x.add("directory", "pruned", dir+": "+reason)
+				return false
+			}
+			if rule, prune := ff.rules.pruningRule(dir); prune {
+				if rule != nil {
+					x.add("directory", "pruned", fmt.Sprintf("%s: matched exclude pattern %q", dir, rule.pattern))
+				} else {
+					x.add("directory", "pruned", dir+": no include pattern can match below it")
+				}
+				return false
+			}
+		}
+	}
+
+	for _, rule := range ff.rules.rules {
+		kind := "include"
+		if rule.exclude {
+			kind = "exclude"
+		}
+		matched, err := rule.match(entry.rel)
+		switch {
+		case err != nil:
+			x.add("pattern", "invalid", fmt.Sprintf("%s %q: %v", kind, rule.pattern, err))
+		case matched:
+			x.add("pattern", "matched", fmt.Sprintf("%s %q", kind, rule.pattern))
+		default:
+			x.add("pattern", "no match", fmt.Sprintf("%s %q", kind, rule.pattern))
+		}
+	}
+
+	rule, err := ff.rules.lastMatch(entry.rel)
+	switch {
+	case err != nil:
+		x.add("patterns", "failed", err.Error())
+		return false
+	case rule != nil && rule.exclude:
+		x.add("patterns", "excluded", fmt.Sprintf("the last matching pattern %q excludes it", rule.pattern))
+		return false
+	case rule != nil:
+		x.add("patterns", "included", fmt.Sprintf("the last matching pattern %q includes it", rule.pattern))
+	case ff.rules.includeAll:
+		x.add("patterns", "included", "no pattern matched and there are no include patterns")
+	default:
+		x.add("patterns", "excluded", "did not match any include pattern")
+		return false
+	}
+
+	// Links that are not followed are matched by their name only
+	_, link := linkTarget(entry.path)
+	link = link && ff.symlinks != SymlinkFollow
+	if !link {
+		if reason := ff.outputReason(entry); reason != "" {
+			x.add("output", "skipped", reason)
+			return false
+		}
+	}
+
+	var reason string
+	if link {
+		if !entry.input {
+			reason = ff.walkLimit(entry.rel, false)
+		}
+	} else {
+		reason, err = ff.filterReason(entry)
+	}
+	switch {
+	case err != nil:
+		x.add("filter", "failed", err.Error())
+		return false
+	case reason != "":
+		x.add("filter", "rejected", reason)
+		return false
+	case ff.filter != nil:
+		x.add("filter", "passed", ff.filter.String())
+	}
+	return true
+}
+
+// explainLink traces how links on the way to the file are resolved
+func (e *Explainer) explainLink(x *Explanation, entry walkEntry) bool {
+	policy := e.Finder.symlinks
+	realPath, err := e.Finder.GetRealPath(entry.path)
+	target, link := linkTarget(entry.path)
+	switch {
+	case !link && (err != nil || realPath == entry.path):
+		x.add("symlink", "none", "regular file")
+	case !link && policy != SymlinkFollow:
+		x.add("symlink", "not walked", fmt.Sprintf("reached through a linked directory, which is not walked with --symlinks=%s", policy))
+		return false
+	case !link:
+		x.add("symlink", "resolved", "reached through a linked directory, the real path is "+realPath)
+	case policy == SymlinkSkip:
+		x.add("symlink", "skipped", "links are not followed with --symlinks=skip")
+		return false
+	case policy == SymlinkRecord:
+		x.add("symlink", "recorded", "bundled without content as a link to "+target)
+	case err != nil:
+		x.add("symlink", "broken", "broken link to "+target+", there is no content to bundle")
+		return false
+	default:
+		target, err := os.Stat(realPath)
+		if err == nil && target.IsDir() {
+			x.add("symlink", "directory", "links to the directory "+realPath+", explain a file below it")
+			return false
+		}
+		x.add("symlink", "resolved", "links to "+realPath)
+	}
+	return true
+}
+
+// explainFinder traces whether the finder keeps the file under its own path.
+// A file reached through a link is bundled once, under its real path when
+// that path is below an input and passes the rules itself. Only the real
+// path and its directories are evaluated, the inputs are not walked.
+func (e *Explainer) explainFinder(x *Explanation, absPath string, inputs []string) bool {
+	_, link := linkTarget(absPath)
+	realPath, err := e.Finder.GetRealPath(absPath)
+	if err != nil || realPath == absPath || (link && e.Finder.symlinks == SymlinkRecord) {
+		x.add("finder", "selected", "")
+		return true
+	}
+
+	real := &Explanation{Path: realPath}
+	if entry, ok := e.locate(real, realPath, inputs); ok && e.explainRules(real, entry) && e.explainLink(real, entry) {
+		x.add("finder", "duplicate", "the same file is selected as "+realPath)
+		return false
+	}
+	x.add("finder", "selected", "")
+	return true
+}
+
+// explainSize traces the size checks of the file manager
+func (e *Explainer) explainSize(x *Explanation, absPath string) bool {
+	fm := e.Manager
+	if _, ok := linkTarget(absPath); ok && fm.symlinks == SymlinkRecord {
+		x.add("size", "passed", "recorded links have no content")
+		return true
+	}
+	if _, err := fm.FilterFiles([]string{absPath}); err != nil {
+		if failures, ok := fm.Errors().(*MultiError); ok {
+			x.add("size", "failed", failures.Errors[0].cause())
+			return false
+		}
+		for _, skipped := range fm.SkippedFiles() {
+			x.add("size", "skipped", skipped.Reason)
+			return false
+		}
+		x.add("size", "failed", err.Error())
+		return false
+	}
+
+	info, err := statFile(absPath)
+	if err != nil {
+		x.add("size", "failed", err.Error())
+		return false
+	}
+	if info.Size() > fm.maxFileSize {
+		x.add("size", "oversize", fmt.Sprintf("%s exceeds the limit of %s, reduced with --oversize=%s",
+			formatSize(info.Size()), formatSize(fm.maxFileSize), fm.oversize))
+	} else {
+		x.add("size", "passed", fmt.Sprintf("%s within the limit of %s", formatSize(info.Size()), formatSize(fm.maxFileSize)))
+	}
+	return true
+}
+
+// hasCleaner reports whether a transformer chain runs the code cleaner
+func hasCleaner(chain []Transformer) bool {
+	for _, t := range chain {
+		if pt, ok := t.(*patternTransformer); ok {
+			t = pt.Transformer
+		}
+		if _, ok := t.(*CleanerTransformer); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// stageResults returns the "stage:result" pairs of an explanation
+func stageResults(x *Explanation) []string {
+	var got []string
+	for _, step := range x.Steps {
+		got = append(got, step.Stage+":"+step.Result)
+	}
+	return got
+}
+
+func TestExplain(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":         "package main\n\n// main runs\nfunc main() {}\n",
+		"big.go":          "package main\n" + strings.Repeat("// filler\n", 200),
+		"gen/api.go":      "package gen\n",
+		"vendor/x/lib.go": "package x\n",
+		"notes.txt":       "notes\n",
+		"image.go":        "\x00\x01\x02\x03\x04\x05\x06\x07",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "main.go"), filepath.Join(root, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	newExplainer := func(cleanerOptions *cleaner.CleanerOptions) *Explainer {
+		return &Explainer{
+			Finder:    NewFileFinder([]string{"*.go"}, []string{"vendor/**", "gen/**", "!gen/api.go"}, true),
+			Manager:   NewFileManager(1024, 1024*1024, OutputTypeXML),
+			Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024, CleanerOptions: cleanerOptions, SkipBinary: true}),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		cleaner  *cleaner.CleanerOptions
+		included bool
+		want     []string
+	}{
+		{
+			name:     "included",
+			file:     "main.go",
+			included: true,
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:included", "symlink:none", "finder:selected", "size:passed",
+				"language:go", "clean:disabled",
+			},
+		},
+		{
+			name:     "cleaned",
+			file:     "main.go",
+			cleaner:  cleaner.DefaultOptions(),
+			included: true,
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:included", "symlink:none", "finder:selected", "size:passed",
+				"language:go", "clean:applied",
+			},
+		},
+		{
+			name: "no include pattern",
+			file: "notes.txt",
+			want: []string{
+				"input:found", "pattern:no match", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:excluded",
+			},
+		},
+		{
+			name: "pruned directory",
+			file: "vendor/x/lib.go",
+			want: []string{"input:found", "directory:pruned"},
+		},
+		{
+			name:     "re-included by a later pattern",
+			file:     "gen/api.go",
+			included: true,
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:matched", "pattern:matched",
+				"patterns:included", "symlink:none", "finder:selected", "size:passed",
+				"language:go", "clean:disabled",
+			},
+		},
+		{
+			name: "over the size limit",
+			file: "big.go",
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:included", "symlink:none", "finder:selected", "size:skipped",
+			},
+		},
+		{
+			name: "binary",
+			file: "image.go",
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:included", "symlink:none", "finder:selected", "size:passed", "process:skipped",
+			},
+		},
+		{
+			name: "link to a selected file",
+			file: "link.go",
+			want: []string{
+				"input:found", "pattern:matched", "pattern:no match", "pattern:no match", "pattern:no match",
+				"patterns:included", "symlink:resolved", "finder:duplicate",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := newExplainer(tt.cleaner).Explain(filepath.Join(root, filepath.FromSlash(tt.file)), []string{root})
+			if err != nil {
+				t.Fatalf("Explain() error = %v", err)
+			}
+			if x.Included != tt.included {
+				t.Errorf("Explain() included = %v, want %v", x.Included, tt.included)
+			}
+			// The markup transformer runs on every file and is left out of the comparison
+			var got []string
+			for _, step := range stageResults(x) {
+				if !strings.HasPrefix(step, "markup:") {
+					got = append(got, step)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Explain() steps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainDoesNotWalk(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "main.go", "notes.txt", "vendor/lib.go")
+
+	// Only the explained file and its directories are matched, the other
+	// files of the input are never reached
+	e := &Explainer{
+		Finder:    NewFileFinder([]string{"*.go"}, []string{"vendor/**"}, true),
+		Manager:   NewFileManager(1024, 1024, OutputTypeXML),
+		Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024}),
+	}
+	x, err := e.Explain(filepath.Join(root, "main.go"), []string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Included {
+		t.Errorf("Explain() = %+v, want main.go included", x)
+	}
+	if excluded := e.Finder.ExcludedFiles(); len(excluded) != 0 {
+		t.Errorf("ExcludedFiles() = %+v, want no other file evaluated", excluded)
+	}
+}
+
+func TestExplainOutsideInputs(t *testing.T) {
+	root := t.TempDir()
+	other := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(other, []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Explainer{
+		Finder:    NewFileFinder([]string{"*.go"}, nil, true),
+		Manager:   NewFileManager(1024, 1024, OutputTypeXML),
+		Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024}),
+	}
+	x, err := e.Explain(other, []string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Included || !reflect.DeepEqual(stageResults(x), []string{"input:outside"}) {
+		t.Errorf("Explain() = %+v, want the file outside the inputs", x)
+	}
+
+	if _, err := e.Explain(root, []string{root}); err == nil {
+		t.Error("Explain() error = nil, want error for a directory")
+	}
+}
+
+func TestExplainDuplicate(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "real", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "real", "pkg", "a.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	// Both inputs reach the same file, the finder keeps the path found first
+	inputs := []string{filepath.Join(root, "real", "pkg"), filepath.Join(root, "link", "pkg")}
+
+	e := &Explainer{
+		Finder:    NewFileFinder([]string{"*.go"}, nil, true),
+		Manager:   NewFileManager(1024, 1024, OutputTypeXML),
+		Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024}),
+	}
+	x, err := e.Explain(filepath.Join(root, "link", "pkg", "a.go"), inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var finder ExplainStep
+	for _, step := range x.Steps {
+		switch step.Stage {
+		case "symlink":
+			if step.Result != "resolved" {
+				t.Errorf("symlink step = %+v, want the linked directory resolved", step)
+			}
+		case "finder":
+			finder = step
+		}
+	}
+	if finder.Result != "duplicate" || x.Included || !strings.Contains(finder.Detail, filepath.Join(root, "real", "pkg", "a.go")) {
+		t.Errorf("Explain() = %+v, want the file reported as a duplicate of the real path", x)
+	}
+
+	// The real path is the one selected
+	x, err = e.Explain(filepath.Join(root, "real", "pkg", "a.go"), inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Included {
+		t.Errorf("Explain() = %+v, want the real path included", x)
+	}
+}
+
+func TestExplainSymlinkPolicy(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "main.go", "link.go -> main.go", "broken.go -> gone.go")
+
+	tests := []struct {
+		policy   SymlinkPolicy
+		file     string
+		included bool
+		want     []string
+	}{
+		{policy: SymlinkRecord, file: "link.go", included: true, want: []string{"symlink:recorded", "finder:selected", "size:passed"}},
+		{policy: SymlinkRecord, file: "broken.go", included: true, want: []string{"symlink:recorded", "finder:selected", "size:passed"}},
+		{policy: SymlinkSkip, file: "link.go", want: []string{"symlink:skipped"}},
+		{policy: SymlinkFollow, file: "broken.go", want: []string{"symlink:broken"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy)+" "+tt.file, func(t *testing.T) {
+			e := &Explainer{
+				Finder:    NewFileFinder([]string{"*.go"}, nil, true),
+				Manager:   NewFileManager(1024, 1024, OutputTypeXML),
+				Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024, Symlinks: tt.policy}),
+			}
+			e.Finder.SetSymlinks(tt.policy)
+			e.Manager.SetSymlinks(tt.policy)
+
+			x, err := e.Explain(filepath.Join(root, tt.file), []string{root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Included != tt.included {
+				t.Errorf("Explain() included = %v, want %v", x.Included, tt.included)
+			}
+			var got []string
+			for _, step := range stageResults(x) {
+				if strings.HasPrefix(step, "symlink:") || strings.HasPrefix(step, "finder:") || strings.HasPrefix(step, "size:") {
+					got = append(got, step)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Explain() steps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainOutput(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "main.go")
+	bundle := filepath.Join(root, "bundle.xml")
+	if err := os.WriteFile(bundle, []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<documents generator=\"filefusion\">\n</documents>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"bundle.xml", "main.go"} {
+		e := &Explainer{
+			Finder:    NewFileFinder([]string{"*.go", "*.xml"}, nil, true),
+			Manager:   NewFileManager(1024, 1024, OutputTypeXML),
+			Processor: NewFileProcessor(&MixOptions{MaxFileSize: 1024}),
+		}
+		e.Finder.SetOutputs([]string{filepath.Join(root, "main.go")})
+
+		x, err := e.Explain(filepath.Join(root, file), []string{root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Included || !reflect.DeepEqual(stageResults(x)[len(x.Steps)-1:], []string{"output:skipped"}) {
+			t.Errorf("Explain(%s) = %v, want it skipped as an output", file, stageResults(x))
+		}
+	}
+}
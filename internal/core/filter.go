@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterTerm is one element of a filter expression as given on the command
+// line: a predicate such as {"min-size", "10KB"} or an "and" or "or" operator
+type FilterTerm struct {
+	Name  string
+	Value string
+}
+
+// String returns the term in flag form, e.g. "--min-size 10KB"
+func (t FilterTerm) String() string {
+	if t.Value == "" {
+		return "--" + t.Name
+	}
+	return "--" + t.Name + " " + t.Value
+}
+
+// Predicate tests a file found by the FileFinder after its patterns matched
+type Predicate interface {
+	Match(c *Candidate) (bool, error)
+	String() string // Flag form used to report which predicate rejected a file
+}
+
+// Candidate is a file tested by filter predicates. Its metadata is read on
+// first use and shared between the predicates, its content is streamed by
+// each predicate that reads it.
+type Candidate struct {
+	Path string
+	info fs.FileInfo
+}
+
+// Info returns the file information of the candidate
+func (c *Candidate) Info() (fs.FileInfo, error) {
+	if c.info == nil {
+		info, err := statFile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		c.info = info
+	}
+	return c.info, nil
+}
+
+// Open opens the content of the candidate for reading
+func (c *Candidate) Open() (io.ReadCloser, error) {
+	return openFile(c.Path)
+}
+
+// Filter selects files with predicates on their metadata and content. As in
+// find(1), adjacent predicates must all match and --or separates
+// alternatives, so "a b --or c" selects files matching a and b, or c.
+type Filter struct {
+	groups [][]Predicate
+}
+
+// ParseFilter builds a filter from terms in command-line order. Relative ages
+// such as "7d" are measured back from now. It returns nil when there are no
+// predicates.
+func ParseFilter(terms []FilterTerm, now time.Time) (*Filter, error) {
+	var groups [][]Predicate
+	var group []Predicate
+	var operator *FilterTerm
+
+	for i := range terms {
+		term := terms[i]
+		switch term.Name {
+		case "and", "or":
+			if len(group) == 0 || operator != nil {
+				return nil, fmt.Errorf("%s must follow a predicate", term)
+			}
+			if term.Name == "or" {
+				groups = append(groups, group)
+				group = nil
+			}
+			operator = &terms[i]
+		default:
+			p, err := parsePredicate(term, now)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, p)
+			operator = nil
+		}
+	}
+	if operator != nil {
+		return nil, fmt.Errorf("%s must be followed by a predicate", *operator)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	// Test metadata before reading content, the outcome of a group does not
+	// depend on the order of its predicates
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return predicateCost(group[i]) < predicateCost(group[j])
+		})
+	}
+	return &Filter{groups: groups}, nil
+}
+
+// parsePredicate creates the predicate for a term
+func parsePredicate(term FilterTerm, now time.Time) (Predicate, error) {
+	switch term.Name {
+	case "min-size":
+		size, err := parseSize(term.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", term, err)
+		}
+		return minSizePredicate{FilterTerm: term, min: size}, nil
+	case "newer-than", "older-than":
+		t, err := ParseAge(term.Value, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", term, err)
+		}
+		return modTimePredicate{FilterTerm: term, t: t, newer: term.Name == "newer-than"}, nil
+	case "contains", "not-contains":
+		re, err := regexp.Compile(term.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", term, err)
+		}
+		return contentPredicate{FilterTerm: term, re: re, negate: term.Name == "not-contains"}, nil
+	}
+	return nil, fmt.Errorf("unknown filter %s", term)
+}
+
+// predicateCost orders predicates that only stat the file before those that
+// read it
+func predicateCost(p Predicate) int {
+	if _, ok := p.(contentPredicate); ok {
+		return 1
+	}
+	return 0
+}
+
+// Match reports whether a file is selected. For a rejected file it also
+// returns which predicates rejected it, one for each alternative.
+func (f *Filter) Match(path string) (bool, string, error) {
+	c := &Candidate{Path: path}
+	var rejected []string
+
+groups:
+	for _, group := range f.groups {
+		for _, p := range group {
+			ok, err := p.Match(c)
+			if err != nil {
+				return false, "", err
+			}
+			if !ok {
+				rejected = append(rejected, p.String())
+				continue groups
+			}
+		}
+		return true, "", nil
+	}
+	return false, "rejected by " + strings.Join(rejected, ", "), nil
+}
+
+// String returns the filter in flag form
+func (f *Filter) String() string {
+	alternatives := make([]string, len(f.groups))
+	for i, group := range f.groups {
+		terms := make([]string, len(group))
+		for j, p := range group {
+			terms[j] = p.String()
+		}
+		alternatives[i] = strings.Join(terms, " ")
+	}
+	return strings.Join(alternatives, " --or ")
+}
+
+// minSizePredicate selects files of at least a size
+type minSizePredicate struct {
+	FilterTerm
+	min int64
+}
+
+func (p minSizePredicate) Match(c *Candidate) (bool, error) {
+	info, err := c.Info()
+	if err != nil {
+		return false, err
+	}
+	return info.Size() >= p.min, nil
+}
+
+// modTimePredicate selects files modified after or before a time
+type modTimePredicate struct {
+	FilterTerm
+	t     time.Time
+	newer bool
+}
+
+func (p modTimePredicate) Match(c *Candidate) (bool, error) {
+	info, err := c.Info()
+	if err != nil {
+		return false, err
+	}
+	if p.newer {
+		return info.ModTime().After(p.t), nil
+	}
+	return info.ModTime().Before(p.t), nil
+}
+
+// contentPredicate selects files whose content matches, or does not match,
+// a regular expression
+type contentPredicate struct {
+	FilterTerm
+	re     *regexp.Regexp
+	negate bool
+}
+
+// Match streams the file, so files of any size are tested without being
+// held in memory
+func (p contentPredicate) Match(c *Candidate) (bool, error) {
+	f, err := c.Open()
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// MatchReader stops at the first read error as if the file ended there
+	r := &errorReader{Reader: f}
+	matched := p.re.MatchReader(bufio.NewReader(r))
+	if r.err != nil {
+		return false, r.err
+	}
+	return matched != p.negate, nil
+}
+
+// errorReader remembers the first error other than io.EOF of a reader
+type errorReader struct {
+	io.Reader
+	err error
+}
+
+func (r *errorReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// ageUnits maps the units of relative ages to their length
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ageRegex matches relative ages such as "7d" or "12h"
+var ageRegex = regexp.MustCompile(`^(\d+)([smhdw])$`)
+
+// ParseAge converts an age to the point in time it designates. Ages are
+// relative to now ("30m", "12h", "7d", "2w", or a Go duration such as
+// "1h30m"), dates ("2024-01-31") or RFC 3339 timestamps.
+func ParseAge(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if m := ageRegex.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-time.Duration(n) * ageUnits[m[2]]), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid age %q: use a duration such as 7d or 12h, a date or an RFC 3339 time", value)
+}
+
+// LogFiltered logs the files rejected by filters with the predicates that
+// rejected them
+func LogFiltered(entries []ReportEntry, level slog.Level) {
+	for _, entry := range entries {
+		if entry.Status == FileStatusFiltered {
+			logEvent(level, EventFileFiltered, entry.Path, entry.Reason)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		terms   []FilterTerm
+		want    string // Filter in flag form, empty for no filter
+		wantErr string
+	}{
+		{name: "no terms"},
+		{
+			name:  "implicit and",
+			terms: []FilterTerm{{"min-size", "1KB"}, {"newer-than", "7d"}},
+			want:  "--min-size 1KB --newer-than 7d",
+		},
+		{
+			name:  "explicit and",
+			terms: []FilterTerm{{"min-size", "1KB"}, {"and", ""}, {"older-than", "2024-01-01"}},
+			want:  "--min-size 1KB --older-than 2024-01-01",
+		},
+		{
+			name:  "or binds weaker than and",
+			terms: []FilterTerm{{"contains", "TODO"}, {"min-size", "1KB"}, {"or", ""}, {"newer-than", "1d"}},
+			want:  "--min-size 1KB --contains TODO --or --newer-than 1d",
+		},
+		{name: "leading operator", terms: []FilterTerm{{"or", ""}, {"min-size", "1KB"}}, wantErr: "--or must follow a predicate"},
+		{name: "double operator", terms: []FilterTerm{{"min-size", "1KB"}, {"and", ""}, {"or", ""}, {"min-size", "2KB"}}, wantErr: "--or must follow a predicate"},
+		{name: "trailing operator", terms: []FilterTerm{{"min-size", "1KB"}, {"and", ""}}, wantErr: "--and must be followed by a predicate"},
+		{name: "invalid size", terms: []FilterTerm{{"min-size", "lots"}}, wantErr: "invalid --min-size lots"},
+		{name: "invalid age", terms: []FilterTerm{{"newer-than", "soon"}}, wantErr: "invalid --newer-than soon"},
+		{name: "invalid regex", terms: []FilterTerm{{"contains", "("}}, wantErr: "invalid --contains ("},
+		{name: "unknown predicate", terms: []FilterTerm{{"max-size", "1KB"}}, wantErr: "unknown filter --max-size 1KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.terms, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseFilter() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			got := ""
+			if filter != nil {
+				got = filter.String()
+			}
+			if got != tt.want {
+				t.Errorf("ParseFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "2w", want: now.AddDate(0, 0, -14)},
+		{value: "12h", want: now.Add(-12 * time.Hour)},
+		{value: "30m", want: now.Add(-30 * time.Minute)},
+		{value: "1h30m", want: now.Add(-90 * time.Minute)},
+		{value: "2024-01-31", want: time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+		{value: "2024-01-31T10:00:00Z", want: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+		{value: "7y", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAge(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAge(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAge(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseAge(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	files := map[string]struct {
+		content string
+		age     time.Duration
+	}{
+		"small-new.go": {content: "package a // TODO", age: time.Hour},
+		"large-old.go": {content: "package b\n" + strings.Repeat("// filler\n", 200), age: 30 * 24 * time.Hour},
+		"small-old.go": {content: "package c", age: 30 * 24 * time.Hour},
+		"huge.go":      {content: "package d\n" + strings.Repeat("// filler\n", 1<<17) + "// TODO at the end\n", age: time.Hour},
+	}
+	for name, f := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(f.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-f.age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		terms      []FilterTerm
+		file       string
+		want       bool
+		wantReason string
+	}{
+		{name: "size", terms: []FilterTerm{{"min-size", "1KB"}}, file: "large-old.go", want: true},
+		{name: "too small", terms: []FilterTerm{{"min-size", "1KB"}}, file: "small-new.go", wantReason: "rejected by --min-size 1KB"},
+		{name: "newer", terms: []FilterTerm{{"newer-than", "7d"}}, file: "small-new.go", want: true},
+		{name: "not newer", terms: []FilterTerm{{"newer-than", "7d"}}, file: "small-old.go", wantReason: "rejected by --newer-than 7d"},
+		{name: "older", terms: []FilterTerm{{"older-than", "7d"}}, file: "small-old.go", want: true},
+		{name: "contains", terms: []FilterTerm{{"contains", `TODO|FIXME`}}, file: "small-new.go", want: true},
+		{name: "not contains", terms: []FilterTerm{{"not-contains", "TODO"}}, file: "small-new.go", wantReason: "rejected by --not-contains TODO"},
+		{name: "contains past the start of a large file", terms: []FilterTerm{{"contains", `(?m)^// TODO at the end$`}}, file: "huge.go", want: true},
+		{name: "not contains in a large file", terms: []FilterTerm{{"not-contains", "FIXME"}}, file: "huge.go", want: true},
+		{
+			name:       "first failing predicate of a group",
+			terms:      []FilterTerm{{"contains", "TODO"}, {"newer-than", "7d"}},
+			file:       "small-old.go",
+			wantReason: "rejected by --newer-than 7d",
+		},
+		{
+			name:  "second alternative",
+			terms: []FilterTerm{{"contains", "TODO"}, {"or", ""}, {"min-size", "1KB"}},
+			file:  "large-old.go",
+			want:  true,
+		},
+		{
+			name:       "every alternative rejects",
+			terms:      []FilterTerm{{"contains", "TODO"}, {"or", ""}, {"min-size", "1KB"}},
+			file:       "small-old.go",
+			wantReason: "rejected by --contains TODO, --min-size 1KB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.terms, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, reason, err := filter.Match(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want || reason != tt.wantReason {
+				t.Errorf("Match() = %v, %q, want %v, %q", got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFilterMatchMissingFile(t *testing.T) {
+	filter, err := ParseFilter([]FilterTerm{{"min-size", "1B"}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := filter.Match(filepath.Join(t.TempDir(), "missing.go")); err == nil {
+		t.Error("Match() error = nil, want error for a missing file")
+	}
+}
+
+func TestFilterMatchReadError(t *testing.T) {
+	filter, err := ParseFilter([]FilterTerm{{"not-contains", "TODO"}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A directory opens but cannot be read, which must not count as no match
+	if _, _, err := filter.Match(t.TempDir()); err == nil {
+		t.Error("Match() error = nil, want the read error")
+	}
+}
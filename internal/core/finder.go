@@ -2,168 +2,377 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
-
-	"github.com/bmatcuk/doublestar/v4"
 )
 
 // FileFinder handles file pattern matching and collection with support for
 // glob patterns, symlinks, and parallel processing.
 type FileFinder struct {
-	includes       []string        // Glob patterns for files to include
-	excludes       []string        // Glob patterns for files to exclude
-	followSymlinks bool            // Whether to follow symbolic links
-	seenPaths      map[string]bool // Track real paths we've seen to prevent duplicates
-	seenLinks      map[string]bool // Track symlinks we've seen for reference
-	mu             sync.Mutex      // Protects concurrent access to seen maps
+	includes  []string        // Glob patterns for files to include
+	excludes  []string        // Glob patterns for files to exclude
+	rules     *ruleSet        // Include and exclude rules in evaluation order
+	filter    *Filter         // Predicates files must satisfy after the rules, if any
+	maxDepth  int             // Deepest level of files below an input root, 0 for no limit
+	hidden    bool            // Whether hidden files and directories are walked
+	failFast  bool            // Whether the walk stops at the first path that fails
+	symlinks  SymlinkPolicy   // How symbolic links are handled
+	outputs   map[string]bool // Output paths of the run, which are never inputs
+	seenPaths map[string]bool // Track real paths we've seen to prevent duplicates
+	seenLinks map[string]bool // Track symlinks we've seen for reference
+	excluded  []ReportEntry   // Files rejected by an exclude pattern
+	mu        sync.Mutex      // Protects concurrent access to seen maps and excluded
 }
 
 // Result represents the outcome of a file finding operation.
-// It can contain either a matched file path or an error.
+// It can contain either a matched file path or an error for a path.
 type Result struct {
-	Path string // The path of the matched file
-	Err  error  // Any error encountered during processing
+	Path     string // The path of the matched file, or of the path that failed
+	Err      error  // Any error encountered during processing
+	realPath string // Path with links resolved, used to bundle each file once
+	link     bool   // Whether the path is a followed link to a file
 }
 
 // NewFileFinder creates a new FileFinder with the specified include and exclude patterns.
-// The followSymlinks parameter determines whether symbolic links should be followed.
+// Patterns are matched relative to each input root, and the exclude patterns
+// follow the include patterns in one rule list where the last matching rule
+// wins. A "!" prefix inverts a pattern, so "!*_test.go" among the excludes
+// brings back files excluded by an earlier rule.
+// The followSymlinks parameter determines whether symbolic links are followed
+// or skipped, SetSymlinks selects any other policy.
 func NewFileFinder(includes, excludes []string, followSymlinks bool) *FileFinder {
+	symlinks := SymlinkSkip
+	if followSymlinks {
+		symlinks = SymlinkFollow
+	}
 	return &FileFinder{
-		includes:       includes,
-		excludes:       excludes,
-		followSymlinks: followSymlinks,
-		seenPaths:      make(map[string]bool),
-		seenLinks:      make(map[string]bool),
+		includes:  includes,
+		excludes:  excludes,
+		rules:     compileRules(includes, excludes),
+		symlinks:  symlinks,
+		hidden:    true,
+		seenPaths: make(map[string]bool),
+		seenLinks: make(map[string]bool),
+	}
+}
+
+// SetSymlinks sets how symbolic links are handled. Followed links to a file
+// are kept only when no other path to the same file is selected, and broken
+// links are left out. Recorded links are matched by their own name and
+// passed on without being followed.
+func (ff *FileFinder) SetSymlinks(policy SymlinkPolicy) {
+	ff.symlinks = policy
+}
+
+// SetOutputs sets the output paths of the run. They are left out of the
+// matches together with bundles written by earlier runs.
+func (ff *FileFinder) SetOutputs(paths []string) {
+	ff.outputs = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		ff.outputs[absPath] = true
+		// The output may not exist yet, so only its directory is resolved
+		if dir, err := ff.GetRealPath(filepath.Dir(absPath)); err == nil {
+			ff.outputs[filepath.Join(dir, filepath.Base(absPath))] = true
+		}
 	}
 }
 
+// SetFilter sets the predicates on metadata and content that files matching
+// the patterns must also satisfy
+func (ff *FileFinder) SetFilter(filter *Filter) {
+	ff.filter = filter
+}
+
+// SetFailFast sets whether the walk stops at the first path that fails. The
+// matches found until then are still returned with the error.
+func (ff *FileFinder) SetFailFast(failFast bool) {
+	ff.failFast = failFast
+}
+
+// SetMaxDepth limits the walk to files at most depth levels below each input
+// root, where files directly in the root are at level 1. Zero removes the limit.
+func (ff *FileFinder) SetMaxDepth(depth int) {
+	ff.maxDepth = depth
+}
+
+// SetHidden sets whether files and directories whose name starts with a dot
+// are walked. They are walked by default, except version control directories
+// such as .git, which are never walked.
+func (ff *FileFinder) SetHidden(hidden bool) {
+	ff.hidden = hidden
+}
+
 // FindMatchingFiles returns all files that match the include patterns and don't match any exclude patterns.
-// It processes directories in parallel using a worker pool for improved performance.
-// Returns a slice of matched file paths and any error encountered during processing.
+// Directories are read in parallel by a pool of goroutines sized to the number of
+// available CPUs, which share the work of every input root, and directories the
+// exclude patterns cover entirely are skipped without being read.
+// Returns the matched file paths in walk order. Paths that could not be walked
+// are returned as a *MultiError next to the files found elsewhere.
 func (ff *FileFinder) FindMatchingFiles(basePaths []string) ([]string, error) {
-	resultChan := make(chan Result)
-	var wg sync.WaitGroup
-
-	// Create a worker pool sized to the number of available CPUs
-	numWorkers := runtime.GOMAXPROCS(0)
-	pathChan := make(chan string, len(basePaths))
+	// Cancelled on the first failure with SetFailFast, which stops the
+	// walkers from reading further directories
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Start worker goroutines
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go ff.worker(pathChan, resultChan, &wg)
-	}
+	resultChan := make(chan Result)
 
-	// Feed paths to workers in a separate goroutine
 	go func() {
-		for _, path := range basePaths {
-			pathChan <- path
+		var wg sync.WaitGroup
+		var roots []walkEntry
+		for _, basePath := range basePaths {
+			// Walk archives and git revisions through their file system
+			if src, ok := lookupSource(basePath); ok {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := ff.walkSource(ctx, src, resultChan); err != nil && ctx.Err() == nil {
+						resultChan <- Result{Path: basePath, Err: fmt.Errorf("error walking %q: %w", basePath, err)}
+					}
+				}()
+				continue
+			}
+			if root, ok := ff.openRoot(basePath, resultChan); ok {
+				roots = append(roots, root)
+			}
 		}
-		close(pathChan)
-	}()
 
-	// Close result channel when all workers finish
-	go func() {
+		newDirWalker(ctx, ff, runtime.GOMAXPROCS(0), resultChan).run(roots)
 		wg.Wait()
 		close(resultChan)
 	}()
 
 	// Collect and deduplicate results
 	var matches []string
+	var links []Result
 	seen := make(map[string]bool)
-	var firstErr error
+	selectedAs := make(map[string]string) // Selected path by real path
+	var errs MultiError
 
 	for result := range resultChan {
+		// Drain what the walkers send until they notice the cancellation
+		if ctx.Err() != nil {
+			continue
+		}
 		if result.Err != nil {
-			// Store only the first error but continue processing
-			if firstErr == nil {
-				firstErr = result.Err
+			// Keep every failure and continue with the rest of the walk,
+			// unless the walk stops at the first one
+			errs.Add(StageFind, &FileError{Path: result.Path, Stage: StageFind, Err: result.Err})
+			if ff.failFast {
+				cancel()
 			}
 			continue
 		}
+		if result.link {
+			links = append(links, result)
+			continue
+		}
 
 		// Deduplicate matches
 		if !seen[result.Path] {
 			matches = append(matches, result.Path)
 			seen[result.Path] = true
+			if result.realPath != "" {
+				selectedAs[result.realPath] = result.Path
+			}
 		}
 	}
 
-	if firstErr != nil {
-		return matches, fmt.Errorf("errors occurred while finding files: %w", firstErr)
+	// A followed link is kept only when its target is not selected under
+	// another path, so every file is bundled once whatever order it was found in
+	sort.Slice(links, func(i, j int) bool { return links[i].Path < links[j].Path })
+	for _, link := range links {
+		if seen[link.Path] {
+			continue
+		}
+		if other, ok := selectedAs[link.realPath]; ok {
+			ff.skipLink(link.Path, slog.LevelDebug, "the link target is already selected as "+other)
+			continue
+		}
+		matches = append(matches, link.Path)
+		seen[link.Path] = true
+		selectedAs[link.realPath] = link.Path
 	}
+	sortPaths(matches)
 
+	return matches, errs.Err()
+}
+
+// MatchFiles applies the rules, the output exclusion and the filters to files
+// selected without a walk, such as the import closure of --entry. Each file is
+// matched by its path relative to the first root containing it, or by its name
+// when no root does. --hidden=false and --max-depth do not apply as nothing
+// is walked. The files kept are returned in their original order.
+func (ff *FileFinder) MatchFiles(files, roots []string) ([]string, error) {
+	var matches []string
+	for _, file := range files {
+		entry := walkEntry{path: file, realPath: file, rel: filepath.Base(file), input: true}
+		if rel, ok := rootRelative(file, roots); ok {
+			entry.rel = rel
+		}
+		if realPath, err := ff.GetRealPath(file); err == nil {
+			entry.realPath = realPath
+		}
+
+		include, err := ff.shouldIncludeFile(entry)
+		if err != nil {
+			return matches, err
+		}
+		if include {
+			matches = append(matches, file)
+		}
+	}
 	return matches, nil
 }
 
+// openRoot returns the directory to walk for an input path. Input paths that
+// are files or symlinks are handled right away and return false.
+func (ff *FileFinder) openRoot(basePath string, resultChan chan<- Result) (walkEntry, bool) {
+	// Convert to absolute path for consistent handling
+	absPath, err := filepath.Abs(basePath)
+	if err != nil {
+		resultChan <- Result{Path: basePath, Err: fmt.Errorf("error resolving path %q: %w", basePath, err)}
+		return walkEntry{}, false
+	}
+
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		// For broken symlinks and permission errors, log and continue
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			logEvent(slog.LevelWarn, EventWalkSkipped, absPath, err.Error())
+			return walkEntry{}, false
+		}
+		resultChan <- Result{Path: absPath, Err: fmt.Errorf("error walking path %q: %w", basePath, err)}
+		return walkEntry{}, false
+	}
+
+	root := walkEntry{path: absPath, realPath: absPath, input: true}
+	if realPath, err := ff.GetRealPath(absPath); err == nil {
+		root.realPath = realPath
+	}
+	if info.IsDir() {
+		return root, true
+	}
+
+	// A file given as input is matched by its name
+	root.rel = filepath.Base(absPath)
+	if err := ff.handleEntry(root, fs.FileInfoToDirEntry(info), resultChan); err != nil {
+		resultChan <- Result{Path: absPath, Err: fmt.Errorf("error walking path %q: %w", basePath, err)}
+	}
+	return walkEntry{}, false
+}
+
+// pruneDir reports whether every file below a directory is excluded by the
+// rules or lies outside the walk, in which case the directory is not walked
+func (ff *FileFinder) pruneDir(dir walkEntry) bool {
+	if reason := ff.walkLimit(dir.rel, true); reason != "" {
+		// Reported like filtered files so dry runs show the whole subtree was left out
+		logEvent(LevelTrace, EventDirPruned, dir.path, reason)
+		ff.recordFiltered(dir.path, reason)
+		return true
+	}
+
+	rule, prune := ff.rules.pruningRule(dir.rel)
+	if !prune {
+		return false
+	}
+	reason := "no include pattern can match below it"
+	if rule != nil {
+		reason = fmt.Sprintf("matched exclude pattern %q", rule.pattern)
+	}
+	// Recorded once for the directory, as its files are never visited
+	logEvent(LevelTrace, EventDirPruned, dir.path, reason)
+	ff.record(dir.path, FileStatusExcluded, reason)
+	return true
+}
+
+// sortPaths orders paths the way a sequential directory walk visits them,
+// with the contents of a directory before siblings that extend its name
+func sortPaths(paths []string) {
+	keys := make(map[string]string, len(paths))
+	for _, p := range paths {
+		keys[p] = strings.ReplaceAll(p, string(os.PathSeparator), "\x00")
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return keys[paths[i]] < keys[paths[j]]
+	})
+}
+
 // processSymlink handles the processing of symbolic links, including cycle detection
 // and pattern matching for the linked file.
-func (ff *FileFinder) processSymlink(path string, resultChan chan<- Result) error {
-	// Resolve the actual file path that the symlink points to
+func (ff *FileFinder) processSymlink(entry walkEntry, resultChan chan<- Result) error {
+	path, rel := entry.path, entry.rel
+
+	// Resolve the actual file path that the symlink points to. Broken links
+	// and link loops have no content to bundle.
 	realPath, err := ff.GetRealPath(path)
 	if err != nil {
-		// For broken symlinks, just check the symlink itself
-		normalizedPath := filepath.ToSlash(path)
-		include, err := ff.shouldIncludeFile(normalizedPath)
-		if err != nil {
-			return err
+		include, matchErr := ff.matchLink(entry)
+		if matchErr != nil {
+			return matchErr
 		}
 		if include {
-			resultChan <- Result{Path: path}
+			target, _ := os.Readlink(path)
+			ff.skipLink(path, slog.LevelWarn, "broken link to "+target)
 		}
 		return nil
 	}
 
-	// Use mutex to safely check and update seen paths
 	ff.mu.Lock()
-	seenBefore := ff.seenPaths[realPath]
-	ff.seenPaths[realPath] = true
 	ff.seenLinks[path] = true
 	ff.mu.Unlock()
 
-	if seenBefore {
-		// If we've seen the target before, still check if we should include the symlink
-		normalizedPath := filepath.ToSlash(path)
-		include, err := ff.shouldIncludeFile(normalizedPath)
-		if err != nil {
-			return err
-		}
-		if include {
-			resultChan <- Result{Path: path}
-		}
-		return nil
-	}
-
 	// Get info about the real file
 	info, err := os.Stat(realPath)
 	if err != nil {
 		return nil
 	}
 
-	// For directory symlinks, walk the directory
+	// For directory symlinks, walk the directory as if it were below the link.
+	// Each target directory is walked once, which also breaks link cycles.
 	if info.IsDir() {
+		ff.mu.Lock()
+		seenBefore := ff.seenPaths[realPath]
+		ff.seenPaths[realPath] = true
+		ff.mu.Unlock()
+
+		if seenBefore || ff.pruneDir(walkEntry{path: path, realPath: realPath, rel: rel}) {
+			return nil
+		}
 		return filepath.WalkDir(realPath, func(p string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
-			return ff.handleEntry(p, d, resultChan)
+			entry := walkEntry{path: p, realPath: p, rel: joinRelative(rel, relativePath(realPath, p))}
+			if d.IsDir() {
+				if p != realPath && ff.pruneDir(entry) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			return ff.handleEntry(entry, d, resultChan)
 		})
 	}
 
-	// For file symlinks, check the symlink path against patterns
-	normalizedPath := filepath.ToSlash(path)
-	include, err := ff.shouldIncludeFile(normalizedPath)
+	// For file symlinks, check the symlink path against patterns. Whether the
+	// target is selected under another path is known once the walk is done.
+	include, err := ff.shouldIncludeFile(entry)
 	if err != nil {
 		return err
 	}
 	if include {
-		resultChan <- Result{Path: path}
+		resultChan <- Result{Path: path, realPath: realPath, link: true}
 	}
 
 	return nil
@@ -171,19 +380,11 @@ func (ff *FileFinder) processSymlink(path string, resultChan chan<- Result) erro
 
 // processRegularFile handles the processing of regular (non-symlink) files,
 // including deduplication and pattern matching.
-func (ff *FileFinder) processRegularFile(path string, resultChan chan<- Result) error {
-	normalizedPath := filepath.ToSlash(path)
-
-	// Get real path for deduplication
-	realPath, err := ff.GetRealPath(path)
-	if err != nil {
-		realPath = path // If we can't resolve, use original path
-	}
-
-	// Check if we've seen this file before
+func (ff *FileFinder) processRegularFile(entry walkEntry, resultChan chan<- Result) error {
+	// Skip files already reached through another input root or a link
 	ff.mu.Lock()
-	seenBefore := ff.seenPaths[realPath]
-	ff.seenPaths[realPath] = true
+	seenBefore := ff.seenPaths[entry.realPath]
+	ff.seenPaths[entry.realPath] = true
 	ff.mu.Unlock()
 
 	if seenBefore {
@@ -191,85 +392,86 @@ func (ff *FileFinder) processRegularFile(path string, resultChan chan<- Result)
 	}
 
 	// Check if the file matches our patterns
-	include, err := ff.shouldIncludeFile(normalizedPath)
+	include, err := ff.shouldIncludeFile(entry)
 	if err != nil {
 		return err
 	}
 
 	if include {
-		resultChan <- Result{Path: path}
+		resultChan <- Result{Path: entry.path, realPath: entry.realPath}
 	}
 	return nil
 }
 
-// handleEntry processes a single filesystem entry, determining its type
-// and delegating to the appropriate handler.
-func (ff *FileFinder) handleEntry(path string, d fs.DirEntry, resultChan chan<- Result) error {
-	info, err := d.Info()
-	if err != nil {
-		return fmt.Errorf("error getting file info for %q: %w", path, err)
-	}
-
+// handleEntry processes a single file found while walking an input root,
+// determining its type and delegating to the appropriate handler.
+func (ff *FileFinder) handleEntry(entry walkEntry, d fs.DirEntry, resultChan chan<- Result) error {
 	// Check if it's a symlink
-	if info.Mode()&os.ModeSymlink != 0 {
-		if ff.followSymlinks {
-			// Process symlink
-			err := ff.processSymlink(path, resultChan)
+	if d.Type()&os.ModeSymlink != 0 {
+		switch ff.symlinks {
+		case SymlinkFollow:
+			if err := ff.processSymlink(entry, resultChan); err != nil {
+				return fmt.Errorf("error processing symlink %q: %w", entry.path, err)
+			}
+		case SymlinkRecord:
+			// The link itself is bundled, whatever it points to
+			include, err := ff.matchLink(entry)
 			if err != nil {
-				return fmt.Errorf("error processing symlink %q: %w", path, err)
+				return err
+			}
+			if include {
+				resultChan <- Result{Path: entry.path}
 			}
-		} else {
-			// Even if we don't follow symlinks, we should still check if the symlink itself matches
-			normalizedPath := filepath.ToSlash(path)
-			include, err := ff.shouldIncludeFile(normalizedPath)
+		default:
+			include, err := ff.matchLink(entry)
 			if err != nil {
 				return err
 			}
 			if include {
-				resultChan <- Result{Path: path}
+				ff.skipLink(entry.path, slog.LevelDebug, "links are not followed with --symlinks=skip")
 			}
 		}
 		return nil
 	}
 
-	// Skip directories as they're handled by WalkDir
+	// Skip directories as they're handled by the walker
 	if d.IsDir() {
 		return nil
 	}
 
-	return ff.processRegularFile(path, resultChan)
+	return ff.processRegularFile(entry, resultChan)
 }
 
-// worker processes paths from pathChan, walking directories and sending results to resultChan.
-// It's designed to run concurrently with other workers.
-func (ff *FileFinder) worker(pathChan <-chan string, resultChan chan<- Result, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	for basePath := range pathChan {
-		// Convert to absolute path for consistent handling
-		absPath, err := filepath.Abs(basePath)
+// walkSource matches the files of an input source against the patterns using
+// their paths relative to the source root. Links inside the source are not
+// followed.
+func (ff *FileFinder) walkSource(ctx context.Context, src *InputSource, resultChan chan<- Result) error {
+	return fs.WalkDir(src.FS, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			resultChan <- Result{Err: fmt.Errorf("error resolving path %q: %w", basePath, err)}
-			continue
+			return err
 		}
-
-		// Walk the directory tree
-		err = filepath.WalkDir(absPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				// For broken symlinks and permission errors, log and continue
-				if os.IsNotExist(err) || os.IsPermission(err) {
-					fmt.Fprintf(os.Stderr, "Warning: Skipping %s: %v\n", path, err)
-					return nil
-				}
-				return err
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := walkEntry{path: src.Path(path), rel: path}
+		if d.IsDir() {
+			if path != "." && ff.pruneDir(entry) {
+				return fs.SkipDir
 			}
-			return ff.handleEntry(path, d, resultChan)
-		})
-
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		include, err := ff.shouldIncludeFile(entry)
 		if err != nil {
-			resultChan <- Result{Err: fmt.Errorf("error walking path %q: %w", basePath, err)}
+			return err
 		}
-	}
+		if include {
+			resultChan <- Result{Path: entry.path}
+		}
+		return nil
+	})
 }
 
 // GetRealPath returns the real filesystem path for a file, resolving any symbolic links.
@@ -294,48 +496,243 @@ func (ff *FileFinder) IsSymlink(path string) (bool, error) {
 	return ff.seenLinks[path], nil
 }
 
-// matchPattern checks if a file matches a given pattern, handling both basename-only
-// and full path patterns appropriately.
-func (ff *FileFinder) matchPattern(pattern, path, basename string) (bool, error) {
-	// For patterns without path separators, match against basename only
-	if !strings.Contains(pattern, "/") {
-		return doublestar.Match(pattern, basename)
+// relativePath returns the slash-separated path of a file below an input
+// root. A root that is itself a file is matched by its name.
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return filepath.Base(path)
 	}
-	// For patterns with path separators, match against full path
-	return doublestar.Match(pattern, path)
+	return filepath.ToSlash(rel)
 }
 
-// shouldIncludeFile determines whether a file should be included in the results
-// based on the configured include and exclude patterns.
-func (ff *FileFinder) shouldIncludeFile(path string) (bool, error) {
-	basename := filepath.Base(path)
-
-	// Check exclude patterns first - if any match, exclude the file
-	for _, pattern := range ff.excludes {
-		matched, err := ff.matchPattern(pattern, path, basename)
+// rootRelative returns the slash-separated path of a file below the first of
+// roots containing it, or the path inside the archive or git revision it was
+// read from. It returns false when no root contains the file.
+func rootRelative(file string, roots []string) (string, bool) {
+	if _, inner, ok := splitSourcePath(file); ok {
+		return inner, true
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
 		if err != nil {
-			return false, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+			continue
+		}
+		rel, err := filepath.Rel(absRoot, absFile)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel), true
 		}
-		if matched {
+	}
+	return "", false
+}
+
+// joinRelative appends a relative path found below a followed directory link
+// to the relative path of the link
+func joinRelative(linkRel, rel string) string {
+	if rel == "" || rel == "." {
+		return linkRel
+	}
+	return linkRel + "/" + rel
+}
+
+// ExcludedFiles returns the files that were rejected by an exclude pattern or
+// a filter, and the links that were left out.
+func (ff *FileFinder) ExcludedFiles() []ReportEntry {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	return append([]ReportEntry(nil), ff.excluded...)
+}
+
+// recordExcluded remembers that a file was rejected by the given exclude pattern.
+func (ff *FileFinder) recordExcluded(path, pattern string) {
+	entry := ff.record(path, FileStatusExcluded, fmt.Sprintf("matched exclude pattern %q", pattern))
+	logEvent(slog.LevelDebug, EventFileExcluded, entry.Path, entry.Reason)
+}
+
+// recordFiltered remembers that a file matching the patterns was rejected by
+// a filter. Filtered files are logged by LogFiltered.
+func (ff *FileFinder) recordFiltered(path, reason string) {
+	ff.record(path, FileStatusFiltered, reason)
+}
+
+// skipLink remembers that a link matching the patterns was left out
+func (ff *FileFinder) skipLink(path string, level slog.Level, reason string) {
+	entry := ff.record(path, FileStatusSkippedLink, reason)
+	logEvent(level, EventFileSkipped, entry.Path, entry.Reason)
+}
+
+// record adds a report entry for a file left out by the finder
+func (ff *FileFinder) record(path string, status FileStatus, reason string) ReportEntry {
+	entry := ReportEntry{
+		Path:   path,
+		Status: status,
+		Reason: reason,
+	}
+	info, err := os.Lstat(entry.Path)
+	if src, inner, ok := splitSourcePath(entry.Path); ok {
+		info, err = fs.Stat(src.FS, inner)
+	}
+	if err == nil && !info.IsDir() {
+		entry.Size = info.Size()
+	}
+
+	ff.mu.Lock()
+	ff.excluded = append(ff.excluded, entry)
+	ff.mu.Unlock()
+	return entry
+}
+
+// shouldIncludeFile determines whether a file should be included in the results
+// by evaluating the rules against its slash-separated path relative to the
+// input root, where the last matching rule decides, and then the filters.
+// A file left out is logged and recorded under its path.
+func (ff *FileFinder) shouldIncludeFile(entry walkEntry) (bool, error) {
+	if ok, err := ff.matchRules(entry); err != nil || !ok {
+		return false, err
+	}
+
+	if reason := ff.outputReason(entry); reason != "" {
+		entry := ff.record(entry.path, FileStatusSkippedOutput, reason)
+		logEvent(slog.LevelInfo, EventFileSkipped, entry.Path, entry.Reason)
+		return false, nil
+	}
+
+	reason, err := ff.filterReason(entry)
+	if err != nil {
+		return false, err
+	}
+	if reason != "" {
+		ff.recordFiltered(entry.path, reason)
+		return false, nil
+	}
+	return true, nil
+}
+
+// outputReason returns why a file is left out as the output of the run or a
+// bundle of an earlier run, or "" when it is neither. Only files with the
+// extension of an output format are read to look for the bundle marker.
+func (ff *FileFinder) outputReason(entry walkEntry) string {
+	if ff.outputs[entry.path] || ff.outputs[entry.realPath] {
+		return "it is the output file of this run"
+	}
+	if _, _, ok := splitSourcePath(entry.path); ok {
+		return ""
+	}
+	if bundleExtensions[strings.ToLower(filepath.Ext(entry.path))] && isBundle(entry.path) {
+		return "it is a bundle written by filefusion"
+	}
+	return ""
+}
+
+// matchRules reports whether the rules select a file, recording it when an
+// exclude pattern rejects it
+func (ff *FileFinder) matchRules(entry walkEntry) (bool, error) {
+	rule, err := ff.rules.lastMatch(entry.rel)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case rule != nil && rule.exclude:
+		ff.recordExcluded(entry.path, rule.pattern)
+		return false, nil
+	case rule == nil && !ff.rules.includeAll:
+		logEvent(LevelTrace, EventFileExcluded, entry.path, "did not match any include pattern")
+		return false, nil
+	}
+	return true, nil
+}
+
+// matchLink reports whether a link that is not followed is selected by its
+// own name. The rules and walk limits apply, the filters do not as they
+// look at the target.
+func (ff *FileFinder) matchLink(entry walkEntry) (bool, error) {
+	if ok, err := ff.matchRules(entry); err != nil || !ok {
+		return false, err
+	}
+	if !entry.input {
+		if reason := ff.walkLimit(entry.rel, false); reason != "" {
+			ff.recordFiltered(entry.path, reason)
 			return false, nil
 		}
 	}
+	return true, nil
+}
+
+// filterReason returns why a file matching the patterns is rejected by the
+// filters, or an empty string when it is selected. Files given as input paths
+// are not subject to --hidden=false and --max-depth.
+func (ff *FileFinder) filterReason(entry walkEntry) (string, error) {
+	if !entry.input {
+		if reason := ff.walkLimit(entry.rel, false); reason != "" {
+			return reason, nil
+		}
+	}
+	if ff.filter == nil {
+		return "", nil
+	}
 
-	// If no include patterns specified, include all files not explicitly excluded
-	if len(ff.includes) == 0 {
-		return true, nil
+	ok, reason, err := ff.filter.Match(entry.path)
+	if err != nil {
+		return "", fmt.Errorf("error filtering %q: %w", entry.path, err)
 	}
+	if ok {
+		return "", nil
+	}
+	return reason, nil
+}
 
-	// Check if file matches any include pattern
-	for _, pattern := range ff.includes {
-		matched, err := ff.matchPattern(pattern, path, basename)
-		if err != nil {
-			return false, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+// walkLimit returns why an entry below an input root is outside the walk:
+// it is in a version control directory, it is hidden and --hidden=false was
+// given, or its files lie deeper than --max-depth. It returns an empty string
+// for entries within the walk.
+func (ff *FileFinder) walkLimit(rel string, dir bool) string {
+	if name := vcsDir(rel); name != "" {
+		return fmt.Sprintf("in version control directory %s", name)
+	}
+	if !ff.hidden && isHidden(rel) {
+		if dir {
+			return "hidden directory, rejected by --hidden=false"
+		}
+		return "hidden file, rejected by --hidden=false"
+	}
+	if ff.maxDepth > 0 {
+		depth := strings.Count(rel, "/") + 1
+		if dir {
+			depth++ // Depth of the files in the directory
 		}
-		if matched {
-			return true, nil
+		if depth > ff.maxDepth {
+			return fmt.Sprintf("rejected by --max-depth %d", ff.maxDepth)
 		}
 	}
+	return ""
+}
+
+// vcsDirs are the metadata directories of version control systems, which
+// are never walked
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".bzr": true}
 
-	return false, nil
+// vcsDir returns the version control directory in a slash-separated path, or
+// an empty string when there is none
+func vcsDir(rel string) string {
+	for _, name := range strings.Split(rel, "/") {
+		if vcsDirs[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// isHidden reports whether a slash-separated path has a component starting
+// with a dot
+func isHidden(rel string) bool {
+	for _, name := range strings.Split(rel, "/") {
+		if len(name) > 1 && name[0] == '.' && name != ".." {
+			return true
+		}
+	}
+	return false
 }
@@ -3,8 +3,10 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestNewFileFinder(t *testing.T) {
@@ -21,8 +23,8 @@ func TestNewFileFinder(t *testing.T) {
 	if len(ff.excludes) != 1 || ff.excludes[0] != "temp*" {
 		t.Errorf("Expected excludes to be [temp*], got %v", ff.excludes)
 	}
-	if !ff.followSymlinks {
-		t.Error("Expected followSymlinks to be true")
+	if ff.symlinks != SymlinkFollow {
+		t.Errorf("Expected symlinks to be followed, got %q", ff.symlinks)
 	}
 }
 
@@ -122,21 +124,21 @@ func TestFindMatchingFiles(t *testing.T) {
 			includes:      []string{"**.txt"},
 			excludes:      nil,
 			followSymlink: false,
-			expectedCount: 4, // file1.txt, temp.txt, subdir/file3.txt, link1.txt
+			expectedCount: 3, // file1.txt, temp.txt, subdir/file3.txt, link1.txt is skipped
 		},
 		{
 			name:          "Match txt files excluding temp",
 			includes:      []string{"**.txt"},
 			excludes:      []string{"**/temp*"},
 			followSymlink: false,
-			expectedCount: 3, // file1.txt, subdir/file3.txt, link1.txt
+			expectedCount: 2, // file1.txt, subdir/file3.txt
 		},
 		{
 			name:          "Match with symlinks",
 			includes:      []string{"**.txt"},
 			excludes:      []string{"**/temp*"},
 			followSymlink: true,
-			expectedCount: 3, // file1.txt, subdir/file3.txt, link1.txt
+			expectedCount: 2, // file1.txt, subdir/file3.txt, link1.txt duplicates file1.txt
 		},
 		{
 			name:          "Match only log files",
@@ -196,7 +198,7 @@ func TestProcessSymlink(t *testing.T) {
 	resultChan := make(chan Result)
 
 	go func() {
-		err := ff.processSymlink(filepath.Join(tempDir, "link1.txt"), resultChan)
+		err := ff.processSymlink(walkEntry{path: filepath.Join(tempDir, "link1.txt"), rel: "link1.txt"}, resultChan)
 		if err != nil {
 			t.Errorf("Unexpected error processing symlink: %v", err)
 		}
@@ -253,12 +255,76 @@ func TestShouldIncludeFile(t *testing.T) {
 			path:     "path/to/test/file.txt",
 			expected: true,
 		},
+		{
+			name:     "Path pattern is relative to the root",
+			includes: []string{"src/*.go"},
+			path:     "src/main.go",
+			expected: true,
+		},
+		{
+			name:     "Path pattern does not match deeper directories",
+			includes: []string{"src/*.go"},
+			path:     "lib/src/main.go",
+			expected: false,
+		},
+		{
+			name:     "Leading slash anchors a name to the root",
+			includes: []string{"/main.go"},
+			path:     "cmd/main.go",
+			expected: false,
+		},
+		{
+			name:     "Anchored name at the root",
+			includes: []string{"/main.go"},
+			path:     "main.go",
+			expected: true,
+		},
+		{
+			name:     "Trailing slash excludes directories at any depth",
+			includes: []string{"*.go"},
+			excludes: []string{"vendor/"},
+			path:     "third_party/vendor/lib/a.go",
+			expected: false,
+		},
+		{
+			name:     "Anchored directory",
+			includes: []string{"*.go"},
+			excludes: []string{"/build/"},
+			path:     "src/build/a.go",
+			expected: true,
+		},
+		{
+			name:     "Negated exclude re-includes",
+			includes: []string{"*.go"},
+			excludes: []string{"*_test.go", "!helper_test.go"},
+			path:     "pkg/helper_test.go",
+			expected: true,
+		},
+		{
+			name:     "Last match wins",
+			includes: []string{"*.go"},
+			excludes: []string{"!main_test.go", "*_test.go"},
+			path:     "main_test.go",
+			expected: false,
+		},
+		{
+			name:     "Negated include",
+			includes: []string{"*.go", "!*_test.go"},
+			path:     "main_test.go",
+			expected: false,
+		},
+		{
+			name:     "Only negated includes keep other files",
+			includes: []string{"!*.log"},
+			path:     "main.go",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ff := NewFileFinder(tt.includes, tt.excludes, false)
-			result, err := ff.shouldIncludeFile(tt.path)
+			result, err := ff.shouldIncludeFile(walkEntry{path: tt.path, rel: tt.path})
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 				return
@@ -360,9 +426,314 @@ func TestConcurrentFinding(t *testing.T) {
 		seen[realPath] = true
 	}
 
-	expectedCount := 3 // file1.txt, subdir/file3.txt, and link1.txt
+	expectedCount := 2 // file1.txt and subdir/file3.txt, link1.txt duplicates file1.txt
 	if len(matches) != expectedCount {
 		t.Errorf("Expected %d matches, got %d: %v", expectedCount, len(matches), matches)
 		t.Logf("Unique paths found: %d", len(seen))
 	}
 }
+
+func TestFindMatchingFilesRelativePatterns(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"main.go",
+		"src/a.go",
+		"src/a_test.go",
+		"src/gen/b.go",
+		"build/out.go",
+		"lib/build/c.go",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		want     []string
+	}{
+		{
+			name:     "path pattern",
+			includes: []string{"src/*.go"},
+			want:     []string{"src/a.go", "src/a_test.go"},
+		},
+		{
+			name:     "anchored exclusion",
+			includes: []string{"*.go"},
+			excludes: []string{"/build/**"},
+			want:     []string{"lib/build/c.go", "main.go", "src/a.go", "src/a_test.go", "src/gen/b.go"},
+		},
+		{
+			name:     "re-include after exclusion",
+			includes: []string{"*.go"},
+			excludes: []string{"src/**", "!src/gen/"},
+			want:     []string{"build/out.go", "lib/build/c.go", "main.go", "src/gen/b.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ff := NewFileFinder(tt.includes, tt.excludes, false)
+			matches, err := ff.FindMatchingFiles([]string{root})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, match := range matches {
+				rel, err := filepath.Rel(root, match)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindMatchingFilesWalkLimits(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"main.go",
+		".env.go",
+		"a/b.go",
+		"a/c/d.go",
+		".github/workflows/ci.go",
+		".git/hooks/pre-commit.go",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		maxDepth   int
+		skipHidden bool
+		inputs     []string
+		want       []string
+		filtered   map[string]string // Reasons of the filtered entries by relative path
+	}{
+		{
+			name: "hidden files included by default",
+			want: []string{".env.go", ".github/workflows/ci.go", "a/b.go", "a/c/d.go", "main.go"},
+			filtered: map[string]string{
+				".git": "in version control directory .git",
+			},
+		},
+		{
+			name:       "hidden files skipped",
+			skipHidden: true,
+			want:       []string{"a/b.go", "a/c/d.go", "main.go"},
+			filtered: map[string]string{
+				".env.go": "hidden file, rejected by --hidden=false",
+				".git":    "in version control directory .git",
+				".github": "hidden directory, rejected by --hidden=false",
+			},
+		},
+		{
+			name:     "max depth",
+			maxDepth: 2,
+			want:     []string{".env.go", "a/b.go", "main.go"},
+			filtered: map[string]string{
+				".git":              "in version control directory .git",
+				".github/workflows": "rejected by --max-depth 2",
+				"a/c":               "rejected by --max-depth 2",
+			},
+		},
+		{
+			name:       "hidden input file",
+			skipHidden: true,
+			inputs:     []string{".env.go"},
+			want:       []string{".env.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := []string{root}
+			if tt.inputs != nil {
+				inputs = nil
+				for _, input := range tt.inputs {
+					inputs = append(inputs, filepath.Join(root, input))
+				}
+			}
+
+			ff := NewFileFinder([]string{"*.go"}, nil, false)
+			ff.SetMaxDepth(tt.maxDepth)
+			ff.SetHidden(!tt.skipHidden)
+			matches, err := ff.FindMatchingFiles(inputs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, match := range matches {
+				rel, _ := filepath.Rel(root, match)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+
+			filtered := make(map[string]string)
+			for _, entry := range ff.ExcludedFiles() {
+				if entry.Status != FileStatusFiltered {
+					t.Errorf("unexpected %s entry for %s", entry.Status, entry.Path)
+				}
+				rel, _ := filepath.Rel(root, entry.Path)
+				filtered[filepath.ToSlash(rel)] = entry.Reason
+			}
+			if len(filtered) == 0 {
+				filtered = nil
+			}
+			if !reflect.DeepEqual(filtered, tt.filtered) {
+				t.Errorf("Filtered %v, want %v", filtered, tt.filtered)
+			}
+		})
+	}
+}
+
+func TestFindMatchingFilesFilter(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"todo.go":  "package x // TODO: split",
+		"plain.go": "package x",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filter, err := ParseFilter([]FilterTerm{{Name: "contains", Value: "TODO"}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ff := NewFileFinder([]string{"*.go"}, nil, false)
+	ff.SetFilter(filter)
+	matches, err := ff.FindMatchingFiles([]string{root})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := []string{filepath.Join(root, "todo.go")}; !reflect.DeepEqual(matches, want) {
+		t.Errorf("Expected %v, got %v", want, matches)
+	}
+	excluded := ff.ExcludedFiles()
+	if len(excluded) != 1 || excluded[0].Path != filepath.Join(root, "plain.go") ||
+		excluded[0].Status != FileStatusFiltered || excluded[0].Reason != "rejected by --contains TODO" {
+		t.Errorf("ExcludedFiles() = %+v, want plain.go rejected by --contains TODO", excluded)
+	}
+}
+
+func TestFindMatchingFilesSkipsOutputs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"config.json": "{\"name\": \"app\"}",
+		"old.json":    "{\n  \"generator\": \"filefusion\",\n  \"documents\": []\n}",
+		"old.yaml":    "generator: filefusion\ndocuments: []\n",
+		"out.json":    "{\"name\": \"not a bundle\"}",
+		"notes.txt":   "generator: filefusion",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ff := NewFileFinder([]string{"*.json", "*.yaml", "*.txt"}, nil, false)
+	ff.SetOutputs([]string{filepath.Join(root, "out.json")})
+	matches, err := ff.FindMatchingFiles([]string{root})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only files with the extension of an output format are read for the marker
+	want := []string{filepath.Join(root, "config.json"), filepath.Join(root, "notes.txt")}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Expected %v, got %v", want, matches)
+	}
+
+	skipped := make(map[string]string)
+	for _, entry := range ff.ExcludedFiles() {
+		if entry.Status == FileStatusSkippedOutput {
+			skipped[filepath.Base(entry.Path)] = entry.Reason
+		}
+	}
+	wantSkipped := map[string]string{
+		"old.json": "it is a bundle written by filefusion",
+		"old.yaml": "it is a bundle written by filefusion",
+		"out.json": "it is the output file of this run",
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped outputs = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestMatchFiles(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	files := map[string]string{
+		filepath.Join(root, "main.go"):                "package main",
+		filepath.Join(root, "gen", "api.go"):          "package gen",
+		filepath.Join(root, "lib", "util.go"):         "package lib",
+		filepath.Join(root, "lib", "old.json"):        "{\n  \"generator\": \"filefusion\",\n  \"documents\": []\n}",
+		filepath.Join(outside, "shared", "shared.go"): "package shared",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Anchored patterns match below the root, files outside it by their name
+	ff := NewFileFinder(nil, []string{"/gen/**", "shared.go"}, false)
+	input := []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "gen", "api.go"),
+		filepath.Join(root, "lib", "util.go"),
+		filepath.Join(root, "lib", "old.json"),
+		filepath.Join(outside, "shared", "shared.go"),
+	}
+	matches, err := ff.MatchFiles(input, []string{root})
+	if err != nil {
+		t.Fatalf("MatchFiles() error = %v", err)
+	}
+
+	want := []string{filepath.Join(root, "main.go"), filepath.Join(root, "lib", "util.go")}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("MatchFiles() = %v, want %v", matches, want)
+	}
+
+	statuses := make(map[string]FileStatus)
+	for _, entry := range ff.ExcludedFiles() {
+		statuses[filepath.Base(entry.Path)] = entry.Status
+	}
+	wantStatuses := map[string]FileStatus{
+		"api.go":    FileStatusExcluded,
+		"shared.go": FileStatusExcluded,
+		"old.json":  FileStatusSkippedOutput,
+	}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("left out = %v, want %v", statuses, wantStatuses)
+	}
+}
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// modelineScanLines is how many lines at the start and end of a file are
+// searched for an editor modeline
+const modelineScanLines = 5
+
+// LanguageMapping assigns a language to the files matching a pattern, as given
+// with --lang-map '*.inc=php'
+type LanguageMapping struct {
+	Pattern  string
+	Language cleaner.Language
+}
+
+// LanguageDetector determines the language of a file. User mappings take
+// precedence, followed by editor modelines, the file name or extension and
+// finally the interpreter named in a shebang line.
+type LanguageDetector struct {
+	Mappings []LanguageMapping
+	Roots    []string // Input paths that mapping patterns are matched relative to
+}
+
+// extensionLanguages maps lower-case file extensions to languages
+var extensionLanguages = map[string]cleaner.Language{
+	".go":    cleaner.LangGo,
+	".java":  cleaner.LangJava,
+	".py":    cleaner.LangPython,
+	".pyi":   cleaner.LangPython,
+	".pyw":   cleaner.LangPython,
+	".js":    cleaner.LangJavaScript,
+	".jsx":   cleaner.LangJavaScript,
+	".mjs":   cleaner.LangJavaScript,
+	".cjs":   cleaner.LangJavaScript,
+	".ts":    cleaner.LangTypeScript,
+	".mts":   cleaner.LangTypeScript,
+	".cts":   cleaner.LangTypeScript,
+	".tsx":   cleaner.LangTSX,
+	".html":  cleaner.LangHTML,
+	".htm":   cleaner.LangHTML,
+	".css":   cleaner.LangCSS,
+	".c":     cleaner.LangC,
+	".h":     cleaner.LangC,
+	".cpp":   cleaner.LangCPP,
+	".cc":    cleaner.LangCPP,
+	".cxx":   cleaner.LangCPP,
+	".c++":   cleaner.LangCPP,
+	".hpp":   cleaner.LangCPP,
+	".hh":    cleaner.LangCPP,
+	".hxx":   cleaner.LangCPP,
+	".h++":   cleaner.LangCPP,
+	".cs":    cleaner.LangCSharp,
+	".php":   cleaner.LangPHP,
+	".rb":    cleaner.LangRuby,
+	".sh":    cleaner.LangBash,
+	".bash":  cleaner.LangBash,
+	".zsh":   cleaner.LangBash,
+	".ksh":   cleaner.LangBash,
+	".swift": cleaner.LangSwift,
+	".kt":    cleaner.LangKotlin,
+	".kts":   cleaner.LangKotlin,
+	".sql":   cleaner.LangSQL,
+	".rs":    cleaner.LangRust,
+	".lua":   cleaner.LangLua,
+	".scala": cleaner.LangScala,
+	".sc":    cleaner.LangScala,
+	".ex":    cleaner.LangElixir,
+	".exs":   cleaner.LangElixir,
+	".ml":    cleaner.LangOCaml,
+	".yaml":  cleaner.LangYAML,
+	".yml":   cleaner.LangYAML,
+}
+
+// languageAliases maps the names used in modelines, shebangs and --lang-map
+// to languages. Supported language identifiers are accepted as well.
+var languageAliases = map[string]cleaner.Language{
+	"py":              cleaner.LangPython,
+	"python2":         cleaner.LangPython,
+	"python3":         cleaner.LangPython,
+	"js":              cleaner.LangJavaScript,
+	"js2":             cleaner.LangJavaScript,
+	"jsx":             cleaner.LangJavaScript,
+	"javascriptreact": cleaner.LangJavaScript,
+	"node":            cleaner.LangJavaScript,
+	"nodejs":          cleaner.LangJavaScript,
+	"deno":            cleaner.LangJavaScript,
+	"bun":             cleaner.LangJavaScript,
+	"ts":              cleaner.LangTypeScript,
+	"ts-node":         cleaner.LangTypeScript,
+	"typescriptreact": cleaner.LangTSX,
+	"c++":             cleaner.LangCPP,
+	"cxx":             cleaner.LangCPP,
+	"cs":              cleaner.LangCSharp,
+	"c#":              cleaner.LangCSharp,
+	"rb":              cleaner.LangRuby,
+	"sh":              cleaner.LangBash,
+	"zsh":             cleaner.LangBash,
+	"ksh":             cleaner.LangBash,
+	"dash":            cleaner.LangBash,
+	"shell-script":    cleaner.LangBash,
+	"kt":              cleaner.LangKotlin,
+	"rs":              cleaner.LangRust,
+	"luajit":          cleaner.LangLua,
+	"elixirs":         cleaner.LangElixir,
+	"tuareg":          cleaner.LangOCaml,
+	"yml":             cleaner.LangYAML,
+}
+
+var (
+	// vim: set ft=python: / vi: filetype=sh / ex: syntax=ruby
+	vimModeline = regexp.MustCompile(`(?:^|\s)(?:vim?|ex):.*?\b(?:ft|filetype|syntax)=([\w+#.-]+)`)
+	// -*- mode: python -*- or the short form -*- python -*-
+	emacsModeline = regexp.MustCompile(`-\*-\s*(.*?)\s*-\*-`)
+	// Trailing version numbers of interpreters, e.g. python3.12 or lua5.4
+	interpreterVersion = regexp.MustCompile(`[\d.]+$`)
+	// Constructs that only appear in C++ headers, including standard headers
+	// without an extension such as <vector>
+	cppHeaderSyntax = regexp.MustCompile(`(?m)^\s*(?:namespace\b|template\s*<|class\s+\w+\s*[:{;]|using\s+namespace\b|(?:public|private|protected)\s*:|#\s*include\s*<\w+>)|\bstd::`)
+)
+
+// ParseLanguageName resolves a language identifier or a common alias such as
+// "py", "c++" or "sh" to a supported language
+func ParseLanguageName(name string) (cleaner.Language, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if lang, ok := languageAliases[name]; ok {
+		return lang, true
+	}
+	for _, lang := range cleaner.GetSupportedLanguages() {
+		if string(lang) == name {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// ParseLanguageMap parses --lang-map entries of the form "pattern=language".
+// Entries may also be comma-separated within a single value.
+func ParseLanguageMap(entries []string) ([]LanguageMapping, error) {
+	var mappings []LanguageMapping
+	for _, entry := range entries {
+		for _, item := range strings.Split(entry, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			pattern, name, ok := strings.Cut(item, "=")
+			pattern = strings.TrimSpace(pattern)
+			if !ok || pattern == "" {
+				return nil, fmt.Errorf("invalid language mapping %q: expected pattern=language", item)
+			}
+			if !doublestar.ValidatePattern(pattern) {
+				return nil, fmt.Errorf("invalid language mapping %q: bad pattern", item)
+			}
+			lang, ok := ParseLanguageName(name)
+			if !ok {
+				return nil, fmt.Errorf("invalid language mapping %q: unsupported language %q", item, strings.TrimSpace(name))
+			}
+			mappings = append(mappings, LanguageMapping{Pattern: pattern, Language: lang})
+		}
+	}
+	return mappings, nil
+}
+
+// Detect returns the language of the file at path, or an empty language when
+// it is not recognized. The content is only consulted for modelines, shebangs
+// and to tell C++ headers from C headers, and may be nil.
+func (d *LanguageDetector) Detect(path string, content []byte) cleaner.Language {
+	lang, _ := d.detect(path, content)
+	return lang
+}
+
+// detect returns the language of a file and what it was detected from
+func (d *LanguageDetector) detect(path string, content []byte) (cleaner.Language, string) {
+	if d != nil {
+		if lang := d.mapped(path); lang != "" {
+			return lang, "--lang-map"
+		}
+	}
+	if lang := modelineLanguage(content); lang != "" {
+		return lang, "modeline"
+	}
+	if lang := languageForPath(path); lang != "" {
+		// Headers are shared by C and C++, so look for C++ syntax
+		if lang == cleaner.LangC && strings.EqualFold(filepath.Ext(path), ".h") && cppHeaderSyntax.Match(content) {
+			return cleaner.LangCPP, "C++ syntax in a header"
+		}
+		return lang, "file name"
+	}
+	if lang := shebangLanguage(content); lang != "" {
+		return lang, "shebang"
+	}
+	return "", ""
+}
+
+// mapped returns the language of the last user mapping matching path, so later
+// mappings override earlier ones. Patterns are read like --pattern: without a
+// slash they match the file name, otherwise the path relative to the input
+// root containing the file, and a leading "/" anchors them to that root.
+// Files outside every root are matched by their path as given.
+func (d *LanguageDetector) mapped(path string) cleaner.Language {
+	if len(d.Mappings) == 0 {
+		return ""
+	}
+	rel, ok := rootRelative(path, d.Roots)
+	if !ok {
+		rel = filepath.ToSlash(filepath.Clean(path))
+	}
+
+	for i := len(d.Mappings) - 1; i >= 0; i-- {
+		mapping := d.Mappings[i]
+		if matched, err := newPatternRule(mapping.Pattern, false).match(rel); err == nil && matched {
+			return mapping.Language
+		}
+	}
+	return ""
+}
+
+// languageForPath maps a file name or extension to the cleaner language
+func languageForPath(path string) cleaner.Language {
+	return extensionLanguages[strings.ToLower(filepath.Ext(path))]
+}
+
+// shebangLanguage returns the language of the interpreter named on a "#!"
+// first line, such as "#!/usr/bin/env python3" or "#!/bin/sh -e"
+func shebangLanguage(content []byte) cleaner.Language {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return ""
+	}
+
+	line, _, _ := bytes.Cut(content[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// Skip env options such as -S and variable assignments
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			interpreter = filepath.Base(field)
+			break
+		}
+	}
+
+	if lang, ok := ParseLanguageName(interpreter); ok {
+		return lang
+	}
+	if lang, ok := ParseLanguageName(interpreterVersion.ReplaceAllString(interpreter, "")); ok {
+		return lang
+	}
+	return ""
+}
+
+// modelineLanguage returns the language named by a vim or emacs modeline in
+// the first or last few lines of the content
+func modelineLanguage(content []byte) cleaner.Language {
+	if len(content) == 0 {
+		return ""
+	}
+
+	lines := bytes.Split(content, []byte("\n"))
+	candidates := lines
+	if len(lines) > 2*modelineScanLines {
+		candidates = append(append([][]byte{}, lines[:modelineScanLines]...), lines[len(lines)-modelineScanLines:]...)
+	}
+
+	for i, line := range candidates {
+		if match := vimModeline.FindSubmatch(line); match != nil {
+			if lang, ok := ParseLanguageName(string(match[1])); ok {
+				return lang
+			}
+		}
+		// Emacs only reads the first line, or the second after a shebang
+		if i < 2 {
+			if lang := emacsMode(line); lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// emacsMode parses "-*- mode: python; coding: utf-8 -*-" and "-*- python -*-"
+func emacsMode(line []byte) cleaner.Language {
+	match := emacsModeline.FindSubmatch(line)
+	if match == nil {
+		return ""
+	}
+
+	settings := string(match[1])
+	if !strings.Contains(settings, ":") {
+		lang, _ := ParseLanguageName(strings.TrimSuffix(settings, "-mode"))
+		return lang
+	}
+	for _, setting := range strings.Split(settings, ";") {
+		key, value, ok := strings.Cut(setting, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "mode") {
+			lang, _ := ParseLanguageName(strings.TrimSuffix(strings.TrimSpace(value), "-mode"))
+			return lang
+		}
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+func TestLanguageDetector(t *testing.T) {
+	detector := &LanguageDetector{
+		Mappings: []LanguageMapping{
+			{Pattern: "*.inc", Language: cleaner.LangPHP},
+			{Pattern: "*.h", Language: cleaner.LangGo}, // overridden below
+			{Pattern: "*.h", Language: cleaner.LangCSharp},
+			{Pattern: "legacy/**/*.h", Language: cleaner.LangCPP},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected cleaner.Language
+	}{
+		{"extension", "src/app.tsx", "", cleaner.LangTSX},
+		{"user mapping", "lib/db.inc", "", cleaner.LangPHP},
+		{"last mapping wins", "include/util.h", "", cleaner.LangCSharp},
+		{"path mapping", "legacy/x/util.h", "", cleaner.LangCPP},
+		{"mapping beats modeline", "lib/db.inc", "# vim: ft=python\n", cleaner.LangPHP},
+		{"env shebang", "bin/tool", "#!/usr/bin/env python3\nprint(1)\n", cleaner.LangPython},
+		{"env shebang with options", "bin/tool", "#!/usr/bin/env -S node --harmony\n", cleaner.LangJavaScript},
+		{"direct shebang", "bin/run", "#!/bin/sh -e\necho hi\n", cleaner.LangBash},
+		{"versioned interpreter", "bin/run", "#!/usr/local/bin/python3.12\n", cleaner.LangPython},
+		{"unknown interpreter", "bin/run", "#!/usr/bin/awk -f\n", ""},
+		{"extension beats shebang", "run.rb", "#!/usr/bin/env python\n", cleaner.LangRuby},
+		{"vim modeline", "config/build", "x = 1\n# vim: set ft=python:\n", cleaner.LangPython},
+		{"vim modeline overrides extension", "notes.txt.inc2", "// vi: filetype=cpp\n", cleaner.LangCPP},
+		{"emacs modeline", "script", "#!/bin/false\n# -*- mode: ruby; coding: utf-8 -*-\n", cleaner.LangRuby},
+		{"emacs short modeline", "script", "// -*- c++ -*-\n", cleaner.LangCPP},
+		{"emacs modeline after second line", "script", "a\nb\n# -*- mode: ruby -*-\n", ""},
+		{"unknown", "README", "plain text\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.Detect(tt.path, []byte(tt.content)); got != tt.expected {
+				t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+
+	// A nil detector still detects by name and content
+	var nilDetector *LanguageDetector
+	if got := nilDetector.Detect("main.go", nil); got != cleaner.LangGo {
+		t.Errorf("Expected go for nil detector, got %q", got)
+	}
+}
+
+func TestLanguageMappingRoots(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	detector := &LanguageDetector{
+		Mappings: []LanguageMapping{
+			{Pattern: "src/*.inc", Language: cleaner.LangPHP},
+			{Pattern: "/scripts/*", Language: cleaner.LangBash},
+			{Pattern: "vendor/", Language: cleaner.LangC},
+		},
+		Roots: []string{root},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected cleaner.Language
+	}{
+		{"path below the root", filepath.Join(root, "src", "db.inc"), cleaner.LangPHP},
+		{"path deeper than the pattern", filepath.Join(root, "lib", "src", "db.inc"), ""},
+		{"anchored pattern", filepath.Join(root, "scripts", "deploy"), cleaner.LangBash},
+		{"anchored pattern below the root", filepath.Join(root, "tools", "scripts", "deploy"), ""},
+		{"directory pattern at any depth", filepath.Join(root, "third_party", "vendor", "x.inc"), cleaner.LangC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.Detect(tt.path, nil); got != tt.expected {
+				t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeaderLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected cleaner.Language
+	}{
+		{"c header", "#include <stdio.h>\nstruct point { int x; };\n", cleaner.LangC},
+		{"empty header", "", cleaner.LangC},
+		{"class", "#pragma once\nclass Point {\n public:\n  int x;\n};\n", cleaner.LangCPP},
+		{"namespace", "namespace util {\nint f();\n}\n", cleaner.LangCPP},
+		{"standard include", "#include <vector>\n", cleaner.LangCPP},
+		{"std qualifier", "typedef std::string name_t;\n", cleaner.LangCPP},
+		{"modeline", "// -*- mode: c++ -*-\nint f();\n", cleaner.LangCPP},
+	}
+
+	detector := &LanguageDetector{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.Detect("include/util.h", []byte(tt.content)); got != tt.expected {
+				t.Errorf("Detect() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelineScanWindow(t *testing.T) {
+	lines := "# vim: ft=ruby\n"
+	for i := 0; i < 20; i++ {
+		lines += "x = 1\n"
+	}
+	if got := modelineLanguage([]byte(lines)); got != cleaner.LangRuby {
+		t.Errorf("Expected modeline on first line to be found, got %q", got)
+	}
+
+	middle := ""
+	for i := 0; i < 10; i++ {
+		middle += "x = 1\n"
+	}
+	middle += "# vim: ft=ruby\n"
+	for i := 0; i < 10; i++ {
+		middle += "x = 1\n"
+	}
+	if got := modelineLanguage([]byte(middle)); got != "" {
+		t.Errorf("Expected modeline in the middle to be ignored, got %q", got)
+	}
+}
+
+func TestParseLanguageMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		expected []LanguageMapping
+		wantErr  bool
+	}{
+		{
+			name:    "single mapping",
+			entries: []string{"*.inc=php"},
+			expected: []LanguageMapping{
+				{Pattern: "*.inc", Language: cleaner.LangPHP},
+			},
+		},
+		{
+			name:    "unsupported language",
+			entries: []string{"Jenkinsfile=groovy"},
+			wantErr: true,
+		},
+		{
+			name:    "aliases and comma-separated entries",
+			entries: []string{"*.h=c++, *.cgi=sh", "BUILD=py"},
+			expected: []LanguageMapping{
+				{Pattern: "*.h", Language: cleaner.LangCPP},
+				{Pattern: "*.cgi", Language: cleaner.LangBash},
+				{Pattern: "BUILD", Language: cleaner.LangPython},
+			},
+		},
+		{
+			name:    "missing language",
+			entries: []string{"*.inc"},
+			wantErr: true,
+		},
+		{
+			name:    "missing pattern",
+			entries: []string{"=php"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			entries: []string{"[*.inc=php"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLanguageMap(tt.entries)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %v", tt.entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLanguageMap() error = %v", err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d mappings, got %v", len(tt.expected), got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Mapping %d = %v, want %v", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Event identifies the kind of a log record so that consumers of the JSON
+// log format can filter on it without parsing free text
+type Event string
+
+const (
+	EventFileIncluded       Event = "file_included"
+	EventFileExcluded       Event = "file_excluded"
+	EventFileFiltered       Event = "file_filtered"
+	EventFileSkipped        Event = "file_skipped"
+	EventWalkSkipped        Event = "walk_skipped"
+	EventDirPruned          Event = "dir_pruned"
+	EventFileTruncated      Event = "file_truncated"
+	EventCleanFailed        Event = "clean_failed"
+	EventMarkupFailed       Event = "markup_failed"
+	EventSkeletonFailed     Event = "skeleton_failed"
+	EventTransformFailed    Event = "transform_failed"
+	EventSymbolFailed       Event = "symbol_failed"
+	EventSymbolSelected     Event = "symbol_selected"
+	EventDependencyResolved Event = "dependency_resolved"
+	EventDependencyFailed   Event = "dependency_failed"
+	EventExternalImport     Event = "external_import"
+	EventFileRanked         Event = "file_ranked"
+	EventSizeSummary        Event = "size_summary"
+	EventOutputWritten      Event = "output_written"
+	EventOutputKept         Event = "output_kept"
+	EventOutputBackedUp     Event = "output_backed_up"
+	EventDryRun             Event = "dry_run"
+)
+
+// eventLabels holds the human-readable message used for each event
+var eventLabels = map[Event]string{
+	EventFileIncluded:       "INCLUDED",
+	EventFileExcluded:       "EXCLUDED",
+	EventFileFiltered:       "FILTERED",
+	EventFileSkipped:        "IGNORED",
+	EventWalkSkipped:        "SKIPPED",
+	EventDirPruned:          "PRUNED",
+	EventFileTruncated:      "TRUNCATED",
+	EventCleanFailed:        "CLEAN FAILED",
+	EventMarkupFailed:       "CONVERT FAILED",
+	EventSkeletonFailed:     "SKELETON FAILED",
+	EventTransformFailed:    "TRANSFORM FAILED",
+	EventSymbolFailed:       "SYMBOLS FAILED",
+	EventSymbolSelected:     "SELECTED",
+	EventDependencyResolved: "RESOLVED",
+	EventDependencyFailed:   "DEPENDENCIES FAILED",
+	EventExternalImport:     "EXTERNAL",
+	EventFileRanked:         "RANKED",
+	EventSizeSummary:        "SIZE LIMITS",
+	EventOutputWritten:      "GENERATED",
+	EventOutputKept:         "KEPT",
+	EventOutputBackedUp:     "BACKED UP",
+	EventDryRun:             "DRY RUN",
+}
+
+// LevelTrace is a level below debug used for the most verbose output (-vv)
+const LevelTrace = slog.LevelDebug - 4
+
+// LogFormat selects how log records are rendered
+type LogFormat string
+
+const (
+	LogFormatText LogFormat = "text"
+	LogFormatJSON LogFormat = "json"
+)
+
+// LogOptions configures the logger created by NewLogger
+type LogOptions struct {
+	Format LogFormat
+	Level  slog.Level
+	Color  bool
+}
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(NewLogger(os.Stderr, LogOptions{
+		Format: LogFormatText,
+		Level:  slog.LevelInfo,
+		Color:  ColorEnabled(os.Stderr),
+	}))
+}
+
+// SetLogger replaces the logger used by the core package
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(discardHandler{})
+	}
+	logger.Store(l)
+}
+
+// Logger returns the logger used by the core package
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// NewLogger creates a logger writing to w in the given format
+func NewLogger(w io.Writer, opts LogOptions) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	if opts.Format == LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, handlerOpts))
+	}
+	return slog.New(&consoleHandler{
+		w:     w,
+		level: opts.Level,
+		color: opts.Color,
+		mu:    &sync.Mutex{},
+	})
+}
+
+// ParseLogFormat validates a --log-format value
+func ParseLogFormat(format string) (LogFormat, error) {
+	switch LogFormat(strings.ToLower(format)) {
+	case LogFormatText, "":
+		return LogFormatText, nil
+	case LogFormatJSON:
+		return LogFormatJSON, nil
+	default:
+		return "", fmt.Errorf("invalid log format %q: must be text or json", format)
+	}
+}
+
+// LevelForVerbosity maps the --quiet and -v flags to a log level
+func LevelForVerbosity(quiet bool, verbosity int) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbosity >= 2:
+		return LevelTrace
+	case verbosity == 1:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ColorEnabled reports whether coloured output should be written to w.
+// Colour is used only for terminals and is disabled when NO_COLOR is set.
+func ColorEnabled(w io.Writer) bool {
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// logEvent emits a typed event carrying the affected path and the reason
+func logEvent(level slog.Level, event Event, path, reason string, attrs ...any) {
+	l := Logger()
+	if !l.Enabled(context.Background(), level) {
+		return
+	}
+
+	args := make([]any, 0, len(attrs)+6)
+	args = append(args, "event", string(event))
+	if path != "" {
+		args = append(args, "path", path)
+	}
+	if reason != "" {
+		args = append(args, "reason", reason)
+	}
+	args = append(args, attrs...)
+
+	l.Log(context.Background(), level, eventLabels[event], args...)
+}
+
+// consoleHandler renders records in the human-friendly format used on terminals
+type consoleHandler struct {
+	w     io.Writer
+	level slog.Level
+	color bool
+	attrs []slog.Attr
+	mu    *sync.Mutex
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var path, reason string
+	var extra []string
+
+	collect := func(a slog.Attr) bool {
+		switch a.Key {
+		case "event":
+		case "path":
+			path = a.Value.String()
+		case "reason":
+			reason = a.Value.String()
+		default:
+			extra = append(extra, fmt.Sprintf("%s=%v", a.Key, a.Value.Any()))
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		collect(a)
+	}
+	r.Attrs(collect)
+
+	var b strings.Builder
+	color := ""
+	switch {
+	case r.Level >= slog.LevelError:
+		color = ColorRed
+		b.WriteString("✗ ")
+	case r.Level >= slog.LevelWarn:
+		color = ColorRed
+		b.WriteString("⚠️  ")
+	case r.Level >= slog.LevelInfo:
+		color = ColorGreen
+		b.WriteString("✓ ")
+	default:
+		b.WriteString("· ")
+	}
+	if !h.color {
+		color = ""
+	}
+
+	b.WriteString(r.Message)
+	if path != "" {
+		b.WriteString(": ")
+		b.WriteString(path)
+	}
+	if len(extra) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(extra, ", "))
+		b.WriteString(")")
+	}
+
+	line := b.String()
+	if color != "" {
+		line = color + line + ColorReset
+	}
+	if reason != "" {
+		line += "\n   " + reason
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(h.w, line)
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+// discardHandler drops every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLogs installs a logger writing to a buffer for the duration of the test
+func captureLogs(t *testing.T, opts LogOptions) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := Logger()
+	SetLogger(NewLogger(&buf, opts))
+	t.Cleanup(func() { SetLogger(previous) })
+	return &buf
+}
+
+func TestLevelForVerbosity(t *testing.T) {
+	tests := []struct {
+		quiet     bool
+		verbosity int
+		want      slog.Level
+	}{
+		{false, 0, slog.LevelInfo},
+		{false, 1, slog.LevelDebug},
+		{false, 2, LevelTrace},
+		{false, 5, LevelTrace},
+		{true, 2, slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		if got := LevelForVerbosity(tt.quiet, tt.verbosity); got != tt.want {
+			t.Errorf("LevelForVerbosity(%v, %d) = %v, want %v", tt.quiet, tt.verbosity, got, tt.want)
+		}
+	}
+}
+
+func TestParseLogFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LogFormat
+		wantErr bool
+	}{
+		{"text", LogFormatText, false},
+		{"", LogFormatText, false},
+		{"JSON", LogFormatJSON, false},
+		{"xml", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLogFormat(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLogFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLogFormat(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	if ColorEnabled(&buf) {
+		t.Error("Expected colour to be disabled for non-file writers")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(os.Stderr) {
+		t.Error("Expected colour to be disabled when NO_COLOR is set")
+	}
+}
+
+func TestJSONLogEvents(t *testing.T) {
+	buf := captureLogs(t, LogOptions{Format: LogFormatJSON, Level: slog.LevelInfo})
+
+	tmpDir := t.TempDir()
+	small := filepath.Join(tmpDir, "small.txt")
+	large := filepath.Join(tmpDir, "large.txt")
+	if err := os.WriteFile(small, []byte("small"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte("large content here"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fm := NewFileManager(10, 1000, OutputTypeXML)
+	if _, err := fm.ValidateFiles([]string{small, large}); err != nil {
+		t.Fatalf("ValidateFiles failed: %v", err)
+	}
+
+	events := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Log line is not valid JSON: %q", line)
+		}
+		if path, ok := record["path"].(string); ok {
+			events[filepath.Base(path)] = record
+		}
+	}
+
+	if events["small.txt"]["event"] != string(EventFileIncluded) {
+		t.Errorf("Expected included event for small.txt, got %v", events["small.txt"])
+	}
+	skipped := events["large.txt"]
+	if skipped["event"] != string(EventFileSkipped) || skipped["level"] != "WARN" {
+		t.Errorf("Expected warning skip event for large.txt, got %v", skipped)
+	}
+	if reason, _ := skipped["reason"].(string); !strings.Contains(reason, "exceeds limit") {
+		t.Errorf("Expected reason to mention the size limit, got %q", reason)
+	}
+}
+
+func TestQuietLoggingSuppressesWarnings(t *testing.T) {
+	buf := captureLogs(t, LogOptions{Format: LogFormatText, Level: LevelForVerbosity(true, 0)})
+
+	logEvent(slog.LevelWarn, EventFileSkipped, "a.txt", "too large")
+	logEvent(slog.LevelInfo, EventFileIncluded, "b.txt", "")
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output in quiet mode, got %q", buf.String())
+	}
+}
+
+func TestConsoleHandlerFormat(t *testing.T) {
+	buf := captureLogs(t, LogOptions{Format: LogFormatText, Level: slog.LevelDebug})
+
+	logEvent(slog.LevelWarn, EventFileSkipped, "big.txt", "Size: 20 B (exceeds limit of 10 B)")
+	logEvent(slog.LevelInfo, EventFileIncluded, "small.txt", "", "size", "5 B")
+	logEvent(LevelTrace, EventFileExcluded, "hidden.txt", "not shown")
+
+	got := buf.String()
+	want := "⚠️  IGNORED: big.txt\n   Size: 20 B (exceeds limit of 10 B)\n✓ INCLUDED: small.txt (size=5 B)\n"
+	if got != want {
+		t.Errorf("Unexpected console output:\n%q\nwant:\n%q", got, want)
+	}
+	if strings.Contains(got, "\033[") {
+		t.Error("Expected no colour codes when colour is disabled")
+	}
+}
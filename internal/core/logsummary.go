@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLogPatterns are the files summarized by "log-summary" without patterns
+var DefaultLogPatterns = []string{"*.log", "*.log.[0-9]*", "*.out", "*.err"}
+
+// minLogRun is the number of similar lines from which a run is collapsed
+const minLogRun = 3
+
+var (
+	// Parts of a log line that vary between otherwise identical lines, in the
+	// order they are replaced
+	logVariables = []struct {
+		pattern     *regexp.Regexp
+		placeholder string
+	}{
+		{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+		{regexp.MustCompile(`\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}\b`), "<time>"},
+		{regexp.MustCompile(`\b\d{1,2}:\d{2}:\d{2}(?:[.,]\d+)?\b`), "<time>"},
+		{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<id>"},
+		{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-f]{7,}\b`), "<id>"},
+		{regexp.MustCompile(`\d+(?:\.\d+)?`), "#"},
+	}
+	// Lines kept in full even when they repeat
+	importantLogLine = regexp.MustCompile(`(?i)\b(?:error|err|warn|warning|fatal|critical|panic|exception|traceback)\b`)
+)
+
+// LogSummaryTransformer shortens log files by collapsing runs of consecutive
+// lines that are identical, or identical apart from timestamps, IDs and
+// numbers, into the first line and a "... repeated N times" note. Error and
+// warning lines are always kept in full.
+type LogSummaryTransformer struct {
+	Patterns []string // Files to summarize, DefaultLogPatterns when empty
+}
+
+func (t *LogSummaryTransformer) Match(file FileContent) bool {
+	patterns := t.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultLogPatterns
+	}
+	return matchFilePatterns(patterns, file)
+}
+
+func (t *LogSummaryTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	file.Content = SummarizeLog(file.Content)
+	return file, nil
+}
+
+func (t *LogSummaryTransformer) String() string {
+	if len(t.Patterns) == 0 {
+		return "log-summary"
+	}
+	return strings.Join(t.Patterns, ",") + "=log-summary"
+}
+
+// SummarizeLog collapses runs of similar lines in a log, see LogSummaryTransformer
+func SummarizeLog(content string) string {
+	lines := strings.Split(content, "\n")
+	trailingNewline := lines[len(lines)-1] == ""
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+
+	result := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); {
+		if importantLogLine.MatchString(lines[i]) {
+			result = append(result, lines[i])
+			i++
+			continue
+		}
+
+		// Extend the run while the lines only differ in their variable parts
+		key := logLineKey(lines[i])
+		exact := true
+		end := i + 1
+		for ; end < len(lines) && !importantLogLine.MatchString(lines[end]) && logLineKey(lines[end]) == key; end++ {
+			exact = exact && lines[end] == lines[i]
+		}
+
+		if end-i < minLogRun {
+			result = append(result, lines[i:end]...)
+		} else {
+			note := fmt.Sprintf("... repeated %s times", formatCount(end-i))
+			if !exact {
+				note += " with varying values"
+			}
+			result = append(result, lines[i], note)
+		}
+		i = end
+	}
+
+	summary := strings.Join(result, "\n")
+	if trailingNewline {
+		summary += "\n"
+	}
+	return summary
+}
+
+// logLineKey returns a line with its timestamps, IDs and numbers replaced by
+// placeholders, so that lines differing only in those compare equal
+func logLineKey(line string) string {
+	for _, v := range logVariables {
+		line = v.pattern.ReplaceAllString(line, v.placeholder)
+	}
+	return line
+}
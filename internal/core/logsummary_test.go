@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeLog(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "exact duplicates",
+			input: "start\n" + strings.Repeat("waiting for lock\n", 532) + "done\n",
+			want:  "start\nwaiting for lock\n... repeated 532 times\ndone\n",
+		},
+		{
+			name: "near duplicates",
+			input: "2024-05-01T10:00:00Z GET /api/users/17 200 12ms\n" +
+				"2024-05-01T10:00:01Z GET /api/users/18 200 9ms\n" +
+				"2024-05-01T10:00:02Z GET /api/users/19 200 15ms\n",
+			want: "2024-05-01T10:00:00Z GET /api/users/17 200 12ms\n... repeated 3 times with varying values\n",
+		},
+		{
+			name: "uuids and hashes",
+			input: "job 3f2b9c1e-8d4a-4b2f-9e6a-1c2d3e4f5a6b queued at commit 9fceb02\n" +
+				"job 7a1d2e3f-4b5c-4d6e-8f9a-0b1c2d3e4f5a queued at commit 1a2b3c4\n" +
+				"job 0e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b queued at commit deadbee\n",
+			want: "job 3f2b9c1e-8d4a-4b2f-9e6a-1c2d3e4f5a6b queued at commit 9fceb02\n... repeated 3 times with varying values\n",
+		},
+		{
+			name:  "short runs are kept",
+			input: "a\na\nb\n",
+			want:  "a\na\nb\n",
+		},
+		{
+			name: "errors and warnings are kept in full",
+			input: "retry 1\nretry 2\nretry 3\n" +
+				"ERROR connection refused\nERROR connection refused\nERROR connection refused\n" +
+				"WARN slow response 1200ms\nWARN slow response 1300ms\nWARN slow response 1400ms\n",
+			want: "retry 1\n... repeated 3 times with varying values\n" +
+				"ERROR connection refused\nERROR connection refused\nERROR connection refused\n" +
+				"WARN slow response 1200ms\nWARN slow response 1300ms\nWARN slow response 1400ms\n",
+		},
+		{
+			name:  "different lines are kept",
+			input: "compiling main.go\nlinking binary\nrunning tests",
+			want:  "compiling main.go\nlinking binary\nrunning tests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizeLog(tt.input); got != tt.want {
+				t.Errorf("SummarizeLog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogSummaryTransformerMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{name: "log file", path: "logs/server.log", want: true},
+		{name: "rotated log", path: "logs/server.log.1", want: true},
+		{name: "source file", path: "main.go", want: false},
+		{name: "custom pattern", patterns: []string{"ci/**/*.txt"}, path: "ci/build/output.txt", want: true},
+		{name: "custom pattern replaces defaults", patterns: []string{"ci/**/*.txt"}, path: "server.log", want: false},
+		{name: "anchored pattern", patterns: []string{"/ci/*.txt"}, path: "ci/output.txt", want: true},
+		{name: "later exclusion", patterns: []string{"*.log", "!debug.log"}, path: "logs/debug.log", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := &LogSummaryTransformer{Patterns: tt.patterns}
+			// Patterns match the path below the input root, not the path
+			// relative to the working directory
+			file := FileContent{Path: "checkout/" + tt.path, Name: filepath.Base(tt.path), RootPath: tt.path}
+			if got := transformer.Match(file); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	transformer, err := ParseTransformer("ci/**/*.txt=log-summary", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := transformer.Transform(context.Background(), FileContent{Content: "tick\ntick\ntick\n"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Content != "tick\n... repeated 3 times\n" {
+		t.Errorf("content = %q", got.Content)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,6 +14,11 @@ type FileManager struct {
 	maxFileSize   int64
 	maxOutputSize int64
 	outputType    OutputType
+	oversize      Oversize
+	selections    map[string]*Selection // Files narrowed by a selector, by absolute path
+	symlinks      SymlinkPolicy         // How links among the files are read
+	skipped       []ReportEntry         // Files rejected by the most recent ValidateFiles call
+	errs          MultiError            // Files that could not be checked by the most recent call
 }
 
 // FileGroup represents a collection of files destined for the same output
@@ -30,45 +36,134 @@ func NewFileManager(maxFileSize, maxOutputSize int64, outputType OutputType) *Fi
 	}
 }
 
+// SetOversize sets what happens to files over the size limit. Unless the mode
+// is skip, such files are kept and reduced later by the processor.
+func (fm *FileManager) SetOversize(mode Oversize) {
+	fm.oversize = mode
+}
+
+// SetSelections sets the files narrowed by a selector on their input path.
+// Such files are kept whatever their size, the processor checks the size of
+// the selected lines.
+func (fm *FileManager) SetSelections(selections map[string]*Selection) {
+	fm.selections = selections
+}
+
+// SetSymlinks sets how links among the files are read. Links recorded with
+// SymlinkRecord have no content and pass the size checks.
+func (fm *FileManager) SetSymlinks(policy SymlinkPolicy) {
+	fm.symlinks = policy
+}
+
 // ValidateFiles checks files against size limits and returns valid ones
 func (fm *FileManager) ValidateFiles(files []string) ([]string, error) {
+	validFiles, totalSize, err := fm.filterFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	if totalSize > fm.maxOutputSize {
+		return nil, fmt.Errorf("total size of valid files (%s) exceeds maximum output size (%s)",
+			formatSize(totalSize), formatSize(fm.maxOutputSize))
+	}
+
+	return validFiles, nil
+}
+
+// FilterFiles checks files against the per-file size limit only. It is used
+// when the selection is later fitted to the output size, for example by ranking.
+func (fm *FileManager) FilterFiles(files []string) ([]string, error) {
+	validFiles, _, err := fm.filterFiles(files)
+	return validFiles, err
+}
+
+// selected reports whether a file is narrowed by a selector
+func (fm *FileManager) selected(file string) bool {
+	if len(fm.selections) == 0 {
+		return false
+	}
+	absPath, err := filepath.Abs(file)
+	return err == nil && fm.selections[absPath] != nil
+}
+
+// filterFiles returns the files within the per-file size limit and their total size
+func (fm *FileManager) filterFiles(files []string) ([]string, int64, error) {
 	var validFiles []string
 	var totalSize int64
 	var ignoredCount int
+	fm.skipped = nil
+	fm.errs = MultiError{}
 
 	for _, file := range files {
-		info, err := os.Stat(file)
+		if target, ok := linkTarget(file); ok && fm.symlinks == SymlinkRecord {
+			logEvent(slog.LevelInfo, EventFileIncluded, file, "", "target", target)
+			validFiles = append(validFiles, file)
+			continue
+		}
+
+		info, err := statFile(file)
 		if err != nil {
-			return nil, fmt.Errorf("error getting file info: %w", err)
+			// The file is left out and the others are still checked
+			fm.errs.Add(StageValidate, &FileError{Path: file, Stage: StageValidate, Err: fmt.Errorf("error getting file info: %w", err)})
+			continue
+		}
+
+		if info.Size() > fm.maxFileSize && fm.selected(file) {
+			logEvent(slog.LevelInfo, EventFileIncluded, file, "", "size", formatSize(info.Size()), "selector", true)
+			validFiles = append(validFiles, file)
+			totalSize += fm.maxFileSize
+			continue
+		}
+
+		if info.Size() > fm.maxFileSize && (fm.oversize == OversizeTruncate || fm.oversize == OversizeSkeleton) {
+			// The processor keeps at most the size limit of the file, so the
+			// limit is an upper bound of its reduced size
+			logEvent(slog.LevelInfo, EventFileIncluded, file, "", "size", formatSize(info.Size()), "oversize", string(fm.oversize))
+			validFiles = append(validFiles, file)
+			totalSize += fm.maxFileSize
+			continue
 		}
 
 		if info.Size() > fm.maxFileSize {
 			ignoredCount++
-			fmt.Printf("%s⚠️  IGNORED: %s%s\n", ColorRed, file, ColorReset)
-			fmt.Printf("   Size: %s (exceeds limit of %s)\n", formatSize(info.Size()), formatSize(fm.maxFileSize))
-			fmt.Println()
+			fm.skipped = append(fm.skipped, ReportEntry{
+				Path:   file,
+				Status: FileStatusSkippedSize,
+				Reason: fmt.Sprintf("size %s exceeds limit of %s", formatSize(info.Size()), formatSize(fm.maxFileSize)),
+				Size:   info.Size(),
+			})
+			logEvent(slog.LevelWarn, EventFileSkipped, file,
+				fmt.Sprintf("Size: %s (exceeds limit of %s)", formatSize(info.Size()), formatSize(fm.maxFileSize)))
 			continue
 		}
 
-		fmt.Printf("%s✓ INCLUDED: %s (%s)%s\n", ColorGreen, file, formatSize(info.Size()), ColorReset)
+		logEvent(slog.LevelInfo, EventFileIncluded, file, "", "size", formatSize(info.Size()))
 		validFiles = append(validFiles, file)
 		totalSize += info.Size()
 	}
 
 	if ignoredCount > 0 {
-		fmt.Printf("\n%s⚠️  Warning: %d file(s) were ignored due to size limits%s\n\n", ColorRed, ignoredCount, ColorReset)
+		logEvent(slog.LevelInfo, EventSizeSummary, "",
+			fmt.Sprintf("%d file(s) were ignored due to size limits", ignoredCount), "ignored", ignoredCount)
 	}
 
 	if len(validFiles) == 0 {
-		return nil, fmt.Errorf("no valid files found matching patterns")
+		return nil, 0, fmt.Errorf("no valid files found matching patterns")
 	}
 
-	if totalSize > fm.maxOutputSize {
-		return nil, fmt.Errorf("total size of valid files (%s) exceeds maximum output size (%s)",
-			formatSize(totalSize), formatSize(fm.maxOutputSize))
-	}
+	return validFiles, totalSize, nil
+}
 
-	return validFiles, nil
+// SkippedFiles returns the files rejected by the most recent ValidateFiles call
+func (fm *FileManager) SkippedFiles() []ReportEntry {
+	return append([]ReportEntry(nil), fm.skipped...)
+}
+
+// Errors returns the files the most recent ValidateFiles or FilterFiles call
+// could not check as a *MultiError, or nil when there were none
+func (fm *FileManager) Errors() error {
+	errs := fm.errs
+	return errs.Err()
 }
 
 // GroupFilesByOutput organizes files into groups based on their output destinations
@@ -132,6 +227,9 @@ func (fm *FileManager) DeriveOutputPaths(inputPaths []string, customOutputPath s
 	}
 
 	if customOutputPath != "" {
+		if filepath.IsAbs(customOutputPath) {
+			return []string{filepath.Clean(customOutputPath)}, nil
+		}
 		return []string{filepath.Join(currentDir, customOutputPath)}, nil
 	}
 
@@ -150,6 +248,11 @@ func (fm *FileManager) DeriveOutputPaths(inputPaths []string, customOutputPath s
 
 // ParseSize converts a size string (e.g., "10MB") to bytes
 func (fm *FileManager) ParseSize(size string) (int64, error) {
+	return parseSize(size)
+}
+
+// parseSize converts a size string with a B, KB, MB, GB or TB suffix to bytes
+func parseSize(size string) (int64, error) {
 	size = strings.ToUpper(strings.ReplaceAll(size, " ", ""))
 	if size == "" {
 		return 0, fmt.Errorf("size cannot be empty")
@@ -85,6 +85,71 @@ func TestValidateFiles(t *testing.T) {
 	}
 }
 
+func TestFilterFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	smallFile := filepath.Join(tempDir, "small.txt")
+	if err := os.WriteFile(smallFile, []byte("small"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	largeFile := filepath.Join(tempDir, "large.txt")
+	if err := os.WriteFile(largeFile, []byte("large content here"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output size is not enforced, only the per-file limit
+	fm := NewFileManager(10, 1, OutputTypeXML)
+	got, err := fm.FilterFiles([]string{smallFile, largeFile})
+	if err != nil {
+		t.Fatalf("FilterFiles() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != smallFile {
+		t.Errorf("FilterFiles() = %v, want [%s]", got, smallFile)
+	}
+	if skipped := fm.SkippedFiles(); len(skipped) != 1 || skipped[0].Path != largeFile {
+		t.Errorf("SkippedFiles() = %v, want %s", skipped, largeFile)
+	}
+
+	if _, err := fm.FilterFiles([]string{largeFile}); err == nil {
+		t.Error("Expected error when no files are within the size limit")
+	}
+}
+
+func TestValidateFilesOversize(t *testing.T) {
+	tempDir := t.TempDir()
+
+	largeFile := filepath.Join(tempDir, "large.log")
+	if err := os.WriteFile(largeFile, []byte("line 1\nline 2\nline 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range []Oversize{OversizeTruncate, OversizeSkeleton} {
+		fm := NewFileManager(10, 100, OutputTypeXML)
+		fm.SetOversize(mode)
+		got, err := fm.ValidateFiles([]string{largeFile})
+		if err != nil {
+			t.Fatalf("ValidateFiles() with %s error = %v", mode, err)
+		}
+		if len(got) != 1 || len(fm.SkippedFiles()) != 0 {
+			t.Errorf("ValidateFiles() with %s = %v, want the oversized file kept", mode, got)
+		}
+	}
+
+	fm := NewFileManager(10, 100, OutputTypeXML)
+	fm.SetOversize(OversizeSkip)
+	if _, err := fm.ValidateFiles([]string{largeFile}); err == nil {
+		t.Error("Expected error when oversized files are skipped")
+	}
+
+	// A file narrowed by a selector is kept, the processor checks the selected lines
+	fm = NewFileManager(10, 100, OutputTypeXML)
+	fm.SetSelections(map[string]*Selection{largeFile: {Ranges: []LineRange{{1, 1}}}})
+	if got, err := fm.ValidateFiles([]string{largeFile}); err != nil || len(got) != 1 {
+		t.Errorf("ValidateFiles() with a selection = %v, %v, want the file kept", got, err)
+	}
+}
+
 func TestGroupFilesByOutput(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -142,6 +207,7 @@ func TestDeriveOutputPaths(t *testing.T) {
 		outputType     OutputType
 		expectedSuffix string
 		wantPathsCount int
+		wantPath       string
 	}{
 		{
 			name:           "current directory",
@@ -159,6 +225,14 @@ func TestDeriveOutputPaths(t *testing.T) {
 			expectedSuffix: ".json",
 			wantPathsCount: 1,
 		},
+		{
+			name:           "absolute custom output path",
+			inputPaths:     []string{"."},
+			customOutput:   filepath.Join(os.TempDir(), "out", "..", "bundle.xml"),
+			outputType:     OutputTypeXML,
+			wantPathsCount: 1,
+			wantPath:       filepath.Join(os.TempDir(), "bundle.xml"),
+		},
 		{
 			name:           "multiple input paths",
 			inputPaths:     []string{"file1.txt", "file2.txt"},
@@ -183,6 +257,10 @@ func TestDeriveOutputPaths(t *testing.T) {
 				t.Errorf("DeriveOutputPaths() got %v paths, want %v", len(paths), tt.wantPathsCount)
 			}
 
+			if tt.wantPath != "" && paths[0] != tt.wantPath {
+				t.Errorf("DeriveOutputPaths() = %v, want %v", paths[0], tt.wantPath)
+			}
+
 			for _, path := range paths {
 				if !filepath.IsAbs(path) {
 					t.Errorf("Expected absolute path, got %v", path)
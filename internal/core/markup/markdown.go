@@ -0,0 +1,176 @@
+package markup
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// [![alt](image)](link), with the image URL captured
+	linkedImage = regexp.MustCompile(`\[!\[[^\]]*\]\(\s*<?([^)\s>]*)>?[^)]*\)\]\([^)]*\)`)
+	// [![alt][image]][link] or [![alt][image]](link), with the image label captured
+	linkedRefImage = regexp.MustCompile(`\[!\[[^\]]*\]\[([^\]]*)\]\](?:\[[^\]]*\]|\([^)]*\))`)
+	// ![alt](image), with the image URL captured
+	inlineImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]*)>?[^)]*\)`)
+	// ![alt][image], with the image label captured
+	refImage = regexp.MustCompile(`!\[[^\]]*\]\[([^\]]*)\]`)
+	// <img src="image">, with the image URL captured
+	htmlImage = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']?([^"'\s>]*)[^>]*>`)
+	// Links left empty once their image is gone
+	emptyAnchor = regexp.MustCompile(`(?i)<a\b[^>]*>\s*</a>`)
+	// [label]: url, with both captured
+	linkDefinition = regexp.MustCompile(`(?m)^ {0,3}\[([^\]]+)\]:[ \t]*<?(\S*?)>?(?:[ \t].*)?$`)
+)
+
+// badgeHosts lists URL fragments that identify status badges
+var badgeHosts = []string{
+	"shields.io", "badgen.net", "badge", "travis-ci.", "codecov.io",
+	"coveralls.io", "circleci.com", "ci.appveyor.com",
+}
+
+// isBadgeURL reports whether an image URL points at a status badge
+func isBadgeURL(url string) bool {
+	url = strings.ToLower(url)
+	for _, host := range badgeHosts {
+		if strings.Contains(url, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown strips badges, HTML comments and images from Markdown according to
+// the options. Fenced code blocks are left untouched and lines emptied by the
+// stripping are removed.
+func Markdown(content []byte, options *MarkupOptions) []byte {
+	if options == nil || (!options.StripBadges && !options.StripComments && !options.StripImages) {
+		return content
+	}
+
+	// Split the document into prose and fenced code blocks
+	lines := strings.Split(string(content), "\n")
+	var blocks []markdownBlock
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(strings.TrimSpace(trimmed), fence[:1]) == "" {
+				fence = ""
+			}
+		} else if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+		} else {
+			if len(blocks) == 0 || !blocks[len(blocks)-1].prose {
+				blocks = append(blocks, markdownBlock{prose: true})
+			}
+			blocks[len(blocks)-1].lines = append(blocks[len(blocks)-1].lines, line)
+			continue
+		}
+		if len(blocks) == 0 || blocks[len(blocks)-1].prose {
+			blocks = append(blocks, markdownBlock{})
+		}
+		blocks[len(blocks)-1].lines = append(blocks[len(blocks)-1].lines, line)
+	}
+
+	// Reference definitions may be anywhere in the document
+	var prose []string
+	for _, block := range blocks {
+		if block.prose {
+			prose = append(prose, block.lines...)
+		}
+	}
+	removedLabels := make(map[string]bool)
+	for _, match := range linkDefinition.FindAllStringSubmatch(strings.Join(prose, "\n"), -1) {
+		if isImageURL(match[2]) && (options.StripImages || (options.StripBadges && isBadgeURL(match[2]))) {
+			removedLabels[strings.ToLower(match[1])] = true
+		}
+	}
+
+	result := make([]string, 0, len(lines))
+	for _, block := range blocks {
+		if !block.prose {
+			result = append(result, block.lines...)
+			continue
+		}
+		stripped := stripMarkdown(strings.Join(block.lines, "\n"), options, removedLabels)
+		result = append(result, strings.Split(stripped, "\n")...)
+	}
+
+	return []byte(strings.Join(dropBlankedLines(lines, result), "\n"))
+}
+
+// markdownBlock is a run of prose lines or a fenced code block
+type markdownBlock struct {
+	prose bool
+	lines []string
+}
+
+// fenceMarker returns the backtick or tilde run opening a fenced code block
+func fenceMarker(line string) string {
+	for _, char := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, char))
+		if n >= 3 {
+			return strings.Repeat(char, n)
+		}
+	}
+	return ""
+}
+
+// stripMarkdown applies the options to prose outside code blocks, removing
+// references to the labels of removed image definitions. Removed text is
+// replaced by as many newlines as it spanned, so the result stays aligned line
+// by line with the input.
+func stripMarkdown(text string, options *MarkupOptions, removedLabels map[string]bool) string {
+	if options.StripComments {
+		text = htmlComment.ReplaceAllStringFunc(text, keepNewlines)
+	}
+
+	removeURL := func(url string) bool {
+		return options.StripImages || (options.StripBadges && isBadgeURL(url))
+	}
+	removeLabel := func(label string) bool {
+		return removedLabels[strings.ToLower(label)]
+	}
+
+	text = replaceMatching(text, linkedImage, removeURL)
+	text = replaceMatching(text, linkedRefImage, removeLabel)
+	text = replaceMatching(text, inlineImage, removeURL)
+	text = replaceMatching(text, refImage, removeLabel)
+	text = replaceMatching(text, htmlImage, removeURL)
+	text = emptyAnchor.ReplaceAllStringFunc(text, keepNewlines)
+	if len(removedLabels) > 0 {
+		text = replaceMatching(text, linkDefinition, removeLabel)
+	}
+	return text
+}
+
+// isImageURL reports whether a link definition looks like it targets an image
+// rather than a page, so that badge link targets are kept
+func isImageURL(url string) bool {
+	url = strings.ToLower(url)
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	for _, ext := range []string{".svg", ".png", ".gif", ".jpg", ".jpeg", ".webp"} {
+		if strings.HasSuffix(url, ext) {
+			return true
+		}
+	}
+	return strings.Contains(url, "shields.io") || strings.Contains(url, "badgen.net") || strings.Contains(url, "/badge")
+}
+
+// replaceMatching removes the matches of re whose first group satisfies remove
+func replaceMatching(text string, re *regexp.Regexp, remove func(string) bool) string {
+	return re.ReplaceAllStringFunc(text, func(match string) string {
+		if remove(re.FindStringSubmatch(match)[1]) {
+			return keepNewlines(match)
+		}
+		return match
+	})
+}
+
+// keepNewlines returns the newlines of a removed match
+func keepNewlines(match string) string {
+	return strings.Repeat("\n", strings.Count(match, "\n"))
+}
@@ -0,0 +1,126 @@
+package markup
+
+import (
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	input := `# Project
+
+[![Build](https://github.com/o/r/actions/workflows/ci.yml/badge.svg)](https://github.com/o/r/actions) [![Coverage](https://img.shields.io/codecov/c/github/o/r)](https://codecov.io/gh/o/r)
+[![Docs][docs-badge]][docs]
+
+<!-- TODO: rewrite
+this section -->
+Intro text with a ![logo](logo.png) inline.
+
+<p align="center"><a href="https://example.com"><img src="docs/screenshot.png"></a></p>
+
+` + "```html" + `
+<!-- kept inside code -->
+![kept](code.png)
+` + "```" + `
+
+[docs-badge]: https://img.shields.io/badge/docs-latest-blue
+[docs]: https://example.com/docs
+`
+
+	tests := []struct {
+		name     string
+		options  *MarkupOptions
+		expected string
+	}{
+		{
+			name:     "no stripping",
+			options:  &MarkupOptions{},
+			expected: input,
+		},
+		{
+			name:    "badges",
+			options: &MarkupOptions{StripBadges: true},
+			expected: `# Project
+
+<!-- TODO: rewrite
+this section -->
+Intro text with a ![logo](logo.png) inline.
+
+<p align="center"><a href="https://example.com"><img src="docs/screenshot.png"></a></p>
+
+` + "```html" + `
+<!-- kept inside code -->
+![kept](code.png)
+` + "```" + `
+
+[docs]: https://example.com/docs
+`,
+		},
+		{
+			name:    "comments",
+			options: &MarkupOptions{StripComments: true},
+			expected: `# Project
+
+[![Build](https://github.com/o/r/actions/workflows/ci.yml/badge.svg)](https://github.com/o/r/actions) [![Coverage](https://img.shields.io/codecov/c/github/o/r)](https://codecov.io/gh/o/r)
+[![Docs][docs-badge]][docs]
+
+Intro text with a ![logo](logo.png) inline.
+
+<p align="center"><a href="https://example.com"><img src="docs/screenshot.png"></a></p>
+
+` + "```html" + `
+<!-- kept inside code -->
+![kept](code.png)
+` + "```" + `
+
+[docs-badge]: https://img.shields.io/badge/docs-latest-blue
+[docs]: https://example.com/docs
+`,
+		},
+		{
+			name:    "images",
+			options: &MarkupOptions{StripImages: true},
+			expected: `# Project
+
+<!-- TODO: rewrite
+this section -->
+Intro text with a  inline.
+
+<p align="center"></p>
+
+` + "```html" + `
+<!-- kept inside code -->
+![kept](code.png)
+` + "```" + `
+
+[docs]: https://example.com/docs
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Markdown([]byte(input), tt.options)); got != tt.expected {
+				t.Errorf("Markdown() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsBadgeURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://img.shields.io/badge/go-1.23-blue", true},
+		{"https://github.com/o/r/actions/workflows/ci.yml/badge.svg", true},
+		{"https://codecov.io/gh/o/r/branch/main/graph/badge.svg", true},
+		{"https://travis-ci.org/o/r.svg?branch=main", true},
+		{"docs/architecture.png", false},
+		{"https://example.com/logo.svg", false},
+	}
+
+	for _, tt := range tests {
+		if got := isBadgeURL(tt.url); got != tt.expected {
+			t.Errorf("isBadgeURL(%q) = %v, want %v", tt.url, got, tt.expected)
+		}
+	}
+}
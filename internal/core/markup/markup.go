@@ -0,0 +1,72 @@
+// Package markup reduces documents and notebooks to the text worth sending to
+// a language model. Jupyter notebooks are converted to their cells, and
+// badges, comments and images can be stripped from Markdown and
+// reStructuredText.
+package markup
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a document format handled by this package
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatReST     Format = "rst"
+	FormatNotebook Format = "notebook"
+)
+
+// DetectFormat returns the document format of a file based on its extension,
+// or an empty format for other files
+func DetectFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return FormatMarkdown
+	case ".rst", ".rest":
+		return FormatReST
+	case ".ipynb":
+		return FormatNotebook
+	}
+	return ""
+}
+
+// Transform applies the options to content in the given format. Content in
+// other formats is returned unchanged.
+func Transform(format Format, content []byte, options *MarkupOptions) ([]byte, error) {
+	if options == nil {
+		options = DefaultOptions()
+	}
+
+	switch format {
+	case FormatNotebook:
+		if !options.ConvertNotebooks {
+			return content, nil
+		}
+		return Notebook(content, options)
+	case FormatMarkdown:
+		return Markdown(content, options), nil
+	case FormatReST:
+		return ReST(content, options), nil
+	}
+	return content, nil
+}
+
+// dropBlankedLines removes the lines that became blank through stripping while
+// keeping the blank lines of the original text. Runs of blank lines left
+// behind are collapsed to one.
+func dropBlankedLines(original, stripped []string) []string {
+	var result []string
+	for i, line := range stripped {
+		blank := strings.TrimSpace(line) == ""
+		if blank && i < len(original) && strings.TrimSpace(original[i]) != "" {
+			continue
+		}
+		if blank && len(result) > 0 && strings.TrimSpace(result[len(result)-1]) == "" {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
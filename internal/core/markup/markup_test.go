@@ -0,0 +1,62 @@
+package markup
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+	}{
+		{"README.md", FormatMarkdown},
+		{"docs/guide.markdown", FormatMarkdown},
+		{"docs/index.rst", FormatReST},
+		{"notebooks/Analysis.IPYNB", FormatNotebook},
+		{"main.go", ""},
+		{"Makefile", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path); got != tt.expected {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	notebook := []byte(`{"nbformat": 4, "cells": [{"cell_type": "code", "source": "x = 1", "outputs": []}]}`)
+
+	// Notebooks are converted by default
+	output, err := Transform(FormatNotebook, notebook, nil)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	if string(output) != "```\nx = 1\n```\n" {
+		t.Errorf("Transform() = %q", output)
+	}
+
+	// Conversion can be turned off
+	output, err = Transform(FormatNotebook, notebook, &MarkupOptions{})
+	if err != nil || !bytes.Equal(output, notebook) {
+		t.Errorf("Expected notebook unchanged, got %q, %v", output, err)
+	}
+
+	// Other formats pass through
+	output, err = Transform("", []byte("plain"), DefaultOptions())
+	if err != nil || string(output) != "plain" {
+		t.Errorf("Expected content unchanged, got %q, %v", output, err)
+	}
+}
+
+func TestParseOutputMode(t *testing.T) {
+	for _, value := range []string{"drop", "truncate", "keep"} {
+		if mode, err := ParseOutputMode(value); err != nil || string(mode) != value {
+			t.Errorf("ParseOutputMode(%q) = %q, %v", value, mode, err)
+		}
+	}
+	if _, err := ParseOutputMode("all"); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
@@ -0,0 +1,180 @@
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// notebook holds the parts of an nbformat 4 document that are rendered
+type notebook struct {
+	NBFormat int    `json:"nbformat"`
+	Cells    []cell `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type cell struct {
+	CellType string    `json:"cell_type"`
+	Source   multiline `json:"source"`
+	Outputs  []output  `json:"outputs"`
+}
+
+type output struct {
+	OutputType string                     `json:"output_type"`
+	Text       multiline                  `json:"text"`
+	Data       map[string]json.RawMessage `json:"data"`
+	EName      string                     `json:"ename"`
+	EValue     string                     `json:"evalue"`
+}
+
+// multiline is a notebook string, stored either as one string or as a list of lines
+type multiline string
+
+func (m *multiline) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = multiline(text)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*m = multiline(strings.Join(lines, ""))
+	return nil
+}
+
+// dataURI matches inline base64 images, e.g. in markdown cells
+var dataURI = regexp.MustCompile(`data:image/[\w.+-]+;base64,[A-Za-z0-9+/=\s]+`)
+
+// Notebook converts a Jupyter notebook to its markdown and code cells in order.
+// Code cells are written as fenced blocks in the kernel language, followed by
+// their text outputs according to the options. Image outputs and attachments
+// are dropped.
+func Notebook(content []byte, options *MarkupOptions) ([]byte, error) {
+	var nb notebook
+	if err := json.Unmarshal(content, &nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+	if nb.NBFormat != 0 && nb.NBFormat < 4 {
+		return nil, fmt.Errorf("unsupported notebook format version %d", nb.NBFormat)
+	}
+
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.Kernelspec.Language
+	}
+
+	var blocks []string
+	for _, c := range nb.Cells {
+		source := strings.TrimRight(string(c.Source), "\n")
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+
+		switch c.CellType {
+		case "markdown":
+			source = dataURI.ReplaceAllString(source, "data:image/omitted")
+			blocks = append(blocks, strings.TrimRight(string(Markdown([]byte(source), options)), "\n"))
+		case "code":
+			blocks = append(blocks, fenced(source, language))
+			if text := renderOutputs(c.Outputs, options); text != "" {
+				blocks = append(blocks, fenced(text, "output"))
+			}
+		default:
+			blocks = append(blocks, fenced(source, ""))
+		}
+	}
+
+	if len(blocks) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(blocks, "\n\n") + "\n"), nil
+}
+
+// renderOutputs returns the text of a code cell's outputs, or an empty string
+// when outputs are dropped or there are none
+func renderOutputs(outputs []output, options *MarkupOptions) string {
+	if options.NotebookOutputs == OutputsDrop {
+		return ""
+	}
+
+	var parts []string
+	for _, o := range outputs {
+		text := outputText(o)
+		if text == "" {
+			continue
+		}
+		if options.NotebookOutputs == OutputsTruncate {
+			text = truncateLines(text, options.MaxOutputLines)
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// outputText returns the plain text of an output. Rich outputs without a plain
+// text form are replaced by a note naming their media types.
+func outputText(o output) string {
+	switch o.OutputType {
+	case "stream":
+		return strings.TrimRight(string(o.Text), "\n")
+	case "error":
+		return strings.TrimSpace(o.EName + ": " + o.EValue)
+	case "execute_result", "display_data":
+		if raw, ok := o.Data["text/plain"]; ok {
+			var text multiline
+			if err := json.Unmarshal(raw, &text); err == nil {
+				return strings.TrimRight(string(text), "\n")
+			}
+		}
+		var types []string
+		for mediaType := range o.Data {
+			types = append(types, mediaType)
+		}
+		if len(types) == 0 {
+			return ""
+		}
+		sort.Strings(types)
+		return fmt.Sprintf("[%s output omitted]", strings.Join(types, ", "))
+	}
+	return ""
+}
+
+// truncateLines keeps the first limit lines of text and notes how many were cut
+func truncateLines(text string, limit int) string {
+	if limit <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) <= limit {
+		return text
+	}
+	return strings.Join(lines[:limit], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-limit)
+}
+
+// fenced wraps text in a Markdown code fence that is longer than any run of
+// backticks inside it
+func fenced(text, info string) string {
+	longest := 0
+	run := 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + info + "\n" + text + "\n" + fence
+}
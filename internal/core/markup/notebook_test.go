@@ -0,0 +1,147 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "nbformat": 4,
+ "nbformat_minor": 5,
+ "metadata": {
+  "kernelspec": {"name": "python3", "language": "python"},
+  "language_info": {"name": "python"}
+ },
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": ["# Analysis\n", "\n", "![plot](data:image/png;base64,iVBORw0KGgo=)"]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {},
+   "source": "import pandas as pd\ndf = pd.read_csv('data.csv')",
+   "outputs": []
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 2,
+   "metadata": {},
+   "source": ["for i in range(12):\n", "    print(i)"],
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["0\n", "1\n", "2\n", "3\n", "4\n", "5\n", "6\n", "7\n", "8\n", "9\n", "10\n", "11\n"]}
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "metadata": {},
+   "source": "df.plot()",
+   "outputs": [
+    {"output_type": "execute_result", "execution_count": 3, "metadata": {}, "data": {"text/plain": ["<Axes: >"]}},
+    {"output_type": "display_data", "metadata": {}, "data": {"image/png": "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="}}
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 4,
+   "metadata": {},
+   "source": "1 / 0",
+   "outputs": [
+    {"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero", "traceback": ["\u001b[0;31m---\u001b[0m"]}
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": null,
+   "metadata": {},
+   "source": "",
+   "outputs": []
+  }
+ ]
+}`
+
+func TestNotebook(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        *MarkupOptions
+		shouldContain  []string
+		shouldNotMatch []string
+	}{
+		{
+			name:    "truncated outputs",
+			options: &MarkupOptions{ConvertNotebooks: true, NotebookOutputs: OutputsTruncate, MaxOutputLines: 3},
+			shouldContain: []string{
+				"# Analysis",
+				"```python\nimport pandas as pd\ndf = pd.read_csv('data.csv')\n```",
+				"```output\n0\n1\n2\n... (9 more lines)\n```",
+				"<Axes: >\n[image/png output omitted]",
+				"ZeroDivisionError: division by zero",
+			},
+			shouldNotMatch: []string{"iVBORw0KGgo", "\"cell_type\"", "traceback", "\n11\n"},
+		},
+		{
+			name:          "kept outputs",
+			options:       &MarkupOptions{ConvertNotebooks: true, NotebookOutputs: OutputsKeep, MaxOutputLines: 3},
+			shouldContain: []string{"9\n10\n11\n```"},
+		},
+		{
+			name:           "dropped outputs",
+			options:        &MarkupOptions{ConvertNotebooks: true, NotebookOutputs: OutputsDrop},
+			shouldContain:  []string{"```python\nfor i in range(12):\n    print(i)\n```"},
+			shouldNotMatch: []string{"```output", "ZeroDivisionError"},
+		},
+		{
+			name:           "stripped images in markdown cells",
+			options:        &MarkupOptions{ConvertNotebooks: true, StripImages: true},
+			shouldNotMatch: []string{"![plot]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := Notebook([]byte(testNotebook), tt.options)
+			if err != nil {
+				t.Fatalf("Notebook() error = %v", err)
+			}
+			got := string(output)
+
+			for _, s := range tt.shouldContain {
+				if !strings.Contains(got, s) {
+					t.Errorf("Expected output to contain %q, got:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.shouldNotMatch {
+				if strings.Contains(got, s) {
+					t.Errorf("Expected output to not contain %q, got:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNotebookErrors(t *testing.T) {
+	if _, err := Notebook([]byte("not json"), DefaultOptions()); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, err := Notebook([]byte(`{"nbformat": 3, "worksheets": []}`), DefaultOptions()); err == nil {
+		t.Error("Expected error for nbformat 3")
+	}
+
+	output, err := Notebook([]byte(`{"nbformat": 4, "cells": []}`), DefaultOptions())
+	if err != nil || len(output) != 0 {
+		t.Errorf("Expected empty output for empty notebook, got %q, %v", output, err)
+	}
+}
+
+func TestFenced(t *testing.T) {
+	if got := fenced("x = 1", "python"); got != "```python\nx = 1\n```" {
+		t.Errorf("fenced() = %q", got)
+	}
+	// Fences grow past backtick runs in the content
+	if got := fenced("s = '```'", ""); got != "````\ns = '```'\n````" {
+		t.Errorf("fenced() = %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package markup
+
+import "fmt"
+
+// OutputMode controls what happens to the outputs of notebook code cells
+type OutputMode string
+
+const (
+	OutputsDrop     OutputMode = "drop"     // Leave outputs out entirely
+	OutputsTruncate OutputMode = "truncate" // Keep the first lines of each output
+	OutputsKeep     OutputMode = "keep"     // Keep all text outputs
+)
+
+// MarkupOptions defines the configuration options for documents and notebooks
+type MarkupOptions struct {
+	// ConvertNotebooks determines if Jupyter notebooks are reduced to their cells
+	ConvertNotebooks bool
+
+	// NotebookOutputs determines how code cell outputs are kept
+	NotebookOutputs OutputMode
+
+	// MaxOutputLines is the number of lines kept per output when truncating
+	MaxOutputLines int
+
+	// StripBadges determines if status badges should be removed
+	StripBadges bool
+
+	// StripComments determines if HTML comments in Markdown and comments in
+	// reStructuredText should be removed
+	StripComments bool
+
+	// StripImages determines if images should be removed
+	StripImages bool
+}
+
+// DefaultOptions returns a new MarkupOptions with default settings
+func DefaultOptions() *MarkupOptions {
+	return &MarkupOptions{
+		ConvertNotebooks: true,
+		NotebookOutputs:  OutputsTruncate,
+		MaxOutputLines:   10,
+	}
+}
+
+// ParseOutputMode validates a notebook output mode given on the command line
+func ParseOutputMode(value string) (OutputMode, error) {
+	switch mode := OutputMode(value); mode {
+	case OutputsDrop, OutputsTruncate, OutputsKeep:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid notebook outputs mode %q: expected drop, truncate or keep", value)
+}
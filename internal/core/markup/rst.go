@@ -0,0 +1,82 @@
+package markup
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// The start of an explicit markup block, e.g. ".. note::" or ".. comment"
+	explicitMarkup = regexp.MustCompile(`^(\s*)\.\.(?:\s+(.*))?$`)
+	// "|name| image:: url", with the name and URL captured
+	substitutionImage = regexp.MustCompile(`^\|([^|]+)\|\s+image::\s*(\S*)`)
+	// "image:: url" and "figure:: url", with the URL captured
+	imageDirective = regexp.MustCompile(`^(?:image|figure)::\s*(\S*)`)
+	// Any other directive, hyperlink target, footnote, citation or substitution
+	rstMarkup = regexp.MustCompile(`^(?:[\w:+-]+::|_|\[|\|)`)
+)
+
+// ReST strips badges, comments and images from reStructuredText according to
+// the options. Badges are image directives or substitution definitions with a
+// badge URL. Substitution references to removed images are removed as well.
+func ReST(content []byte, options *MarkupOptions) []byte {
+	if options == nil || (!options.StripBadges && !options.StripComments && !options.StripImages) {
+		return content
+	}
+
+	lines := strings.Split(string(content), "\n")
+	result := append([]string(nil), lines...)
+	removedSubstitutions := make(map[string]bool)
+
+	for i := 0; i < len(lines); i++ {
+		match := explicitMarkup.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+
+		indent, body := len(match[1]), strings.TrimSpace(match[2])
+		remove := false
+		if m := substitutionImage.FindStringSubmatch(body); m != nil {
+			if options.StripImages || (options.StripBadges && isBadgeURL(m[2])) {
+				remove = true
+				removedSubstitutions[m[1]] = true
+			}
+		} else if m := imageDirective.FindStringSubmatch(body); m != nil {
+			remove = options.StripImages || (options.StripBadges && isBadgeURL(m[1]))
+		} else if !rstMarkup.MatchString(body) {
+			remove = options.StripComments
+		}
+		if !remove {
+			continue
+		}
+
+		// The block continues over lines indented deeper than its marker
+		end := i + 1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "" {
+				continue
+			}
+			if len(lines[j])-len(strings.TrimLeft(lines[j], " \t")) <= indent {
+				break
+			}
+			end = j + 1
+		}
+		for j := i; j < end; j++ {
+			result[j] = ""
+		}
+		i = end - 1
+	}
+
+	if len(removedSubstitutions) > 0 {
+		for i, line := range result {
+			for name := range removedSubstitutions {
+				line = strings.ReplaceAll(line, "|"+name+"|__", "")
+				line = strings.ReplaceAll(line, "|"+name+"|_", "")
+				line = strings.ReplaceAll(line, "|"+name+"|", "")
+			}
+			result[i] = line
+		}
+	}
+
+	return []byte(strings.Join(dropBlankedLines(lines, result), "\n"))
+}
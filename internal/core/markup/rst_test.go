@@ -0,0 +1,88 @@
+package markup
+
+import (
+	"testing"
+)
+
+func TestReST(t *testing.T) {
+	input := `Project
+=======
+
+|build| |coverage|
+
+.. |build| image:: https://github.com/o/r/actions/workflows/ci.yml/badge.svg
+   :target: https://github.com/o/r/actions
+.. |coverage| image:: https://img.shields.io/codecov/c/github/o/r
+   :target: https://codecov.io/gh/o/r
+
+.. This comment is not rendered
+   and spans two lines.
+
+.. image:: docs/screenshot.png
+   :alt: Screenshot
+
+.. note::
+
+   Keep this note.
+
+.. _target:
+
+Text with |build| inline.
+`
+
+	tests := []struct {
+		name     string
+		options  *MarkupOptions
+		expected string
+	}{
+		{
+			name:     "no stripping",
+			options:  &MarkupOptions{},
+			expected: input,
+		},
+		{
+			name:    "badges",
+			options: &MarkupOptions{StripBadges: true},
+			expected: `Project
+=======
+
+.. This comment is not rendered
+   and spans two lines.
+
+.. image:: docs/screenshot.png
+   :alt: Screenshot
+
+.. note::
+
+   Keep this note.
+
+.. _target:
+
+Text with  inline.
+`,
+		},
+		{
+			name:    "comments and images",
+			options: &MarkupOptions{StripComments: true, StripImages: true},
+			expected: `Project
+=======
+
+.. note::
+
+   Keep this note.
+
+.. _target:
+
+Text with  inline.
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(ReST([]byte(input), tt.options)); got != tt.expected {
+				t.Errorf("ReST() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
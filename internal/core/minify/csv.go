@@ -0,0 +1,63 @@
+package minify
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CSV keeps the header and the first maxItems rows of delimited data, followed
+// by a line with the number of rows left out and the total row count.
+// Quoted fields spanning several lines count as one row.
+func CSV(content []byte, comma rune, maxItems int) ([]byte, error) {
+	if maxItems <= 0 {
+		return content, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	w.Comma = comma
+
+	rows := -1 // The first record is the header
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", formatName(comma), err)
+		}
+		if rows < maxItems {
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+		rows++
+	}
+
+	if rows <= maxItems {
+		return content, nil
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&out, "... %d more rows (%d rows total)\n", rows-maxItems, rows)
+	return out.Bytes(), nil
+}
+
+// formatName names the delimited format for error messages
+func formatName(comma rune) string {
+	if comma == '\t' {
+		return "TSV"
+	}
+	return "CSV"
+}
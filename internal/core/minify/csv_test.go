@@ -0,0 +1,69 @@
+package minify
+
+import "testing"
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		comma    rune
+		maxItems int
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "header and first rows are kept",
+			input:    "id,name\n1,a\n2,b\n3,c\n4,d\n",
+			comma:    ',',
+			maxItems: 2,
+			want:     "id,name\n1,a\n2,b\n... 2 more rows (4 rows total)\n",
+		},
+		{
+			name:     "short files are unchanged",
+			input:    "id,name\r\n1,a\r\n",
+			comma:    ',',
+			maxItems: 2,
+			want:     "id,name\r\n1,a\r\n",
+		},
+		{
+			name:     "quoted fields spanning lines",
+			input:    "id,note\n1,\"multi\nline\"\n2,\"x, y\"\n3,z\n",
+			comma:    ',',
+			maxItems: 2,
+			want:     "id,note\n1,\"multi\nline\"\n2,\"x, y\"\n... 1 more rows (3 rows total)\n",
+		},
+		{
+			name:     "tab separated",
+			input:    "gene\tcount\nA\t1\nB\t2\n",
+			comma:    '\t',
+			maxItems: 1,
+			want:     "gene\tcount\nA\t1\n... 1 more rows (2 rows total)\n",
+		},
+		{
+			name:     "no limit",
+			input:    "a\n1\n2\n",
+			comma:    ',',
+			maxItems: 0,
+			want:     "a\n1\n2\n",
+		},
+		{name: "invalid quoting", input: "a,b\n1,\"open\n", comma: ',', maxItems: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CSV([]byte(tt.input), tt.comma, tt.maxItems)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CSV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package minify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSON re-emits a JSON document without insignificant whitespace, keeping the
+// order of object keys. Arrays longer than maxItems keep their first elements
+// followed by a string noting how many were left out.
+func JSON(content []byte, maxItems int) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	if err := writeJSONValue(dec, &out, maxItems); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: unexpected data after the top-level value")
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// writeJSONValue copies the next value from the decoder to out. A nil out
+// skips the value.
+func writeJSONValue(dec *json.Decoder, out *bytes.Buffer, maxItems int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			writeByte(out, '{')
+			for i := 0; dec.More(); i++ {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					writeByte(out, ',')
+				}
+				if err := writeJSONString(out, key.(string)); err != nil {
+					return err
+				}
+				writeByte(out, ':')
+				if err := writeJSONValue(dec, out, maxItems); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			writeByte(out, '}')
+		case '[':
+			writeByte(out, '[')
+			n := 0
+			for ; dec.More(); n++ {
+				target := out
+				if maxItems > 0 && n >= maxItems {
+					target = nil
+				} else if n > 0 {
+					writeByte(out, ',')
+				}
+				if err := writeJSONValue(dec, target, maxItems); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			if maxItems > 0 && n > maxItems {
+				writeByte(out, ',')
+				if err := writeJSONString(out, omittedItems(n-maxItems, n)); err != nil {
+					return err
+				}
+			}
+			writeByte(out, ']')
+		}
+	case string:
+		return writeJSONString(out, v)
+	case json.Number:
+		writeString(out, v.String())
+	case bool:
+		writeString(out, fmt.Sprint(v))
+	case nil:
+		writeString(out, "null")
+	}
+	return nil
+}
+
+// writeJSONString writes a quoted string without escaping HTML characters
+func writeJSONString(out *bytes.Buffer, s string) error {
+	if out == nil {
+		return nil
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	out.Truncate(out.Len() - 1) // Drop the newline added by Encode
+	return nil
+}
+
+func writeByte(out *bytes.Buffer, b byte) {
+	if out != nil {
+		out.WriteByte(b)
+	}
+}
+
+func writeString(out *bytes.Buffer, s string) {
+	if out != nil {
+		out.WriteString(s)
+	}
+}
@@ -0,0 +1,78 @@
+package minify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxItems int
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "whitespace is removed",
+			input:    "{\n  \"name\": \"app\",\n  \"port\": 8080,\n  \"debug\": false,\n  \"proxy\": null\n}\n",
+			maxItems: 10,
+			want:     `{"name":"app","port":8080,"debug":false,"proxy":null}`,
+		},
+		{
+			name:     "key order is kept",
+			input:    `{"z": 1, "a": 2, "m": 3}`,
+			maxItems: 10,
+			want:     `{"z":1,"a":2,"m":3}`,
+		},
+		{
+			name:     "long arrays are sampled",
+			input:    `{"users": [{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}], "tags": ["a"]}`,
+			maxItems: 2,
+			want:     `{"users":[{"id":1},{"id":2},"... 2 more items (4 total)"],"tags":["a"]}`,
+		},
+		{
+			name:     "nested arrays are sampled",
+			input:    `[[1, 2, 3], [4, 5, 6], [7, 8, 9]]`,
+			maxItems: 2,
+			want:     `[[1,2,"... 1 more items (3 total)"],[4,5,"... 1 more items (3 total)"],"... 1 more items (3 total)"]`,
+		},
+		{
+			name:     "no limit",
+			input:    `[1, 2, 3]`,
+			maxItems: 0,
+			want:     `[1,2,3]`,
+		},
+		{
+			name:     "numbers and strings are kept exactly",
+			input:    `{"big": 12345678901234567890, "pi": 3.14e0, "html": "<b>&</b>", "esc": "a\"b\n"}`,
+			maxItems: 10,
+			want:     `{"big":12345678901234567890,"pi":3.14e0,"html":"<b>&</b>","esc":"a\"b\n"}`,
+		},
+		{name: "invalid", input: `{"a": }`, maxItems: 10, wantErr: true},
+		{name: "trailing data", input: `{} {}`, maxItems: 10, wantErr: true},
+		{name: "empty", input: "", maxItems: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSON([]byte(tt.input), tt.maxItems)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.TrimSuffix(string(got), "\n") != tt.want {
+				t.Errorf("JSON() = %q, want %q", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("JSON() produced invalid JSON: %q", got)
+			}
+		})
+	}
+}
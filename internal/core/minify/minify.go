@@ -0,0 +1,64 @@
+// Package minify shrinks structured data files so that large fixtures do not
+// dominate a bundle. JSON and YAML are re-emitted compactly with long arrays
+// sampled down to their first elements, and CSV and TSV files are cut down to
+// their header and first rows. Every reduction is annotated with a count of
+// what was left out.
+package minify
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a data format handled by this package
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+	FormatTSV  Format = "tsv"
+)
+
+// DefaultMaxItems is the number of array elements or rows kept by default
+const DefaultMaxItems = 10
+
+// DetectFormat returns the data format of a file based on its extension, or an
+// empty format for other files
+func DetectFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	}
+	return ""
+}
+
+// Minify reduces content in the given format, keeping at most maxItems
+// elements of each array and maxItems data rows. A limit of zero or less keeps
+// all elements and only compacts JSON and YAML. Content that cannot be parsed
+// is returned with an error.
+func Minify(format Format, content []byte, maxItems int) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return JSON(content, maxItems)
+	case FormatYAML:
+		return YAML(content, maxItems)
+	case FormatCSV:
+		return CSV(content, ',', maxItems)
+	case FormatTSV:
+		return CSV(content, '\t', maxItems)
+	}
+	return nil, fmt.Errorf("unsupported data format %q", format)
+}
+
+// omittedItems is the note standing in for the array elements left out
+func omittedItems(omitted, total int) string {
+	return fmt.Sprintf("... %d more items (%d total)", omitted, total)
+}
@@ -0,0 +1,57 @@
+package minify
+
+import "testing"
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+	}{
+		{"testdata/users.json", FormatJSON},
+		{"config.YAML", FormatYAML},
+		{".github/workflows/ci.yml", FormatYAML},
+		{"data/sales.csv", FormatCSV},
+		{"data/genes.tsv", FormatTSV},
+		{"main.go", ""},
+		{"data.jsonl", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path); got != tt.expected {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		format  Format
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{format: FormatJSON, input: "[1, 2, 3]", want: "[1,2,\"... 1 more items (3 total)\"]\n"},
+		{format: FormatYAML, input: "- 1\n- 2\n- 3\n", want: "[1, 2, '... 1 more items (3 total)']\n"},
+		{format: FormatCSV, input: "a,b\n1,2\n3,4\n5,6\n", want: "a,b\n1,2\n3,4\n... 1 more rows (3 rows total)\n"},
+		{format: FormatTSV, input: "a\tb\n1\t2\n3\t4\n5\t6\n", want: "a\tb\n1\t2\n3\t4\n... 1 more rows (3 rows total)\n"},
+		{format: "xml", input: "<a/>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := Minify(tt.format, []byte(tt.input), 2)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Minify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package minify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML re-emits a YAML stream without comments and blank lines, writing
+// sequences of plain values on one line. Sequences longer than maxItems keep
+// their first elements followed by a string noting how many were left out.
+func YAML(content []byte, maxItems int) ([]byte, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		compactYAML(&doc, maxItems)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("error encoding YAML: %w", err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("error encoding YAML: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// compactYAML drops the comments of a node tree, samples long sequences and
+// switches sequences of scalars to flow style
+func compactYAML(node *yaml.Node, maxItems int) {
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+
+	if node.Kind == yaml.SequenceNode && maxItems > 0 && len(node.Content) > maxItems {
+		total := len(node.Content)
+		node.Content = append(node.Content[:maxItems:maxItems], &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: omittedItems(total-maxItems, total),
+		})
+	}
+
+	scalars := true
+	for _, child := range node.Content {
+		compactYAML(child, maxItems)
+		scalars = scalars && child.Kind == yaml.ScalarNode && child.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0
+	}
+	if node.Kind == yaml.SequenceNode && scalars {
+		node.Style = yaml.FlowStyle
+	}
+}
@@ -0,0 +1,63 @@
+package minify
+
+import "testing"
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxItems int
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "comments and blank lines are removed",
+			input:    "# Service settings\nname: app # the name\n\nport: 8080\n",
+			maxItems: 10,
+			want:     "name: app\nport: 8080\n",
+		},
+		{
+			name:     "scalar lists use flow style",
+			input:    "tags:\n  - web\n  - api\n",
+			maxItems: 10,
+			want:     "tags: [web, api]\n",
+		},
+		{
+			name:     "long sequences are sampled",
+			input:    "users:\n  - name: a\n    id: 1\n  - name: b\n    id: 2\n  - name: c\n    id: 3\n",
+			maxItems: 2,
+			want:     "users:\n  - name: a\n    id: 1\n  - name: b\n    id: 2\n  - '... 1 more items (3 total)'\n",
+		},
+		{
+			name:     "multiple documents",
+			input:    "a: 1\n---\nb: [1, 2, 3]\n",
+			maxItems: 2,
+			want:     "a: 1\n---\nb: [1, 2, '... 1 more items (3 total)']\n",
+		},
+		{
+			name:     "block scalars are kept",
+			input:    "script: |\n  make build\n  make test\n",
+			maxItems: 10,
+			want:     "script: |\n  make build\n  make test\n",
+		},
+		{name: "invalid", input: "a: [1, 2\nb: c\n", maxItems: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := YAML([]byte(tt.input), tt.maxItems)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("YAML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
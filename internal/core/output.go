@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -72,57 +73,77 @@ func (g *OutputGenerator) normalizePath(path string) string {
 	return filepath.Join(lastDir, path)
 }
 
-// Generate creates an output file containing the provided file contents
+// Generate creates an output file containing the provided file contents. The
+// file is written next to the output and renamed into place, an existing
+// output is handled by the overwrite policy.
 func (g *OutputGenerator) Generate(contents []FileContent) error {
-	// Create a temporary file
-	tempFile, err := os.CreateTemp("", "filefusion-*")
+	if write, err := CheckOutput(g.options.OutputPath, nil, g.options.Overwrite); err != nil || !write {
+		return err
+	}
+
+	// Create a temporary file in the output directory
+	tempFile, err := createAtomic(g.options.OutputPath)
 	if err != nil {
 		return &MixError{
 			File:    g.options.OutputPath,
 			Message: fmt.Sprintf("error creating temporary file: %v", err),
 		}
 	}
-	tempPath := tempFile.Name()
-	defer os.Remove(tempPath)
 
 	// Normalize paths in contents
 	normalizedContents := make([]FileContent, len(contents))
 	for i, content := range contents {
+		path := content.Path
+		if content.Origin == "" {
+			path = g.normalizePath(path)
+		}
 		normalizedContents[i] = FileContent{
-			Path:      g.normalizePath(content.Path),
-			Name:      content.Name,
-			Content:   content.Content,
-			Extension: content.Extension,
-			Size:      content.Size,
+			Path:         path,
+			Name:         content.Name,
+			Content:      content.Content,
+			Extension:    content.Extension,
+			Size:         content.Size,
+			OriginalSize: content.OriginalSize,
+			Language:     content.Language,
+			Truncated:    content.Truncated,
+			Origin:       content.Origin,
+			Lines:        content.Lines,
+			Target:       content.Target,
 		}
 	}
 
+	// Compute the statistics section if requested
+	var stats *BundleStats
+	if g.options.IncludeStats {
+		stats = ComputeStats(normalizedContents)
+	}
+
 	// Generate content in the specified format
 	switch g.options.OutputType {
 	case OutputTypeJSON:
-		err = g.generateJSON(tempFile, normalizedContents)
+		err = g.generateJSON(tempFile.File, normalizedContents, stats)
 	case OutputTypeYAML:
-		err = g.generateYAML(tempFile, normalizedContents)
+		err = g.generateYAML(tempFile.File, normalizedContents, stats)
 	case OutputTypeXML:
-		err = g.generateXML(tempFile, normalizedContents)
+		err = g.generateXML(tempFile.File, normalizedContents, stats)
 	default:
-		return &MixError{Message: fmt.Sprintf("unsupported output type: %s", g.options.OutputType)}
+		err = &MixError{Message: fmt.Sprintf("unsupported output type: %s", g.options.OutputType)}
 	}
 
 	if err != nil {
+		tempFile.abort()
 		return err
 	}
 
-	// Close the temp file
-	tempFile.Close()
-
 	// Check the size
-	info, err := os.Stat(tempPath)
+	info, err := tempFile.Stat()
 	if err != nil {
+		tempFile.abort()
 		return &MixError{Message: fmt.Sprintf("error checking output file size: %v", err)}
 	}
 
 	if info.Size() > g.options.MaxOutputSize {
+		tempFile.abort()
 		return &MixError{
 			Message: fmt.Sprintf("output size (%d bytes) exceeds maximum allowed size (%d bytes)",
 				info.Size(), g.options.MaxOutputSize),
@@ -130,22 +151,40 @@ func (g *OutputGenerator) Generate(contents []FileContent) error {
 	}
 
 	// Move temp file to final destination
-	return os.Rename(tempPath, g.options.OutputPath)
+	if err := tempFile.commit(g.options.Overwrite); err != nil {
+		return &MixError{
+			File:    g.options.OutputPath,
+			Message: fmt.Sprintf("error writing output file: %v", err),
+		}
+	}
+	return nil
 }
 
 // generateJSON creates a JSON output file
-func (g *OutputGenerator) generateJSON(file *os.File, contents []FileContent) error {
+func (g *OutputGenerator) generateJSON(file *os.File, contents []FileContent, stats *BundleStats) error {
 	output := struct {
-		Documents []struct {
+		Generator  string      `json:"generator"`
+		Provenance *Provenance `json:"provenance,omitempty"`
+		Documents  []struct {
 			Index           int    `json:"index"`
 			Source          string `json:"source"`
 			DocumentContent string `json:"document_content"`
+			Truncated       bool   `json:"truncated,omitempty"`
+			Lines           string `json:"lines,omitempty"`
+			Target          string `json:"target,omitempty"`
 		} `json:"documents"`
+		Statistics *BundleStats `json:"statistics,omitempty"`
 	}{
+		Generator:  bundleGenerator,
+		Provenance: g.options.Provenance,
+		Statistics: stats,
 		Documents: make([]struct {
 			Index           int    `json:"index"`
 			Source          string `json:"source"`
 			DocumentContent string `json:"document_content"`
+			Truncated       bool   `json:"truncated,omitempty"`
+			Lines           string `json:"lines,omitempty"`
+			Target          string `json:"target,omitempty"`
 		}, len(contents)),
 	}
 
@@ -154,10 +193,16 @@ func (g *OutputGenerator) generateJSON(file *os.File, contents []FileContent) er
 			Index           int    `json:"index"`
 			Source          string `json:"source"`
 			DocumentContent string `json:"document_content"`
+			Truncated       bool   `json:"truncated,omitempty"`
+			Lines           string `json:"lines,omitempty"`
+			Target          string `json:"target,omitempty"`
 		}{
 			Index:           i + 1,
 			Source:          content.Path,
 			DocumentContent: content.Content,
+			Truncated:       content.Truncated,
+			Lines:           content.Lines,
+			Target:          content.Target,
 		}
 	}
 
@@ -170,18 +215,30 @@ func (g *OutputGenerator) generateJSON(file *os.File, contents []FileContent) er
 }
 
 // generateYAML writes the content in YAML format
-func (g *OutputGenerator) generateYAML(file *os.File, contents []FileContent) error {
+func (g *OutputGenerator) generateYAML(file *os.File, contents []FileContent, stats *BundleStats) error {
 	docs := struct {
-		Documents []struct {
+		Generator  string      `yaml:"generator"`
+		Provenance *Provenance `yaml:"provenance,omitempty"`
+		Documents  []struct {
 			Index           int    `yaml:"index"`
 			Source          string `yaml:"source"`
 			DocumentContent string `yaml:"document_content"`
+			Truncated       bool   `yaml:"truncated,omitempty"`
+			Lines           string `yaml:"lines,omitempty"`
+			Target          string `yaml:"target,omitempty"`
 		} `yaml:"documents"`
+		Statistics *BundleStats `yaml:"statistics,omitempty"`
 	}{
+		Generator:  bundleGenerator,
+		Provenance: g.options.Provenance,
+		Statistics: stats,
 		Documents: make([]struct {
 			Index           int    `yaml:"index"`
 			Source          string `yaml:"source"`
 			DocumentContent string `yaml:"document_content"`
+			Truncated       bool   `yaml:"truncated,omitempty"`
+			Lines           string `yaml:"lines,omitempty"`
+			Target          string `yaml:"target,omitempty"`
 		}, len(contents)),
 	}
 
@@ -190,10 +247,16 @@ func (g *OutputGenerator) generateYAML(file *os.File, contents []FileContent) er
 			Index           int    `yaml:"index"`
 			Source          string `yaml:"source"`
 			DocumentContent string `yaml:"document_content"`
+			Truncated       bool   `yaml:"truncated,omitempty"`
+			Lines           string `yaml:"lines,omitempty"`
+			Target          string `yaml:"target,omitempty"`
 		}{
 			Index:           i + 1,
 			Source:          content.Path,
 			DocumentContent: content.Content,
+			Truncated:       content.Truncated,
+			Lines:           content.Lines,
+			Target:          content.Target,
 		}
 	}
 
@@ -206,15 +269,35 @@ func (g *OutputGenerator) generateYAML(file *os.File, contents []FileContent) er
 }
 
 // generateXML writes the content in XML format
-func (g *OutputGenerator) generateXML(file *os.File, contents []FileContent) error {
+func (g *OutputGenerator) generateXML(file *os.File, contents []FileContent, stats *BundleStats) error {
 	const xmlTemplate = `<?xml version="1.0" encoding="UTF-8"?>
-<documents>{{range $index, $file := .}}
-<document index="{{add $index 1}}">
+<documents generator="{{.Generator}}">{{if .Provenance}}
+{{.Provenance}}{{end}}{{range $index, $file := .Contents}}
+<document index="{{add $index 1}}"{{if .Truncated}} truncated="true"{{end}}{{if .Lines}} lines="{{.Lines}}"{{end}}{{if .Target}} target="{{escapeXML .Target}}"{{end}}>
 <source>{{.Path}}</source>
 <document_content>{{- escapeXML .Content -}}</document_content>
-</document>{{end}}
+</document>{{end}}{{if .Statistics}}
+{{.Statistics}}{{end}}
 </documents>`
 
+	var statistics string
+	if stats != nil {
+		data, err := xml.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return &MixError{Message: fmt.Sprintf("error encoding statistics: %v", err)}
+		}
+		statistics = string(data)
+	}
+
+	var provenance string
+	if g.options.Provenance != nil {
+		data, err := xml.MarshalIndent(g.options.Provenance, "", "  ")
+		if err != nil {
+			return &MixError{Message: fmt.Sprintf("error encoding provenance: %v", err)}
+		}
+		provenance = string(data)
+	}
+
 	t, err := template.New("llm").Funcs(template.FuncMap{
 		"add": func(a, b int) int { return a + b },
 		"escapeXML": func(s string) string {
@@ -230,7 +313,19 @@ func (g *OutputGenerator) generateXML(file *os.File, contents []FileContent) err
 		return &MixError{Message: fmt.Sprintf("error parsing template: %v", err)}
 	}
 
-	if err := t.Execute(file, contents); err != nil {
+	data := struct {
+		Generator  string
+		Provenance string
+		Contents   []FileContent
+		Statistics string
+	}{
+		Generator:  bundleGenerator,
+		Provenance: provenance,
+		Contents:   contents,
+		Statistics: statistics,
+	}
+
+	if err := t.Execute(file, data); err != nil {
 		return &MixError{Message: fmt.Sprintf("error executing template: %v", err)}
 	}
 	return nil
@@ -151,7 +151,7 @@ func TestOutputGenerator_Generate(t *testing.T) {
 				expectedPath = filepath.ToSlash(expectedPath)
 
 				assert.Contains(t, s, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>")
-				assert.Contains(t, s, "<documents>")
+				assert.Contains(t, s, "<documents generator=\"filefusion\">")
 				assert.Contains(t, s, "<document index=\"1\">")
 				assert.Contains(t, s, fmt.Sprintf("<source>%s</source>", expectedPath))
 				assert.Contains(t, s, "<document_content>package main")
@@ -227,7 +227,7 @@ func TestOutputGenerator_Generate(t *testing.T) {
 			outputType: OutputTypeXML,
 			maxSize:    1024,
 			verifyFunc: func(t *testing.T, content []byte) {
-				expected := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<documents>\n</documents>"
+				expected := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<documents generator=\"filefusion\">\n</documents>"
 				assert.Equal(t, expected, string(content))
 			},
 		},
@@ -390,7 +390,7 @@ func TestOutputFormatting(t *testing.T) {
 				assert.Contains(t, s, "&amp;")
 				// Check structure
 				assert.True(t, strings.HasPrefix(s, "<?xml"))
-				assert.Contains(t, s, "<documents>")
+				assert.Contains(t, s, "<documents generator=\"filefusion\">")
 			},
 		},
 		{
@@ -441,3 +441,237 @@ func TestOutputFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputStatistics(t *testing.T) {
+	contents := []FileContent{
+		{Path: "src/main.go", Content: "package main\n", Size: 13, OriginalSize: 40, Language: "go"},
+		{Path: "config.yaml", Content: "key: value\n", Size: 11},
+	}
+
+	tests := []struct {
+		name       string
+		outputType OutputType
+		verifyFunc func(t *testing.T, content []byte)
+	}{
+		{
+			name:       "XML statistics",
+			outputType: OutputTypeXML,
+			verifyFunc: func(t *testing.T, content []byte) {
+				s := string(content)
+				assert.Contains(t, s, "<statistics>")
+				assert.Contains(t, s, "<total_files>2</total_files>")
+				assert.Contains(t, s, "<bytes_saved>27</bytes_saved>")
+				assert.Contains(t, s, `<language name="go" files="1" bytes="13"`)
+				assert.True(t, strings.HasSuffix(s, "</statistics>\n</documents>"))
+			},
+		},
+		{
+			name:       "JSON statistics",
+			outputType: OutputTypeJSON,
+			verifyFunc: func(t *testing.T, content []byte) {
+				var output struct {
+					Statistics *BundleStats `json:"statistics"`
+				}
+				require.NoError(t, json.Unmarshal(content, &output))
+				require.NotNil(t, output.Statistics)
+				assert.Equal(t, 2, output.Statistics.TotalFiles)
+				assert.Equal(t, int64(24), output.Statistics.TotalBytes)
+				assert.True(t, strings.HasSuffix(output.Statistics.LargestFiles[0].Path, "src/main.go"))
+			},
+		},
+		{
+			name:       "YAML statistics",
+			outputType: OutputTypeYAML,
+			verifyFunc: func(t *testing.T, content []byte) {
+				var output struct {
+					Statistics *BundleStats `yaml:"statistics"`
+				}
+				require.NoError(t, yaml.Unmarshal(content, &output))
+				require.NotNil(t, output.Statistics)
+				assert.Equal(t, int64(27), output.Statistics.BytesSaved)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "output")
+			generator, err := NewOutputGenerator(&MixOptions{
+				OutputPath:    outputPath,
+				OutputType:    tt.outputType,
+				MaxOutputSize: 1024 * 1024,
+				IncludeStats:  true,
+			})
+			require.NoError(t, err)
+			require.NoError(t, generator.Generate(contents))
+
+			content, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			tt.verifyFunc(t, content)
+		})
+	}
+}
+
+func TestGenerateTruncatedFlag(t *testing.T) {
+	contents := []FileContent{
+		{Path: "app.log", Name: "app.log", Content: "start\nend\n", Size: 10, Truncated: true},
+		{Path: "main.go", Name: "main.go", Content: "package main\n", Size: 13},
+	}
+
+	tests := []struct {
+		outputType OutputType
+		ext        string
+		want       string
+		unwanted   string
+	}{
+		{outputType: OutputTypeXML, ext: ".xml", want: `<document index="1" truncated="true">`, unwanted: `<document index="2" truncated`},
+		{outputType: OutputTypeJSON, ext: ".json", want: `"truncated": true`},
+		{outputType: OutputTypeYAML, ext: ".yaml", want: "truncated: true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outputType), func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "output"+tt.ext)
+			gen, err := NewOutputGenerator(&MixOptions{
+				OutputPath:    outputPath,
+				OutputType:    tt.outputType,
+				MaxOutputSize: 1024,
+			})
+			require.NoError(t, err)
+			require.NoError(t, gen.Generate(contents))
+
+			data, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			s := string(data)
+			assert.Contains(t, s, tt.want)
+			assert.Equal(t, 1, strings.Count(s, "truncated"), "only the truncated document is flagged")
+			if tt.unwanted != "" {
+				assert.NotContains(t, s, tt.unwanted)
+			}
+		})
+	}
+}
+
+func TestGenerateLinesMetadata(t *testing.T) {
+	contents := []FileContent{
+		{Path: "main.go", Name: "main.go", Content: "func main() {}\n", Size: 15, Lines: "12-12,40-55"},
+		{Path: "util.go", Name: "util.go", Content: "package main\n", Size: 13},
+	}
+
+	tests := []struct {
+		outputType OutputType
+		ext        string
+		want       string
+	}{
+		{outputType: OutputTypeXML, ext: ".xml", want: `<document index="1" lines="12-12,40-55">`},
+		{outputType: OutputTypeJSON, ext: ".json", want: `"lines": "12-12,40-55"`},
+		{outputType: OutputTypeYAML, ext: ".yaml", want: "lines: 12-12,40-55"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outputType), func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "output"+tt.ext)
+			gen, err := NewOutputGenerator(&MixOptions{
+				OutputPath:    outputPath,
+				OutputType:    tt.outputType,
+				MaxOutputSize: 1024,
+			})
+			require.NoError(t, err)
+			require.NoError(t, gen.Generate(contents))
+
+			data, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			s := string(data)
+			assert.Contains(t, s, tt.want)
+			assert.Equal(t, 1, strings.Count(s, "lines"), "only the selected document has lines")
+		})
+	}
+}
+
+func TestGenerateLinkTarget(t *testing.T) {
+	contents := []FileContent{
+		{Path: "alias.go", Name: "alias.go", Target: "../lib/a&b.go"},
+	}
+
+	tests := []struct {
+		outputType OutputType
+		ext        string
+		want       string
+	}{
+		{outputType: OutputTypeXML, ext: ".xml", want: `<document index="1" target="../lib/a&amp;b.go">`},
+		{outputType: OutputTypeJSON, ext: ".json", want: `"target": "../lib/a\u0026b.go"`},
+		{outputType: OutputTypeYAML, ext: ".yaml", want: "target: ../lib/a&b.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outputType), func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "output"+tt.ext)
+			gen, err := NewOutputGenerator(&MixOptions{
+				OutputPath:    outputPath,
+				OutputType:    tt.outputType,
+				MaxOutputSize: 1024,
+			})
+			require.NoError(t, err)
+			require.NoError(t, gen.Generate(contents))
+
+			data, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), tt.want)
+		})
+	}
+}
+
+func TestGenerateProvenance(t *testing.T) {
+	contents := []FileContent{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12}}
+	provenance := &Provenance{
+		Tool:        "filefusion",
+		Version:     "v1.2.3",
+		GeneratedAt: "2023-11-14T22:13:20Z",
+		Options:     []ProvenanceOption{{Name: "format", Value: "xml"}},
+		ConfigHash:  "sha256:abc",
+	}
+
+	tests := []struct {
+		outputType OutputType
+		ext        string
+		want       []string
+	}{
+		{outputType: OutputTypeXML, ext: ".xml", want: []string{
+			"<documents generator=\"filefusion\">\n<provenance>",
+			"<version>v1.2.3</version>",
+			`<option name="format" value="xml"></option>`,
+			"<config_hash>sha256:abc</config_hash>",
+		}},
+		{outputType: OutputTypeJSON, ext: ".json", want: []string{
+			"\"generator\": \"filefusion\",\n  \"provenance\": {",
+			`"generated_at": "2023-11-14T22:13:20Z"`,
+			`"config_hash": "sha256:abc"`,
+		}},
+		{outputType: OutputTypeYAML, ext: ".yaml", want: []string{
+			"generator: filefusion\nprovenance:",
+			"version: v1.2.3",
+			"config_hash: sha256:abc",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outputType), func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "output"+tt.ext)
+			gen, err := NewOutputGenerator(&MixOptions{
+				OutputPath:    outputPath,
+				OutputType:    tt.outputType,
+				MaxOutputSize: 1024,
+				Provenance:    provenance,
+			})
+			require.NoError(t, err)
+			require.NoError(t, gen.Generate(contents))
+
+			data, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(data), want)
+			}
+			assert.NotContains(t, string(data), "commit", "an empty commit is left out")
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Oversize selects what happens to files larger than the per-file size limit
+type Oversize string
+
+const (
+	OversizeSkip     Oversize = "skip"     // Leave the file out
+	OversizeTruncate Oversize = "truncate" // Keep the first and last lines
+	OversizeSkeleton Oversize = "skeleton" // Keep the declarations, truncating unsupported languages
+)
+
+// Default number of lines kept at the start and end of truncated files
+const (
+	DefaultOversizeHead = 200
+	DefaultOversizeTail = 50
+)
+
+// ParseOversize validates an --oversize value
+func ParseOversize(value string) (Oversize, error) {
+	switch Oversize(strings.ToLower(value)) {
+	case OversizeSkip, "":
+		return OversizeSkip, nil
+	case OversizeTruncate:
+		return OversizeTruncate, nil
+	case OversizeSkeleton:
+		return OversizeSkeleton, nil
+	default:
+		return "", fmt.Errorf("invalid oversize mode %q: must be skip, truncate or skeleton", value)
+	}
+}
+
+// Room kept for the truncation marker when the kept lines are capped in bytes
+const markerReserve = 64
+
+// lineCutMarker ends a line that was cut to fit the size limit
+const lineCutMarker = " ... [line truncated]\n"
+
+// readHeadTail reads the first head and last tail lines of a file without
+// holding the rest in memory. The lines in between are replaced by a marker.
+// With a positive maxBytes, the result is kept within that many bytes, and
+// lines too long to fit are cut. It returns the number of lines left out and
+// whether anything was left out or cut.
+func readHeadTail(path string, head, tail int, maxBytes int64) (string, int, bool, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return "", 0, false, err
+	}
+	defer f.Close()
+	return headTail(f, head, tail, maxBytes)
+}
+
+// headTail keeps the first head and last tail lines read from r, see
+// readHeadTail
+func headTail(r io.Reader, head, tail int, maxBytes int64) (string, int, bool, error) {
+	// Split the byte budget between the head and the tail by their lines
+	headBudget, tailBudget := math.MaxInt/2, math.MaxInt/2
+	if maxBytes > 0 && head+tail > 0 {
+		budget := max(int(maxBytes)-markerReserve, 2*len(lineCutMarker))
+		headBudget = budget * head / (head + tail)
+		tailBudget = budget - headBudget
+	}
+
+	var headLines, tailLines []string
+	headBytes, tailBytes := 0, 0
+	headFull := head == 0
+	total := 0
+	cut := false
+
+	reader := bufio.NewReader(r)
+	for {
+		line, lineCut, err := readLine(reader, max(headBudget, tailBudget))
+		if line != "" {
+			total++
+			// A line that does not fit the rest of the head budget ends the
+			// head, unless it is the first line and is cut to fit instead
+			if !headFull && len(line) > headBudget-headBytes && len(headLines) > 0 {
+				headFull = true
+			}
+			if !headFull {
+				line, lineCut = cutLine(line, headBudget, lineCut)
+				headLines = append(headLines, line)
+				headBytes += len(line)
+				cut = cut || lineCut
+				headFull = lineCut || len(headLines) == head || headBytes >= headBudget
+			} else if tail > 0 {
+				line, lineCut = cutLine(line, tailBudget, lineCut)
+				tailLines = append(tailLines, line)
+				tailBytes += len(line)
+				// Drop the oldest lines until the tail fits its lines and bytes
+				for len(tailLines) > tail || tailBytes > tailBudget {
+					tailBytes -= len(tailLines[0])
+					tailLines = tailLines[1:]
+				}
+				cut = cut || lineCut
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, false, err
+		}
+	}
+	omitted := total - len(headLines) - len(tailLines)
+
+	var b strings.Builder
+	for _, line := range headLines {
+		b.WriteString(line)
+	}
+	if omitted > 0 {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+		b.WriteString(truncationMarker(omitted))
+	}
+	for _, line := range tailLines {
+		b.WriteString(line)
+	}
+	return b.String(), omitted, omitted > 0 || cut, nil
+}
+
+// readLine reads the next line from r, keeping at most limit bytes of it.
+// The rest of a longer line is read and dropped, and the kept part is cut
+// with cutLine. It reports whether the line was cut.
+func readLine(r *bufio.Reader, limit int) (string, bool, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		// Keep one byte over the limit to tell whether the line is cut
+		if room := limit + 1 - len(line); room > 0 {
+			line = append(line, chunk[:min(room, len(chunk))]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		text, cut := cutLine(string(line), limit, false)
+		return text, cut, err
+	}
+}
+
+// cutLine shortens a line longer than limit bytes, ending it with
+// lineCutMarker at a rune boundary. A line that fits is returned as is, with
+// the cut state it already had.
+func cutLine(line string, limit int, cut bool) (string, bool) {
+	if len(line) <= limit {
+		return line, cut
+	}
+	keep := max(limit-len(lineCutMarker), 0)
+	for keep > 0 && !utf8.RuneStart(line[keep]) {
+		keep--
+	}
+	return line[:keep] + lineCutMarker, true
+}
+
+// truncationMarker returns the line that stands in for the omitted lines
+func truncationMarker(omitted int) string {
+	return fmt.Sprintf("... [truncated %s lines] ...\n", formatCount(omitted))
+}
+
+// formatCount formats a number with thousands separators, e.g. 12,345
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
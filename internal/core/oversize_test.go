@@ -0,0 +1,263 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOversize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Oversize
+		wantErr bool
+	}{
+		{value: "", want: OversizeSkip},
+		{value: "skip", want: OversizeSkip},
+		{value: "Truncate", want: OversizeTruncate},
+		{value: "skeleton", want: OversizeSkeleton},
+		{value: "shrink", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseOversize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseOversize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseOversize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// numberedLines returns n lines of the form "line i"
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestReadHeadTail(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		head, tail    int
+		maxBytes      int64
+		want          string
+		wantOmitted   int
+		wantTruncated bool
+	}{
+		{
+			name:          "head and tail",
+			content:       numberedLines(10),
+			head:          2,
+			tail:          3,
+			want:          "line 1\nline 2\n... [truncated 5 lines] ...\nline 8\nline 9\nline 10\n",
+			wantOmitted:   5,
+			wantTruncated: true,
+		},
+		{
+			name:          "head only",
+			content:       numberedLines(5),
+			head:          1,
+			want:          "line 1\n... [truncated 4 lines] ...\n",
+			wantOmitted:   4,
+			wantTruncated: true,
+		},
+		{
+			name:          "tail only",
+			content:       numberedLines(5),
+			tail:          1,
+			want:          "... [truncated 4 lines] ...\nline 5\n",
+			wantOmitted:   4,
+			wantTruncated: true,
+		},
+		{
+			name:    "short file is kept",
+			content: numberedLines(4),
+			head:    2,
+			tail:    2,
+			want:    numberedLines(4),
+		},
+		{
+			name:          "no trailing newline",
+			content:       "a\nb\nc\nd",
+			head:          1,
+			tail:          1,
+			want:          "a\n... [truncated 2 lines] ...\nd",
+			wantOmitted:   2,
+			wantTruncated: true,
+		},
+		{
+			name:          "thousands separator",
+			content:       numberedLines(12347),
+			head:          1,
+			tail:          1,
+			want:          "line 1\n... [truncated 12,345 lines] ...\nline 12347\n",
+			wantOmitted:   12345,
+			wantTruncated: true,
+		},
+		{
+			name:          "long line is cut",
+			content:       strings.Repeat("x", 1000) + "\nend\n",
+			head:          1,
+			tail:          1,
+			maxBytes:      164,
+			want:          strings.Repeat("x", 28) + lineCutMarker + "end\n",
+			wantTruncated: true,
+		},
+		{
+			name:          "tail is kept within the limit",
+			content:       numberedLines(3) + strings.Repeat("y", 1000) + "\n",
+			head:          1,
+			tail:          2,
+			maxBytes:      124,
+			want:          "line 1\n... [truncated 2 lines] ...\n" + strings.Repeat("y", 18) + lineCutMarker,
+			wantOmitted:   2,
+			wantTruncated: true,
+		},
+		{
+			name:          "head stops at the limit",
+			content:       numberedLines(100),
+			head:          50,
+			tail:          50,
+			maxBytes:      100,
+			want:          "line 1\nline 2\nline 3\n... [truncated 95 lines] ...\nline 99\nline 100\n",
+			wantOmitted:   95,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, omitted, truncated, err := readHeadTail(path, tt.head, tt.tail, tt.maxBytes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if omitted != tt.wantOmitted {
+				t.Errorf("omitted = %d, want %d", omitted, tt.wantOmitted)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if tt.maxBytes > 0 && int64(len(got)) > tt.maxBytes {
+				t.Errorf("content of %d bytes exceeds the limit of %d", len(got), tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{
+		0:       "0",
+		999:     "999",
+		1000:    "1,000",
+		12345:   "12,345",
+		1234567: "1,234,567",
+		-4200:   "-4,200",
+	}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestProcessFileOversize(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logPath := filepath.Join(tmpDir, "app.log")
+	if err := os.WriteFile(logPath, []byte(numberedLines(100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goSource := "package main\n\n// Run starts the server\nfunc Run() {\n" + strings.Repeat("\tprintln(\"serving\")\n", 20) + "}\n"
+	goPath := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(goPath, []byte(goSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	longLinePath := filepath.Join(tmpDir, "min.js")
+	if err := os.WriteFile(longLinePath, []byte(strings.Repeat("x", 500)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var decls strings.Builder
+	decls.WriteString("package api\n")
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&decls, "\nfunc Handler%d() {\n\tprintln(%d)\n}\n", i, i)
+	}
+	declsPath := filepath.Join(tmpDir, "api.go")
+	if err := os.WriteFile(declsPath, []byte(decls.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		mode          Oversize
+		path          string
+		wantSkipped   bool
+		wantTruncated bool
+		wantSkeleton  bool
+		wantContains  string
+	}{
+		{name: "skip", mode: OversizeSkip, path: logPath, wantSkipped: true},
+		{name: "truncate log", mode: OversizeTruncate, path: logPath, wantTruncated: true, wantContains: "... [truncated 95 lines] ...\nline 98\n"},
+		{name: "truncate source", mode: OversizeTruncate, path: goPath, wantTruncated: true, wantContains: "[truncated"},
+		{name: "skeleton source", mode: OversizeSkeleton, path: goPath, wantSkeleton: true, wantContains: "func Run() { ... }"},
+		{name: "skeleton falls back to truncation", mode: OversizeSkeleton, path: logPath, wantTruncated: true, wantContains: "[truncated 95 lines]"},
+		{name: "skeleton over the limit is truncated", mode: OversizeSkeleton, path: declsPath, wantSkeleton: true, wantTruncated: true, wantContains: "[truncated"},
+		{name: "single long line", mode: OversizeTruncate, path: longLinePath, wantTruncated: true, wantContains: lineCutMarker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewFileProcessor(&MixOptions{
+				InputPath:    tmpDir,
+				MaxFileSize:  200,
+				Oversize:     tt.mode,
+				OversizeHead: 2,
+				OversizeTail: 3,
+			})
+
+			result := processor.processFile(tt.path)
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if (result.Skipped != nil) != tt.wantSkipped {
+				t.Fatalf("skipped = %v, want %v", result.Skipped, tt.wantSkipped)
+			}
+			if tt.wantSkipped {
+				if result.Skipped.Status != FileStatusSkippedSize {
+					t.Errorf("status = %s, want %s", result.Skipped.Status, FileStatusSkippedSize)
+				}
+				return
+			}
+
+			content := result.Content
+			if content.Size > 200 || len(content.Content) > 200 {
+				t.Errorf("size = %d, want at most the limit of 200", len(content.Content))
+			}
+			if content.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", content.Truncated, tt.wantTruncated)
+			}
+			if content.Skeleton != tt.wantSkeleton {
+				t.Errorf("skeleton = %v, want %v", content.Skeleton, tt.wantSkeleton)
+			}
+			if !strings.Contains(content.Content, tt.wantContains) {
+				t.Errorf("content %q does not contain %q", content.Content, tt.wantContains)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -31,8 +32,7 @@ func NewPatternValidator() *PatternValidator {
 		maxPatternLen:  1000, // Reasonable default
 		allowedSymbols: []rune{'*', '?', '[', ']', '{', '}', ',', '!', '/'},
 		bannedPatterns: []string{
-			"/**/../",  // Prevent complex traversal
-			"**/.*/**", // prevent hidden files
+			"/**/../", // Prevent complex traversal
 		},
 	}
 }
@@ -366,3 +366,196 @@ func (v *PatternValidator) ExpandPattern(pattern string) ([]string, error) {
 
 	return result, nil
 }
+
+// patternRule is one entry of the ordered list of include and exclude rules
+// applied by FileFinder. Rules are matched against paths relative to the
+// input root, and the last rule matching a file decides whether it is kept.
+type patternRule struct {
+	pattern string // Pattern as given, reported for excluded files
+	glob    string // Glob matched against the basename or the relative path
+	exclude bool   // Whether a match leaves the file out
+	anyPath bool   // Glob has no slash and matches the basename at any depth
+}
+
+// newPatternRule compiles a pattern in gitignore style. A leading "!" inverts
+// the rule, a leading "/" anchors it to the input root, and a trailing "/"
+// matches everything below directories of that name. Patterns without a slash
+// match the file name at any depth, others match the path from the root.
+func newPatternRule(pattern string, exclude bool) patternRule {
+	rule := patternRule{pattern: pattern, exclude: exclude}
+
+	glob := pattern
+	if strings.HasPrefix(glob, "!") {
+		glob = glob[1:]
+		rule.exclude = !exclude
+	}
+
+	anchored := strings.HasPrefix(glob, "/")
+	glob = strings.TrimPrefix(glob, "/")
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+		if !anchored && !strings.Contains(strings.TrimSuffix(glob, "/**"), "/") {
+			glob = "**/" + glob
+		}
+	}
+
+	rule.glob = glob
+	rule.anyPath = !anchored && !strings.Contains(glob, "/")
+	return rule
+}
+
+// ruleSet is the compiled, ordered list of include and exclude rules. Rules
+// of the common forms "*.ext" and "name" are looked up by file name instead
+// of being matched one by one, so a file costs a few map lookups plus a match
+// against each of the remaining globs.
+type ruleSet struct {
+	rules      []patternRule
+	includeAll bool           // Whether files no rule matches are included
+	bySuffix   map[string]int // Suffix of "*.ext" rules to the index of the last such rule
+	byName     map[string]int // File name of literal rules to the index of the last such rule
+	globs      []int          // Indices of the other rules, in order
+}
+
+// compileRules builds the ordered rule list from the include patterns followed
+// by the exclude patterns. A "!" prefix turns an include into an exclusion and
+// an exclusion into a re-inclusion.
+func compileRules(includes, excludes []string) *ruleSet {
+	set := &ruleSet{
+		includeAll: true,
+		bySuffix:   make(map[string]int),
+		byName:     make(map[string]int),
+	}
+	for _, pattern := range includes {
+		set.rules = append(set.rules, newPatternRule(pattern, false))
+		if !strings.HasPrefix(pattern, "!") {
+			set.includeAll = false
+		}
+	}
+	for _, pattern := range excludes {
+		set.rules = append(set.rules, newPatternRule(pattern, true))
+	}
+
+	for i, rule := range set.rules {
+		switch {
+		case rule.anyPath && strings.HasPrefix(rule.glob, "*.") && !hasGlobMeta(rule.glob[1:]):
+			set.bySuffix[rule.glob[1:]] = i
+		case rule.anyPath && !hasGlobMeta(rule.glob):
+			set.byName[rule.glob] = i
+		default:
+			set.globs = append(set.globs, i)
+		}
+	}
+	return set
+}
+
+// hasGlobMeta reports whether a pattern contains characters with a special
+// meaning in globs
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[]{}\\")
+}
+
+// lastMatch returns the last rule matching a slash-separated path relative to
+// the input root, or nil when no rule matches
+func (s *ruleSet) lastMatch(rel string) (*patternRule, error) {
+	best := -1
+	base := path.Base(rel)
+	if i, ok := s.byName[base]; ok {
+		best = i
+	}
+	for j := 0; j < len(base); j++ {
+		if base[j] != '.' {
+			continue
+		}
+		if i, ok := s.bySuffix[base[j:]]; ok && i > best {
+			best = i
+		}
+	}
+
+	// Only globs after the best lookup match can change the outcome
+	for j := len(s.globs) - 1; j >= 0 && s.globs[j] > best; j-- {
+		rule := s.rules[s.globs[j]]
+		matched, err := rule.match(rel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", rule.pattern, err)
+		}
+		if matched {
+			best = s.globs[j]
+			break
+		}
+	}
+
+	if best < 0 {
+		return nil, nil
+	}
+	return &s.rules[best], nil
+}
+
+// pruningRule returns the exclude rule that provably leaves out every file
+// below the directory rel, meaning no later rule can include any of them. A
+// nil rule with true is returned when no include rule can match below it.
+func (s *ruleSet) pruningRule(dir string) (*patternRule, bool) {
+	for i := len(s.rules) - 1; i >= 0; i-- {
+		rule := &s.rules[i]
+		if rule.exclude && rule.coversDir(dir) {
+			return rule, true
+		}
+		if !rule.exclude && rule.mayMatchBelow(dir) {
+			return nil, false
+		}
+	}
+	return nil, !s.includeAll
+}
+
+// match reports whether the rule matches a slash-separated path relative to
+// the input root
+func (r patternRule) match(rel string) (bool, error) {
+	if r.anyPath {
+		return doublestar.Match(r.glob, path.Base(rel))
+	}
+	return doublestar.Match(r.glob, rel)
+}
+
+// coversDir reports whether the rule matches every file below the directory
+// dir, which holds for "prefix/**" globs whose prefix matches the directory
+// or one of its parents
+func (r patternRule) coversDir(dir string) bool {
+	if r.glob == "*" || r.glob == "**" {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(r.glob, "/**")
+	if !ok || r.anyPath {
+		return false
+	}
+	for {
+		if matched, _ := doublestar.Match(prefix, dir); matched {
+			return true
+		}
+		i := strings.LastIndex(dir, "/")
+		if i < 0 {
+			return false
+		}
+		dir = dir[:i]
+	}
+}
+
+// mayMatchBelow reports whether the rule could match a file below the
+// directory dir. It compares the leading segments of path globs with the
+// directory and answers true whenever it cannot rule a match out.
+func (r patternRule) mayMatchBelow(dir string) bool {
+	if r.anyPath || strings.ContainsAny(r.glob, "{\\") {
+		return true
+	}
+	segments := strings.Split(r.glob, "/")
+	for i, name := range strings.Split(dir, "/") {
+		if segments[i] == "**" {
+			return true
+		}
+		if i >= len(segments)-1 {
+			return false // The glob ends at or above this directory
+		}
+		if matched, _ := doublestar.Match(segments[i], name); !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -49,10 +49,14 @@ func TestPatternValidator_ValidatePattern(t *testing.T) {
 			errMsg:  "contains banned pattern",
 		},
 		{
-			name:    "hidden files",
+			name:    "hidden directories",
 			pattern: "**/.*/**",
-			wantErr: true,
-			errMsg:  "contains banned pattern",
+			wantErr: false,
+		},
+		{
+			name:    "hidden directory path",
+			pattern: ".github/workflows/*.yml",
+			wantErr: false,
 		},
 
 		// Brace validation
@@ -330,3 +334,72 @@ func TestPatternValidator_ExpandPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleSetLastMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		path     string
+		want     string // Pattern of the deciding rule, empty for none
+	}{
+		{name: "suffix lookup", includes: []string{"*.go"}, path: "a/b.go", want: "*.go"},
+		{name: "multi-part suffix", includes: []string{"*.gz", "*.tar.gz"}, path: "x.tar.gz", want: "*.tar.gz"},
+		{name: "name lookup", includes: []string{"*.go", "Makefile"}, path: "build/Makefile", want: "Makefile"},
+		{name: "hidden file", includes: []string{"*.env"}, path: ".env", want: "*.env"},
+		{name: "later glob wins over lookup", includes: []string{"*.go"}, excludes: []string{"**/gen/**"}, path: "pkg/gen/a.go", want: "**/gen/**"},
+		{name: "later lookup wins over glob", includes: []string{"src/**"}, excludes: []string{"*.log"}, path: "src/a.log", want: "*.log"},
+		{name: "earlier glob loses to lookup", includes: []string{"src/**", "*.go"}, path: "src/a.go", want: "*.go"},
+		{name: "no match", includes: []string{"*.go"}, path: "a.py", want: ""},
+		{name: "suffix needs the dot", includes: []string{"*.go"}, path: "cargo", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileRules(tt.includes, tt.excludes).lastMatch(tt.path)
+			if err != nil {
+				t.Fatalf("lastMatch() error = %v", err)
+			}
+			got := ""
+			if rule != nil {
+				got = rule.pattern
+			}
+			if got != tt.want {
+				t.Errorf("lastMatch(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSetPruningRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		dir      string
+		want     bool
+	}{
+		{name: "excluded directory", includes: []string{"*.go"}, excludes: []string{"node_modules/**"}, dir: "node_modules", want: true},
+		{name: "below excluded directory", includes: []string{"*.go"}, excludes: []string{"node_modules/**"}, dir: "node_modules/lib", want: true},
+		{name: "excluded at any depth", includes: []string{"*.go"}, excludes: []string{"**/node_modules/**"}, dir: "web/node_modules", want: true},
+		{name: "trailing slash", includes: []string{"*.go"}, excludes: []string{"vendor/"}, dir: "a/vendor", want: true},
+		{name: "other directory", includes: []string{"*.go"}, excludes: []string{"node_modules/**"}, dir: "src", want: false},
+		{name: "anchored exclusion elsewhere", includes: []string{"*.go"}, excludes: []string{"/build/**"}, dir: "src/build", want: false},
+		{name: "file exclusion", includes: []string{"*.go"}, excludes: []string{"*.log"}, dir: "logs", want: false},
+		{name: "later re-include", includes: []string{"*.go"}, excludes: []string{"gen/**", "!gen/api/**"}, dir: "gen", want: false},
+		{name: "re-include elsewhere", includes: []string{"*.go"}, excludes: []string{"gen/**", "!gen/api/**"}, dir: "gen/web", want: true},
+		{name: "re-include by name", includes: []string{"*.go"}, excludes: []string{"gen/**", "!*.proto"}, dir: "gen", want: false},
+		{name: "no include below", includes: []string{"src/**/*.go"}, dir: "docs", want: true},
+		{name: "include below", includes: []string{"src/**/*.go"}, dir: "src/a/b", want: false},
+		{name: "include too shallow", includes: []string{"src/*.go"}, dir: "src/a", want: true},
+		{name: "no rules", dir: "anything", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := compileRules(tt.includes, tt.excludes).pruningRule(tt.dir); got != tt.want {
+				t.Errorf("pruningRule(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,52 +1,68 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/drgsn/filefusion/internal/core/cleaner"
 )
 
 // FileResult represents the outcome of processing a single file.
-// It can contain either the processed content or an error, but not both.
+// It contains exactly one of the processed content, an error, or a skip record.
 type FileResult struct {
-	Content FileContent // Processed file content and metadata
-	Error   error       // Error that occurred during processing, if any
+	Content FileContent  // Processed file content and metadata
+	Error   error        // Error that occurred during processing, if any
+	Skipped *ReportEntry // Set when the file was deliberately left out
 }
 
-// FileProcessor handles the concurrent processing of multiple files,
-// including content cleaning when enabled. It manages a pool of cleaners
-// for different languages and ensures proper resource cleanup.
+// FileProcessor handles the concurrent processing of multiple files, passing
+// each one through the transformer pipeline. It shares one cleaner per
+// language between its workers.
 type FileProcessor struct {
 	options  *MixOptions
-	cleaners map[cleaner.Language]*cleaner.Cleaner
+	detector *LanguageDetector
+	cleaner  *CleanerTransformer
+	skipped  []ReportEntry
 	mu       sync.RWMutex
 }
 
 // NewFileProcessor creates a new FileProcessor instance with the specified options.
-// It initializes the cleaner map if cleaning is enabled but defers actual cleaner
-// creation until needed.
+// Cleaners are created when first needed, with the default options when they
+// are only used for skeleton mode.
 func NewFileProcessor(options *MixOptions) *FileProcessor {
+	cleanerOptions := options.CleanerOptions
+	if cleanerOptions == nil {
+		cleanerOptions = cleaner.DefaultOptions()
+	}
 	return &FileProcessor{
 		options:  options,
-		cleaners: make(map[cleaner.Language]*cleaner.Cleaner),
+		detector: &LanguageDetector{Mappings: options.LanguageMap, Roots: options.InputRoots},
+		cleaner:  NewCleanerTransformer(cleanerOptions),
 	}
 }
 
 // ProcessFiles processes multiple files concurrently using a worker pool pattern.
 // It respects file size limits and handles errors gracefully, continuing to process
-// files even if some fail.
+// files even if some fail, unless FailFast is set.
 //
 // Parameters:
 //   - paths: Slice of file paths to process
 //
 // Returns:
 //   - []FileContent: Slice of successfully processed file contents
-//   - error: First error encountered during processing, if any
+//   - error: Every file that failed as a *MultiError, if any
 func (p *FileProcessor) ProcessFiles(paths []string) ([]FileContent, error) {
+	// Cancelled on the first failure with FailFast, which stops the workers
+	// from starting on further files
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Use reasonable number of workers
 	numWorkers := min(len(paths), 10)
 	results := make(chan FileResult, len(paths))
@@ -65,7 +81,13 @@ func (p *FileProcessor) ProcessFiles(paths []string) ([]FileContent, error) {
 		go func() {
 			defer wg.Done()
 			for path := range jobs {
-				result := p.processFile(path)
+				if ctx.Err() != nil {
+					return
+				}
+				result := p.traceFile(ctx, path, nil)
+				if result.Error != nil {
+					result.Error = &FileError{Path: path, Stage: StageProcess, Err: result.Error}
+				}
 				results <- result
 			}
 		}()
@@ -79,32 +101,49 @@ func (p *FileProcessor) ProcessFiles(paths []string) ([]FileContent, error) {
 
 	// Collect results and handle errors
 	var contents []FileContent
-	var errors []error
+	var errs MultiError
 
 	for result := range results {
 		if result.Error != nil {
-			errors = append(errors, result.Error)
+			errs.Add(StageProcess, result.Error)
+			if p.options.FailFast {
+				cancel()
+			}
 			continue
 		}
-		if result.Content.Size > 0 {
+		if result.Skipped != nil {
+			p.mu.Lock()
+			p.skipped = append(p.skipped, *result.Skipped)
+			p.mu.Unlock()
+			continue
+		}
+		if result.Content.Size > 0 || result.Content.Target != "" {
 			contents = append(contents, result.Content)
 		}
 	}
 
-	// Return the first error encountered, but still return processed files
-	var firstError error
-	if len(errors) > 0 {
-		firstError = errors[0]
-	}
-
-	return contents, firstError
+	// Return every failure, but still return processed files
+	return contents, errs.Err()
 }
 
 // processFile handles the processing of a single file, including reading,
 // cleaning (if enabled), and metadata collection.
 func (p *FileProcessor) processFile(path string) FileResult {
+	return p.traceFile(context.Background(), path, nil)
+}
+
+// traceFile processes a file like processFile and passes the detected
+// language and the outcome of every transformer to trace, when set
+func (p *FileProcessor) traceFile(ctx context.Context, path string, trace func(ExplainStep)) FileResult {
+	// Links are recorded with their target instead of being read
+	if p.options.Symlinks == SymlinkRecord {
+		if target, ok := linkTarget(path); ok {
+			return FileResult{Content: p.linkContent(path, target)}
+		}
+	}
+
 	// Get file info and perform initial checks
-	info, err := os.Stat(path)
+	info, err := statFile(path)
 	if err != nil {
 		return FileResult{
 			Error: &MixError{
@@ -123,15 +162,28 @@ func (p *FileProcessor) processFile(path string) FileResult {
 		}
 	}
 
-	// Check size limit
-	if info.Size() > p.options.MaxFileSize {
-		fmt.Fprintf(os.Stderr, "Warning: Skipping %s (size %d bytes exceeds limit %d bytes)\n",
-			path, info.Size(), p.options.MaxFileSize)
-		return FileResult{}
-	}
+	// Read file content, reducing files over the size limit if requested
+	var content []byte
+	truncated, skeleton := false, false
+	// Files narrowed by a selector are read whole, the selection keeps their
+	// line numbers intact and the size limit applies to the selected lines
+	selection := p.selection(path)
+	if info.Size() > p.options.MaxFileSize && selection == nil {
+		reason := fmt.Sprintf("size %d bytes exceeds limit %d bytes", info.Size(), p.options.MaxFileSize)
+		if p.options.Oversize != OversizeTruncate && p.options.Oversize != OversizeSkeleton {
+			logEvent(slog.LevelWarn, EventFileSkipped, path, reason)
+			return FileResult{Skipped: sizeSkipped(path, reason, info.Size())}
+		}
 
-	// Read file content
-	content, err := os.ReadFile(path)
+		content, skeleton, truncated, err = p.readOversized(path)
+		if err == nil && !skeleton && !truncated {
+			reason += ", and it has too few lines to truncate"
+			logEvent(slog.LevelWarn, EventFileSkipped, path, reason)
+			return FileResult{Skipped: sizeSkipped(path, reason, info.Size())}
+		}
+	} else {
+		content, err = readFile(path)
+	}
 	if err != nil {
 		return FileResult{
 			Error: &MixError{
@@ -141,90 +193,233 @@ func (p *FileProcessor) processFile(path string) FileResult {
 		}
 	}
 
-	// Clean content if enabled and language is supported
-	if p.options.CleanerOptions != nil {
-		cleaned, err := p.cleanContent(path, content)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to clean %s: %v\n", path, err)
-			// Continue with original content instead of failing
-		} else {
-			content = cleaned
+	// Skip binary files if requested, they are of no use in a text bundle
+	if p.options.SkipBinary && isBinaryContent(content) {
+		logEvent(slog.LevelInfo, EventFileSkipped, path, "file appears to be binary")
+		return FileResult{
+			Skipped: &ReportEntry{
+				Path:   path,
+				Status: FileStatusSkippedBinary,
+				Reason: "file appears to be binary",
+				Size:   info.Size(),
+			},
 		}
 	}
 
-	// Create relative path
+	// Create relative path, files of an input source are relative to its root
+	var origin string
 	relPath, err := p.createRelativePath(path)
 	if err != nil {
 		relPath = path
 	}
+	if src, inner, ok := splitSourcePath(path); ok {
+		relPath, origin = inner, src.Name
+	}
 
-	// Return successful result
-	return FileResult{
-		Content: FileContent{
-			Path:      filepath.ToSlash(relPath),
-			Name:      filepath.Base(path),
-			Extension: strings.TrimPrefix(filepath.Ext(path), "."),
-			Content:   string(content),
-			Size:      int64(len(content)),
-		},
+	lang, detectedBy := p.detector.detect(path, content)
+
+	// Narrow the file to the lines and symbols selected on its input path
+	var lines string
+	if selection != nil {
+		selected, ranges, err := selection.Apply(path, content, lang)
+		if err != nil {
+			return FileResult{
+				Error: &MixError{
+					File:    path,
+					Message: fmt.Sprintf("error applying selector: %v", err),
+				},
+			}
+		}
+		content, lines = []byte(selected), FormatLineRanges(ranges)
+		if size := int64(len(content)); size > p.options.MaxFileSize {
+			reason := fmt.Sprintf("selected lines %s of %d bytes exceed limit %d bytes", lines, size, p.options.MaxFileSize)
+			logEvent(slog.LevelWarn, EventFileSkipped, path, reason)
+			return FileResult{Skipped: sizeSkipped(path, reason, size)}
+		}
+		if trace != nil {
+			trace(ExplainStep{Stage: "selector", Result: "applied", Detail: "lines " + lines})
+		}
+	}
+	file := FileContent{
+		Path:         filepath.ToSlash(relPath),
+		Name:         filepath.Base(path),
+		Extension:    strings.TrimPrefix(filepath.Ext(path), "."),
+		Content:      string(content),
+		Size:         int64(len(content)),
+		OriginalSize: int64(len(content)),
+		Language:     string(lang),
+		Truncated:    truncated,
+		Origin:       origin,
+		Lines:        lines,
+		SourcePath:   path,
+		RootPath:     p.rootPath(path),
 	}
-}
 
-// cleanContent attempts to clean the content using the appropriate language cleaner
-func (p *FileProcessor) cleanContent(path string, content []byte) ([]byte, error) {
-	// Add defer/recover to prevent panics from crashing goroutines
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "Recovered from panic in cleanContent for %s: %v\n", path, r)
+	if trace != nil {
+		if lang != "" {
+			trace(ExplainStep{Stage: "language", Result: string(lang), Detail: "detected from the " + detectedBy})
+		} else {
+			trace(ExplainStep{Stage: "language", Result: "none", Detail: "not recognized, the file is kept as text"})
 		}
-	}()
+		if p.options.CleanerOptions == nil && !hasCleaner(p.options.Transformers) {
+			trace(ExplainStep{Stage: "clean", Result: "disabled", Detail: "enable with --clean"})
+		}
+	}
 
-	lang := p.detectLanguage(path)
-	if lang == "" {
-		return content, nil
+	result := p.transformers(path, skeleton).apply(ctx, file, trace)
+	if skeleton {
+		result = p.capSkeleton(result)
 	}
 
-	c, err := p.getOrCreateCleaner(lang)
+	// Return successful result
+	return FileResult{Content: result}
+}
+
+// selection returns the selection given on the input path of a file, if any
+func (p *FileProcessor) selection(path string) *Selection {
+	if len(p.options.Selections) == 0 {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cleaner: %w", err)
+		return nil
+	}
+	return p.options.Selections[absPath]
+}
+
+// readOversized reads a file over the size limit according to the oversize
+// mode. In skeleton mode, files in a language with skeleton support are read
+// whole to be reduced to their declarations. Other files are truncated to
+// their first and last lines.
+func (p *FileProcessor) readOversized(path string) (content []byte, skeleton, truncated bool, err error) {
+	if p.options.Oversize == OversizeSkeleton {
+		if lang := p.detectLanguage(path, nil); lang != "" && cleaner.SupportsSkeleton(lang) {
+			content, err = readFile(path)
+			return content, true, false, err
+		}
 	}
 
-	cleaned, err := c.Clean(content)
+	head, tail := p.oversizeLines()
+	text, omitted, truncated, err := readHeadTail(path, head, tail, p.options.MaxFileSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clean content: %w", err)
+		return nil, false, false, err
+	}
+	if truncated {
+		logEvent(slog.LevelInfo, EventFileTruncated, path, fmt.Sprintf("kept the first %d and last %d lines within %s", head, tail, formatSize(p.options.MaxFileSize)), "omitted", omitted)
 	}
+	return []byte(text), false, truncated, nil
+}
 
-	return cleaned, nil
+// oversizeLines returns the number of lines kept at the start and end of
+// truncated files
+func (p *FileProcessor) oversizeLines() (head, tail int) {
+	if p.options.OversizeHead == 0 && p.options.OversizeTail == 0 {
+		return DefaultOversizeHead, DefaultOversizeTail
+	}
+	return p.options.OversizeHead, p.options.OversizeTail
 }
 
-// getOrCreateCleaner safely gets or creates a cleaner for the given language
-func (p *FileProcessor) getOrCreateCleaner(lang cleaner.Language) (*cleaner.Cleaner, error) {
-	// Try to get existing cleaner
+// capSkeleton truncates the skeleton of an oversized file that is still over
+// the size limit, so oversized files never take more than the limit
+func (p *FileProcessor) capSkeleton(file FileContent) FileContent {
+	if file.Size <= p.options.MaxFileSize {
+		return file
+	}
+	head, tail := p.oversizeLines()
+	text, omitted, _, _ := headTail(strings.NewReader(file.Content), head, tail, p.options.MaxFileSize)
+	logEvent(slog.LevelInfo, EventFileTruncated, file.Path, fmt.Sprintf("skeleton of %s exceeds limit of %s", formatSize(file.Size), formatSize(p.options.MaxFileSize)), "omitted", omitted)
+	file.Content, file.Size, file.Truncated = text, int64(len(text)), true
+	return file
+}
+
+// sizeSkipped returns the report entry for a file left out for its size
+func sizeSkipped(path, reason string, size int64) *ReportEntry {
+	return &ReportEntry{
+		Path:   path,
+		Status: FileStatusSkippedSize,
+		Reason: reason,
+		Size:   size,
+	}
+}
+
+// SkippedFiles returns the files that were deliberately left out by the most
+// recent calls to ProcessFiles, for example because they were binary
+func (p *FileProcessor) SkippedFiles() []ReportEntry {
 	p.mu.RLock()
-	c, exists := p.cleaners[lang]
-	p.mu.RUnlock()
+	defer p.mu.RUnlock()
+	return append([]ReportEntry(nil), p.skipped...)
+}
 
-	if exists {
-		return c, nil
+// transformers returns the pipeline for a file: notebook and document
+// conversion, cleaning and skeleton mode, followed by the configured transformers.
+// The skeleton step is forced for oversized files in skeleton mode.
+func (p *FileProcessor) transformers(path string, skeleton bool) TransformChain {
+	var chain TransformChain
+	if p.options.MarkupOptions != nil {
+		chain = append(chain, &MarkupTransformer{Options: p.options.MarkupOptions})
+	}
+	if p.options.CleanerOptions != nil {
+		chain = append(chain, p.cleaner)
 	}
+	if skeleton || (p.options.Skeleton && !p.isFullFile(path)) {
+		chain = append(chain, &skeletonTransformer{cleaner: p.cleaner})
+	}
+	return append(chain, p.options.Transformers...)
+}
 
-	// Create new cleaner if needed
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// skeletonTransformer reduces files to their declarations using the language cleaner
+type skeletonTransformer struct {
+	cleaner *CleanerTransformer
+}
+
+func (t *skeletonTransformer) Match(file FileContent) bool {
+	return file.Language != ""
+}
+
+func (t *skeletonTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	lang := cleaner.Language(file.Language)
+	if file.Content != "" && cleaner.SupportsSkeleton(lang) {
+		c, err := t.cleaner.cleaner(lang)
+		if err != nil {
+			return file, fmt.Errorf("failed to create cleaner: %w", err)
+		}
 
-	// Check again in case another goroutine created it
-	if c, exists = p.cleaners[lang]; exists {
-		return c, nil
+		skeleton, err := c.Skeleton([]byte(file.Content))
+		if err != nil {
+			return file, fmt.Errorf("failed to build skeleton: %w", err)
+		}
+		file.Content = string(skeleton)
 	}
+	file.Skeleton = true
+	return file, nil
+}
 
-	// Create new cleaner
-	c, err := cleaner.NewCleaner(lang, p.options.CleanerOptions)
-	if err != nil {
-		return nil, err
+func (t *skeletonTransformer) String() string {
+	return "skeleton"
+}
+
+// isFullFile reports whether the file matches the full patterns and should be
+// kept whole in skeleton mode. Patterns are read like --pattern, relative to
+// the input root containing the file.
+func (p *FileProcessor) isFullFile(path string) bool {
+	if len(p.options.FullPatterns) == 0 {
+		return false
 	}
+	return matchPatterns(p.options.FullPatterns, p.rootPath(path))
+}
 
-	p.cleaners[lang] = c
-	return c, nil
+// getOrCreateCleaner returns the shared cleaner for the given language
+func (p *FileProcessor) getOrCreateCleaner(lang cleaner.Language) (*cleaner.Cleaner, error) {
+	return p.cleaner.cleaner(lang)
+}
+
+// rootPath returns the slash-separated path of a file below the input root
+// containing it, or its cleaned path when no root contains it
+func (p *FileProcessor) rootPath(path string) string {
+	if rel, ok := rootRelative(path, p.options.InputRoots); ok {
+		return rel
+	}
+	return filepath.ToSlash(filepath.Clean(path))
 }
 
 // createRelativePath creates a path relative to the input directory
@@ -246,42 +441,46 @@ func (p *FileProcessor) createRelativePath(path string) (string, error) {
 	return relPath, nil
 }
 
-// detectLanguage determines the language based on file extension
-func (p *FileProcessor) detectLanguage(path string) cleaner.Language {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".go":
-		return cleaner.LangGo
-	case ".java":
-		return cleaner.LangJava
-	case ".py":
-		return cleaner.LangPython
-	case ".js":
-		return cleaner.LangJavaScript
-	case ".ts":
-		return cleaner.LangTypeScript
-	case ".html":
-		return cleaner.LangHTML
-	case ".css":
-		return cleaner.LangCSS
-	case ".cpp", ".cc", ".h":
-		return cleaner.LangCPP
-	case ".cs":
-		return cleaner.LangCSharp
-	case ".php":
-		return cleaner.LangPHP
-	case ".rb":
-		return cleaner.LangRuby
-	case ".sh", ".bash":
-		return cleaner.LangBash
-	case ".swift":
-		return cleaner.LangSwift
-	case ".kt":
-		return cleaner.LangKotlin
-	case ".sql":
-		return cleaner.LangSQL
-	}
-	return ""
+// detectLanguage determines the language of a file from the user mappings,
+// its name and its content
+func (p *FileProcessor) detectLanguage(path string, content []byte) cleaner.Language {
+	return p.detector.Detect(path, content)
+}
+
+// isBinaryContent reports whether content looks like binary data rather than text.
+// It samples the start of the file like git does: a NUL byte marks it as binary.
+// Otherwise it is binary when a significant share of the sample is control
+// characters or bytes that are not valid UTF-8, which catches compressed and
+// random data while text in a legacy encoding with a few accents is accepted.
+func isBinaryContent(content []byte) bool {
+	const sampleSize = 8000
+	sample := content
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	if len(sample) == 0 {
+		return false
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+
+	var suspicious int
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// A rune cut off by the end of the sample is not counted
+			if len(sample) == sampleSize && !utf8.FullRune(sample[i:]) {
+				return suspicious*10 > len(sample)*3
+			}
+			suspicious++
+		case r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != '\b' && r != 0x1b:
+			suspicious++
+		}
+		i += size
+	}
+	return suspicious*10 > len(sample)*3
 }
 
 // min returns the smaller of two integers.
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,6 +14,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/drgsn/filefusion/internal/core/cleaner"
+	"github.com/drgsn/filefusion/internal/core/markup"
 )
 
 func normalizeWhitespace(s string) string {
@@ -320,11 +323,23 @@ func TestLanguageDetection(t *testing.T) {
 		{"test.py", cleaner.LangPython},
 		{"test.js", cleaner.LangJavaScript},
 		{"test.ts", cleaner.LangTypeScript},
+		{"test.tsx", cleaner.LangTSX},
+		{"test.jsx", cleaner.LangJavaScript},
+		{"test.mjs", cleaner.LangJavaScript},
+		{"test.cjs", cleaner.LangJavaScript},
+		{"test.mts", cleaner.LangTypeScript},
+		{"test.hpp", cleaner.LangCPP},
+		{"test.cxx", cleaner.LangCPP},
+		{"test.hh", cleaner.LangCPP},
+		{"test.kts", cleaner.LangKotlin},
+		{"test.pyi", cleaner.LangPython},
+		{"test.zsh", cleaner.LangBash},
 		{"test.html", cleaner.LangHTML},
 		{"test.css", cleaner.LangCSS},
 		{"test.cpp", cleaner.LangCPP},
 		{"test.cc", cleaner.LangCPP},
-		{"test.h", cleaner.LangCPP},
+		{"test.c", cleaner.LangC},
+		{"test.h", cleaner.LangC},
 		{"test.cs", cleaner.LangCSharp},
 		{"test.php", cleaner.LangPHP},
 		{"test.rb", cleaner.LangRuby},
@@ -333,6 +348,14 @@ func TestLanguageDetection(t *testing.T) {
 		{"test.swift", cleaner.LangSwift},
 		{"test.kt", cleaner.LangKotlin},
 		{"test.sql", cleaner.LangSQL},
+		{"test.rs", cleaner.LangRust},
+		{"test.lua", cleaner.LangLua},
+		{"test.scala", cleaner.LangScala},
+		{"test.ex", cleaner.LangElixir},
+		{"test.exs", cleaner.LangElixir},
+		{"test.ml", cleaner.LangOCaml},
+		{"test.yaml", cleaner.LangYAML},
+		{"test.yml", cleaner.LangYAML},
 		{"test.txt", ""},                // Unsupported extension
 		{"test", ""},                    // No extension
 		{"test.JAVA", cleaner.LangJava}, // Test case insensitivity
@@ -340,7 +363,7 @@ func TestLanguageDetection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			got := processor.detectLanguage(tt.path)
+			got := processor.detectLanguage(tt.path, nil)
 			if got != tt.expected {
 				t.Errorf("detectLanguage(%q) = %v, want %v", tt.path, got, tt.expected)
 			}
@@ -855,3 +878,138 @@ func TestProcessFileSymlinks(t *testing.T) {
 		t.Error("Expected error for broken symlink, got none")
 	}
 }
+
+func TestProcessFileSkeleton(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	skeletonPath := filepath.Join(tmpDir, "main.go")
+	fullPath := filepath.Join(tmpDir, "keep", "main.go")
+	textPath := filepath.Join(tmpDir, "notes.txt")
+	for _, path := range []string{skeletonPath, fullPath, textPath} {
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The working directory is not the input root, full patterns are matched
+	// relative to the root
+	processor := NewFileProcessor(&MixOptions{
+		InputRoots:   []string{tmpDir},
+		MaxFileSize:  1024,
+		Skeleton:     true,
+		FullPatterns: []string{"keep/**"},
+	})
+
+	tests := []struct {
+		path         string
+		wantSkeleton bool
+	}{
+		{skeletonPath, true},
+		{fullPath, false},
+		{textPath, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(filepath.Dir(tt.path))+"/"+filepath.Base(tt.path), func(t *testing.T) {
+			result := processor.processFile(tt.path)
+			if result.Error != nil {
+				t.Fatalf("processFile failed: %v", result.Error)
+			}
+			if result.Content.Skeleton != tt.wantSkeleton {
+				t.Errorf("Skeleton = %v, want %v", result.Content.Skeleton, tt.wantSkeleton)
+			}
+			hasBody := strings.Contains(result.Content.Content, "println")
+			if hasBody == tt.wantSkeleton {
+				t.Errorf("Unexpected content:\n%s", result.Content.Content)
+			}
+			if tt.wantSkeleton && result.Content.OriginalSize <= result.Content.Size {
+				t.Errorf("Expected skeleton to be smaller than the original")
+			}
+		})
+	}
+}
+
+func TestProcessFileMarkup(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"analysis.ipynb": `{"nbformat": 4, "metadata": {"language_info": {"name": "python"}}, "cells": [
+			{"cell_type": "code", "source": "print(1)", "outputs": [
+				{"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}}
+			]}
+		]}`,
+		"README.md":    "# Title\n<!-- internal note -->\nText\n",
+		"broken.ipynb": "{not json",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options := markup.DefaultOptions()
+	options.StripComments = true
+	processor := NewFileProcessor(&MixOptions{
+		InputPath:     tmpDir,
+		MaxFileSize:   1024,
+		MarkupOptions: options,
+	})
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"analysis.ipynb", "```python\nprint(1)\n```\n\n```output\n[image/png output omitted]\n```\n"},
+		{"README.md", "# Title\nText\n"},
+		{"broken.ipynb", "{not json"}, // Kept as is when conversion fails
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := processor.processFile(filepath.Join(tmpDir, tt.name))
+			if result.Error != nil {
+				t.Fatalf("processFile failed: %v", result.Error)
+			}
+			if result.Content.Content != tt.expected {
+				t.Errorf("Content = %q, want %q", result.Content.Content, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	random := make([]byte, 3000)
+	rand.New(rand.NewSource(1)).Read(random)
+	// Random data without NUL bytes is caught by the share of invalid UTF-8
+	withoutNUL := bytes.ReplaceAll(random, []byte{0}, []byte{1})
+	// A multi-byte rune cut off by the end of the sample is not invalid
+	longText := strings.Repeat("a", 7999) + "é"
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{name: "empty", content: nil},
+		{name: "source code", content: []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")},
+		{name: "utf-8 text", content: []byte("naïve café, 日本語, emoji 🎉\n")},
+		{name: "latin-1 text", content: []byte("caf\xe9 cr\xe8me br\xfbl\xe9e, a dessert\n")},
+		{name: "terminal colours", content: []byte("\x1b[31merror\x1b[0m: failed\r\n")},
+		{name: "cut off rune", content: []byte(longText)},
+		{name: "nul byte", content: []byte("package main\x00func main() {}"), want: true},
+		{name: "random bytes", content: random, want: true},
+		{name: "random bytes without nul", content: withoutNUL, want: true},
+		{name: "control characters", content: []byte("\x01\x02\x03\x04\x05\x06\x07abc"), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinaryContent(tt.content); got != tt.want {
+				t.Errorf("isBinaryContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
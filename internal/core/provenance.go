@@ -0,0 +1,131 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProvenanceOption is a setting in effect for a run
+type ProvenanceOption struct {
+	Name  string `json:"name" yaml:"name" xml:"name,attr"`
+	Value string `json:"value" yaml:"value" xml:"value,attr"`
+}
+
+// ProvenanceInput is an input path of a run with the git state it was read at
+type ProvenanceInput struct {
+	Path   string `json:"path" yaml:"path" xml:"path,attr"`
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty" xml:"commit,attr,omitempty"`
+	Dirty  bool   `json:"dirty,omitempty" yaml:"dirty,omitempty" xml:"dirty,attr,omitempty"`
+}
+
+// Provenance records how a bundle was made, so it can be reproduced and
+// compared with other bundles
+type Provenance struct {
+	XMLName     xml.Name           `json:"-" yaml:"-" xml:"provenance"`
+	Tool        string             `json:"tool" yaml:"tool" xml:"tool"`
+	Version     string             `json:"version" yaml:"version" xml:"version"`
+	GeneratedAt string             `json:"generated_at" yaml:"generated_at" xml:"generated_at"`
+	Inputs      []ProvenanceInput  `json:"inputs" yaml:"inputs" xml:"inputs>input"`
+	Options     []ProvenanceOption `json:"options" yaml:"options" xml:"options>option"`
+	ConfigHash  string             `json:"config_hash" yaml:"config_hash" xml:"config_hash"`
+}
+
+// NewProvenance describes a run of the given version over inputs, each with
+// the git state of the repository it is read from. The options are sorted by
+// name and hashed, so runs with the same settings share the config hash
+// whatever the order of their flags.
+func NewProvenance(version string, inputs []string, options []ProvenanceOption) (*Provenance, error) {
+	generatedAt, err := generationTime()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append([]ProvenanceOption(nil), options...)
+	sort.Slice(options, func(i, j int) bool { return options[i].Name < options[j].Name })
+
+	p := &Provenance{
+		Tool:        bundleGenerator,
+		Version:     version,
+		GeneratedAt: generatedAt.Format(time.RFC3339),
+		Options:     options,
+		ConfigHash:  configHash(options),
+	}
+	for _, input := range inputs {
+		entry := ProvenanceInput{Path: input}
+		entry.Commit, entry.Dirty = gitState(input)
+		p.Inputs = append(p.Inputs, entry)
+	}
+	return p, nil
+}
+
+// generationTime returns the time a bundle is generated at. SOURCE_DATE_EPOCH
+// overrides the clock, so reproducible builds produce identical bundles.
+func generationTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a number of seconds", epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// configHash returns the SHA-256 of the options, one name=value line each
+func configHash(options []ProvenanceOption) string {
+	h := sha256.New()
+	for _, option := range options {
+		fmt.Fprintf(h, "%s=%s\n", option.Name, option.Value)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// gitState returns the commit checked out in the repository containing input
+// and whether tracked files differ from it. Untracked files are not counted,
+// as earlier bundles written into the repository are usually untracked. A
+// revision input ("repo@v1.2.0") is read from the commit, so it is clean.
+// Inputs outside a git repository, or when git is unavailable, have no commit.
+func gitState(input string) (string, bool) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(input); err != nil {
+		if repo, rev, ok := splitRevision(input); ok {
+			commit, err := resolveRevision(repo, rev)
+			if err != nil {
+				return "", false
+			}
+			return commit, false
+		}
+	}
+
+	dir, err := filepath.Abs(input)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	commit := strings.TrimSpace(string(out))
+
+	out, err = exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return commit, false
+	}
+	return commit, len(strings.TrimSpace(string(out))) > 0
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewProvenance(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	options := []ProvenanceOption{{Name: "pattern", Value: "*.go"}, {Name: "format", Value: "xml"}}
+	p, err := NewProvenance("v1.2.3", []string{t.TempDir()}, options)
+	if err != nil {
+		t.Fatalf("NewProvenance() error = %v", err)
+	}
+	if p.Tool != "filefusion" || p.Version != "v1.2.3" {
+		t.Errorf("tool, version = %q, %q", p.Tool, p.Version)
+	}
+	if p.GeneratedAt != "2023-11-14T22:13:20Z" {
+		t.Errorf("GeneratedAt = %q, want the time of SOURCE_DATE_EPOCH", p.GeneratedAt)
+	}
+	if p.Options[0].Name != "format" || p.Options[1].Name != "pattern" {
+		t.Errorf("Options = %v, want them sorted by name", p.Options)
+	}
+	if options[0].Name != "pattern" {
+		t.Error("NewProvenance() reordered the options of the caller")
+	}
+	if !strings.HasPrefix(p.ConfigHash, "sha256:") {
+		t.Errorf("ConfigHash = %q, want a sha256 hash", p.ConfigHash)
+	}
+
+	// The hash follows the settings, not the order they were given in
+	reordered, _ := NewProvenance("v1.2.3", []string{t.TempDir()}, []ProvenanceOption{options[1], options[0]})
+	if reordered.ConfigHash != p.ConfigHash {
+		t.Errorf("ConfigHash = %q for reordered options, want %q", reordered.ConfigHash, p.ConfigHash)
+	}
+	changed, _ := NewProvenance("v1.2.3", []string{t.TempDir()}, []ProvenanceOption{{Name: "format", Value: "json"}, options[0]})
+	if changed.ConfigHash == p.ConfigHash {
+		t.Error("ConfigHash did not change with an option value")
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := NewProvenance("v1.2.3", []string{t.TempDir()}, nil); err == nil {
+		t.Error("NewProvenance() with an invalid SOURCE_DATE_EPOCH succeeded")
+	}
+}
+
+func TestGitState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	if commit, dirty := gitState(t.TempDir()); commit != "" || dirty {
+		t.Errorf("gitState() outside a repository = %q, %v", commit, dirty)
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "-q")
+	main := filepath.Join(repo, "main.go")
+	if err := os.WriteFile(main, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1")
+	head := git("rev-parse", "HEAD")
+
+	tests := []struct {
+		name      string
+		setup     func()
+		input     string
+		wantDirty bool
+	}{
+		{name: "clean", input: repo},
+		{name: "file input", input: main},
+		{name: "untracked file", setup: func() {
+			os.WriteFile(filepath.Join(repo, "bundle.xml"), []byte("<documents/>"), 0644)
+		}, input: repo},
+		{name: "modified file", setup: func() {
+			os.WriteFile(main, []byte("package main // changed\n"), 0644)
+		}, input: repo, wantDirty: true},
+		{name: "revision", input: repo + "@v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			commit, dirty := gitState(tt.input)
+			if commit != head {
+				t.Errorf("gitState() commit = %q, want %q", commit, head)
+			}
+			if dirty != tt.wantDirty {
+				t.Errorf("gitState() dirty = %v, want %v", dirty, tt.wantDirty)
+			}
+		})
+	}
+}
+
+func TestNewProvenanceInputs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	// commitRepo creates a repository with one commit and returns its hash
+	commitRepo := func(repo, content string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var head string
+		for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "initial"}, {"rev-parse", "HEAD"}} {
+			cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+			cmd.Env = append(os.Environ(),
+				"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+				"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+			head = strings.TrimSpace(string(out))
+		}
+		return head
+	}
+
+	first, second, plain := t.TempDir(), t.TempDir(), t.TempDir()
+	firstHead := commitRepo(first, "package a\n")
+	secondHead := commitRepo(second, "package b\n")
+	if err := os.WriteFile(filepath.Join(second, "main.go"), []byte("package b // changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewProvenance("v1.2.3", []string{first, second, plain}, nil)
+	if err != nil {
+		t.Fatalf("NewProvenance() error = %v", err)
+	}
+	want := []ProvenanceInput{
+		{Path: first, Commit: firstHead},
+		{Path: second, Commit: secondHead, Dirty: true},
+		{Path: plain},
+	}
+	if !reflect.DeepEqual(p.Inputs, want) {
+		t.Errorf("Inputs = %+v, want %+v", p.Inputs, want)
+	}
+}
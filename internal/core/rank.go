@@ -0,0 +1,440 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// BM25 parameters and the weights of the additional ranking signals
+const (
+	bm25K1        = 1.2
+	bm25B         = 0.75
+	pathWeight    = 1.0  // Added per query term found in the file path
+	recencyWeight = 0.5  // Multiplied with the recency signal in [0, 1]
+	recencyDays   = 90.0 // Age at which the recency signal has decayed to 1/e
+	gitLogLimit   = 1000 // Number of recent commits inspected for recency
+)
+
+// treePlaceholder replaces the content of files that are listed in tree-only mode
+const treePlaceholder = "(content omitted to fit the budget)"
+
+// SelectionMode describes how a ranked file is included in the bundle
+type SelectionMode string
+
+const (
+	SelectionFull     SelectionMode = "full"
+	SelectionSkeleton SelectionMode = "skeleton"
+	SelectionTree     SelectionMode = "tree"
+	SelectionDropped  SelectionMode = "dropped"
+)
+
+// Fallback selects what happens to files that do not fit the budget in full
+type Fallback string
+
+const (
+	FallbackSkeleton Fallback = "skeleton" // Try a skeleton, then tree-only
+	FallbackTree     Fallback = "tree"     // List the path without content
+	FallbackNone     Fallback = "none"     // Leave the file out
+)
+
+// RankedFile is a file scored against a query
+type RankedFile struct {
+	Path    string        `json:"path"`
+	Score   float64       `json:"score"`
+	BM25    float64       `json:"bm25"`
+	PathHit float64       `json:"path_match"`
+	Recency float64       `json:"recency"`
+	Size    int64         `json:"size"`
+	Tokens  int           `json:"tokens"`
+	Mode    SelectionMode `json:"mode,omitempty"`
+}
+
+// Budget limits the size of a ranked selection. Zero values mean no limit.
+type Budget struct {
+	MaxBytes  int64
+	MaxTokens int
+	Fallback  Fallback
+}
+
+// ParseFallback validates a --fallback value
+func ParseFallback(value string) (Fallback, error) {
+	switch Fallback(strings.ToLower(value)) {
+	case FallbackSkeleton, "":
+		return FallbackSkeleton, nil
+	case FallbackTree:
+		return FallbackTree, nil
+	case FallbackNone:
+		return FallbackNone, nil
+	default:
+		return "", fmt.Errorf("invalid fallback %q: must be skeleton, tree or none", value)
+	}
+}
+
+// RankFiles scores the files against the query and returns them sorted by
+// descending score. The score combines BM25 over the identifiers and words
+// of the content and path, a bonus for query terms in the path and, inside
+// git repositories, how recently the file was changed.
+func RankFiles(files []string, query string) ([]RankedFile, error) {
+	terms := uniqueStrings(tokenize(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query %q contains no searchable terms", query)
+	}
+
+	ranked := make([]RankedFile, len(files))
+	termFreqs := make([]map[string]int, len(files))
+	lengths := make([]int, len(files))
+	docFreq := make(map[string]int)
+	totalLength := 0
+
+	for i, file := range files {
+		content, err := readFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+
+		pathTokens := tokenize(filepath.ToSlash(file))
+		tokens := append(tokenize(string(content)), pathTokens...)
+		lengths[i] = len(tokens)
+		totalLength += len(tokens)
+
+		freqs := make(map[string]int)
+		for _, token := range tokens {
+			freqs[token]++
+		}
+		termFreqs[i] = freqs
+
+		pathSet := make(map[string]bool, len(pathTokens))
+		for _, token := range pathTokens {
+			pathSet[token] = true
+		}
+
+		ranked[i] = RankedFile{
+			Path:   file,
+			Size:   int64(len(content)),
+			Tokens: EstimateTokens(string(content)),
+		}
+		for _, term := range terms {
+			if freqs[term] > 0 {
+				docFreq[term]++
+			}
+			if pathSet[term] {
+				ranked[i].PathHit += pathWeight
+			}
+		}
+	}
+
+	avgLength := 1.0
+	if len(files) > 0 && totalLength > 0 {
+		avgLength = float64(totalLength) / float64(len(files))
+	}
+
+	modTimes := gitModTimes(files)
+	now := time.Now()
+
+	for i := range ranked {
+		for _, term := range terms {
+			tf := float64(termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log((float64(len(files))-df+0.5)/(df+0.5) + 1)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/avgLength)
+			ranked[i].BM25 += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+
+		if modified, ok := modTimes[ranked[i].Path]; ok {
+			age := now.Sub(modified).Hours() / 24
+			ranked[i].Recency = math.Exp(-math.Max(age, 0) / recencyDays)
+		}
+
+		ranked[i].Score = ranked[i].BM25 + ranked[i].PathHit + recencyWeight*ranked[i].Recency
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+	return ranked, nil
+}
+
+// FitBudget assigns a selection mode to each ranked file, filling the budget
+// greedily in rank order. Files that do not fit in full fall back to a
+// skeleton or to a tree-only entry as allowed by the budget's fallback.
+func FitBudget(ranked []RankedFile, budget Budget) {
+	var usedBytes int64
+	var usedTokens int
+
+	fits := func(size int64, tokens int) bool {
+		return (budget.MaxBytes <= 0 || usedBytes+size <= budget.MaxBytes) &&
+			(budget.MaxTokens <= 0 || usedTokens+tokens <= budget.MaxTokens)
+	}
+	take := func(i int, mode SelectionMode, size int64, tokens int) {
+		ranked[i].Mode = mode
+		usedBytes += size
+		usedTokens += tokens
+	}
+
+	for i, file := range ranked {
+		if fits(file.Size, file.Tokens) {
+			take(i, SelectionFull, file.Size, file.Tokens)
+			continue
+		}
+
+		if budget.Fallback == FallbackSkeleton {
+			if skeleton, ok := skeletonOf(file.Path); ok {
+				size, tokens := int64(len(skeleton)), EstimateTokens(skeleton)
+				if fits(size, tokens) {
+					take(i, SelectionSkeleton, size, tokens)
+					continue
+				}
+			}
+		}
+
+		if budget.Fallback == FallbackSkeleton || budget.Fallback == FallbackTree {
+			size, tokens := int64(len(treePlaceholder)), EstimateTokens(treePlaceholder)
+			if fits(size, tokens) {
+				take(i, SelectionTree, size, tokens)
+				continue
+			}
+		}
+
+		ranked[i].Mode = SelectionDropped
+	}
+}
+
+// SplitRanking returns the files selected in rank order together with report
+// entries for the files left out of the budget
+func SplitRanking(ranked []RankedFile) ([]string, []ReportEntry) {
+	var selected []string
+	var dropped []ReportEntry
+	for _, file := range ranked {
+		if file.Mode == SelectionDropped {
+			dropped = append(dropped, ReportEntry{
+				Path:   file.Path,
+				Status: FileStatusSkippedBudget,
+				Reason: fmt.Sprintf("ranked below the budget (score %.3f)", file.Score),
+				Size:   file.Size,
+			})
+			continue
+		}
+		selected = append(selected, file.Path)
+	}
+	return selected, dropped
+}
+
+// ApplyRanking reduces processed contents to their selection mode and sorts
+// them by rank, so that the most relevant files come first in the bundle
+func ApplyRanking(contents []FileContent, ranked []RankedFile) []FileContent {
+	modes := make(map[string]SelectionMode, len(ranked))
+	order := make([]string, 0, len(ranked))
+	for _, file := range ranked {
+		modes[filepath.ToSlash(file.Path)] = file.Mode
+		order = append(order, file.Path)
+	}
+
+	cleaners := make(map[cleaner.Language]*cleaner.Cleaner)
+	result := make([]FileContent, 0, len(contents))
+	for _, content := range contents {
+		mode := modes[content.key()]
+		if mode == SelectionSkeleton {
+			skeleton, err := skeletonContent(cleaners, content)
+			if err == nil {
+				content.Content = string(skeleton)
+				content.Size = int64(len(skeleton))
+				content.Skeleton = true
+			} else {
+				// The full content would overrun the budget the skeleton was counted against
+				logEvent(slog.LevelWarn, EventSkeletonFailed, content.Path, err.Error()+", listed in tree-only mode instead")
+				mode = SelectionTree
+			}
+		}
+		switch mode {
+		case SelectionDropped:
+			continue
+		case SelectionTree:
+			content.Content = treePlaceholder
+			content.Size = int64(len(treePlaceholder))
+		}
+		result = append(result, content)
+	}
+
+	OrderContents(result, order)
+	return result
+}
+
+// skeletonContent returns the skeleton of a processed file, sharing one
+// cleaner per language
+func skeletonContent(cleaners map[cleaner.Language]*cleaner.Cleaner, content FileContent) ([]byte, error) {
+	lang := cleaner.Language(content.Language)
+	c, ok := cleaners[lang]
+	if !ok {
+		var err error
+		if c, err = cleaner.NewCleaner(lang, cleaner.DefaultOptions()); err != nil {
+			return nil, err
+		}
+		cleaners[lang] = c
+	}
+	return c.Skeleton([]byte(content.Content))
+}
+
+// LogRanking logs the score and selection of every ranked file
+func LogRanking(ranked []RankedFile, level slog.Level) {
+	for _, file := range ranked {
+		logEvent(level, EventFileRanked, file.Path, "",
+			"score", fmt.Sprintf("%.3f", file.Score),
+			"bm25", fmt.Sprintf("%.3f", file.BM25),
+			"path_match", fmt.Sprintf("%.1f", file.PathHit),
+			"recency", fmt.Sprintf("%.2f", file.Recency),
+			"mode", string(file.Mode))
+	}
+}
+
+// skeletonOf returns the skeleton of a file in a language with skeleton support
+func skeletonOf(path string) (string, bool) {
+	lang := languageForPath(path)
+	if lang == "" || !cleaner.SupportsSkeleton(lang) {
+		return "", false
+	}
+	content, err := readFile(path)
+	if err != nil || len(content) == 0 {
+		return "", false
+	}
+	c, err := cleaner.NewCleaner(lang, cleaner.DefaultOptions())
+	if err != nil {
+		return "", false
+	}
+	skeleton, err := c.Skeleton(content)
+	if err != nil {
+		return "", false
+	}
+	return string(skeleton), true
+}
+
+// tokenize splits text into lower-case search terms. Identifiers are kept
+// whole and also split into their camelCase parts, so that "ParseConfig"
+// matches the queries "parseconfig", "parse" and "config".
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	for _, word := range words {
+		parts := splitCamelCase(word)
+		if len(word) > 1 {
+			tokens = append(tokens, strings.ToLower(word))
+		}
+		if len(parts) > 1 {
+			for _, part := range parts {
+				if len(part) > 1 {
+					tokens = append(tokens, strings.ToLower(part))
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+// splitCamelCase splits an identifier at lower-to-upper transitions and at the
+// end of upper-case runs, e.g. "parseHTTPRequest" into parse, HTTP, Request
+func splitCamelCase(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && nextLower {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// uniqueStrings removes duplicates while keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// gitModTimes returns the time of the most recent commit touching each file,
+// looking at the last commits of the repository containing the files. Files
+// outside a git repository, or when git is unavailable, are left out.
+func gitModTimes(files []string) map[string]time.Time {
+	result := make(map[string]time.Time)
+	if len(files) == 0 {
+		return result
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return result
+	}
+
+	abs, err := filepath.Abs(files[0])
+	if err != nil {
+		return result
+	}
+	out, err := exec.Command("git", "-C", filepath.Dir(abs), "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return result
+	}
+	root := strings.TrimSpace(string(out))
+
+	out, err = exec.Command("git", "-C", root, "log", "-n", strconv.Itoa(gitLogLimit),
+		"--format=%x00%ct", "--name-only", "--no-renames").Output()
+	if err != nil {
+		return result
+	}
+
+	latest := make(map[string]time.Time)
+	var current time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			if seconds, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				current = time.Unix(seconds, 0)
+			}
+		case line != "":
+			path := filepath.Join(root, filepath.FromSlash(line))
+			if _, seen := latest[path]; !seen {
+				latest[path] = current
+			}
+		}
+	}
+
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		if modified, ok := latest[abs]; ok {
+			result[file] = modified
+		}
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"ParseConfig", []string{"parseconfig", "parse", "config"}},
+		{"parseHTTPRequest(x)", []string{"parsehttprequest", "parse", "http", "request"}},
+		{"max_file_size", []string{"max", "file", "size"}},
+		{"internal/core/rank.go", []string{"internal", "core", "rank", "go"}},
+		{"a + b", nil},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRankFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"auth/login.go":  "package auth\n\nfunc Login(user string) error { return checkPassword(user) }\n",
+		"auth/token.go":  "package auth\n\nfunc IssueToken() string { return \"token\" }\n",
+		"db/query.go":    "package db\n\nfunc Query() {}\n",
+		"docs/readme.md": "How to login: call the Login function with a user name and password.\n",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	ranked, err := RankFiles(paths, "login password")
+	if err != nil {
+		t.Fatalf("RankFiles() error = %v", err)
+	}
+	if len(ranked) != len(paths) {
+		t.Fatalf("Expected %d ranked files, got %d", len(paths), len(ranked))
+	}
+
+	if !strings.HasSuffix(filepath.ToSlash(ranked[0].Path), "auth/login.go") {
+		t.Errorf("Expected auth/login.go to rank first, got %s", ranked[0].Path)
+	}
+	if ranked[0].PathHit == 0 {
+		t.Errorf("Expected a path match for %s", ranked[0].Path)
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Score > ranked[i-1].Score {
+			t.Errorf("Ranking not sorted: %v", ranked)
+		}
+	}
+	last := ranked[len(ranked)-1]
+	if last.Score != 0 {
+		t.Errorf("Expected unrelated file to score 0, got %s with %.3f", last.Path, last.Score)
+	}
+
+	if _, err := RankFiles(paths, "  + "); err == nil {
+		t.Error("Expected error for a query without terms")
+	}
+}
+
+func TestFitBudget(t *testing.T) {
+	dir := t.TempDir()
+	goFile := filepath.Join(dir, "big.go")
+	goContent := "package big\n\nfunc Big() {\n" + strings.Repeat("\tprintln(\"padding\")\n", 20) + "}\n"
+	if err := os.WriteFile(goFile, []byte(goContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newRanking := func() []RankedFile {
+		return []RankedFile{
+			{Path: "first.txt", Size: 100, Tokens: 25},
+			{Path: goFile, Size: int64(len(goContent)), Tokens: EstimateTokens(goContent)},
+			{Path: "third.txt", Size: 100, Tokens: 25},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		budget Budget
+		want   []SelectionMode
+	}{
+		{
+			name:   "everything fits",
+			budget: Budget{MaxBytes: 10000, Fallback: FallbackSkeleton},
+			want:   []SelectionMode{SelectionFull, SelectionFull, SelectionFull},
+		},
+		{
+			name:   "skeleton fallback",
+			budget: Budget{MaxBytes: 200, Fallback: FallbackSkeleton},
+			want:   []SelectionMode{SelectionFull, SelectionSkeleton, SelectionTree},
+		},
+		{
+			name:   "tree fallback",
+			budget: Budget{MaxBytes: 150, Fallback: FallbackTree},
+			want:   []SelectionMode{SelectionFull, SelectionTree, SelectionDropped},
+		},
+		{
+			name:   "no fallback",
+			budget: Budget{MaxBytes: 250, Fallback: FallbackNone},
+			want:   []SelectionMode{SelectionFull, SelectionDropped, SelectionFull},
+		},
+		{
+			name:   "token budget",
+			budget: Budget{MaxTokens: 30, Fallback: FallbackNone},
+			want:   []SelectionMode{SelectionFull, SelectionDropped, SelectionDropped},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := newRanking()
+			FitBudget(ranked, tt.budget)
+
+			var got []SelectionMode
+			for _, file := range ranked {
+				got = append(got, file.Mode)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FitBudget() modes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAndApplyRanking(t *testing.T) {
+	ranked := []RankedFile{
+		{Path: "b.go", Score: 3, Mode: SelectionSkeleton},
+		{Path: "a.txt", Score: 2, Mode: SelectionFull},
+		{Path: "c.txt", Score: 1, Mode: SelectionTree},
+		{Path: "d.txt", Score: 0, Mode: SelectionDropped, Size: 10},
+	}
+
+	selected, dropped := SplitRanking(ranked)
+	if want := []string{"b.go", "a.txt", "c.txt"}; !reflect.DeepEqual(selected, want) {
+		t.Errorf("SplitRanking() selected = %v, want %v", selected, want)
+	}
+	if len(dropped) != 1 || dropped[0].Path != "d.txt" || dropped[0].Status != FileStatusSkippedBudget {
+		t.Errorf("SplitRanking() dropped = %v", dropped)
+	}
+
+	contents := []FileContent{
+		{Path: "a.txt", Content: "alpha"},
+		{Path: "c.txt", Content: "gamma"},
+		{Path: "b.go", Language: "go", Content: "package b\n\nfunc B() {\n\tprintln(1)\n}\n"},
+	}
+	result := ApplyRanking(contents, ranked)
+
+	var paths []string
+	for _, content := range result {
+		paths = append(paths, content.Path)
+	}
+	if want := []string{"b.go", "a.txt", "c.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("ApplyRanking() order = %v, want %v", paths, want)
+	}
+	if !result[0].Skeleton || strings.Contains(result[0].Content, "println") {
+		t.Errorf("Expected b.go to be skeletonized, got %q", result[0].Content)
+	}
+	if result[1].Content != "alpha" {
+		t.Errorf("Expected a.txt to be kept in full, got %q", result[1].Content)
+	}
+	if result[2].Content != treePlaceholder || result[2].Size != int64(len(treePlaceholder)) {
+		t.Errorf("Expected c.txt to be tree-only, got %q", result[2].Content)
+	}
+}
+
+func TestParseFallback(t *testing.T) {
+	for input, want := range map[string]Fallback{"": FallbackSkeleton, "skeleton": FallbackSkeleton, "TREE": FallbackTree, "none": FallbackNone} {
+		got, err := ParseFallback(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFallback(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseFallback("drop"); err == nil {
+		t.Error("Expected error for invalid fallback")
+	}
+}
+
+func TestApplyRankingFromSource(t *testing.T) {
+	src, err := OpenSource(createArchive(t, t.TempDir(), "app.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	// Ranked files keep the path the finder reported, contents the path inside the archive
+	ranked := []RankedFile{
+		{Path: src.Path("src/util.go"), Mode: SelectionFull},
+		{Path: src.Path("docs/guide.txt"), Mode: SelectionSkeleton},
+		{Path: src.Path("src/main.go"), Mode: SelectionTree},
+		{Path: src.Path("README.md"), Mode: SelectionDropped},
+	}
+	var files []string
+	for _, file := range ranked {
+		files = append(files, file.Path)
+	}
+	processor := NewFileProcessor(&MixOptions{MaxFileSize: 1024, MaxOutputSize: 1024 * 1024})
+	contents, err := processor.ProcessFiles(files)
+	if err != nil {
+		t.Fatalf("ProcessFiles() error = %v", err)
+	}
+
+	result := ApplyRanking(contents, ranked)
+	got := make(map[string]string)
+	var paths []string
+	for _, content := range result {
+		got[content.Path] = content.Content
+		paths = append(paths, content.Path)
+	}
+	if want := []string{"src/util.go", "docs/guide.txt", "src/main.go"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("ApplyRanking() = %v, want %v", paths, want)
+	}
+	if got["src/util.go"] != sourceFiles["src/util.go"] {
+		t.Errorf("src/util.go = %q, want the full content", got["src/util.go"])
+	}
+	// A file without a skeleton does not overrun the budget with its full content
+	if got["docs/guide.txt"] != treePlaceholder {
+		t.Errorf("docs/guide.txt = %q, want it listed in tree-only mode", got["docs/guide.txt"])
+	}
+	if got["src/main.go"] != treePlaceholder {
+		t.Errorf("src/main.go = %q, want it listed in tree-only mode", got["src/main.go"])
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// FileStatus describes what happened to a file during a run
+type FileStatus string
+
+const (
+	FileStatusIncluded      FileStatus = "included"
+	FileStatusSkippedSize   FileStatus = "skipped_size"
+	FileStatusSkippedBinary FileStatus = "skipped_binary"
+	FileStatusExcluded      FileStatus = "excluded"
+	FileStatusFiltered      FileStatus = "filtered"
+	FileStatusSkippedBudget FileStatus = "skipped_budget"
+	FileStatusSkippedLink   FileStatus = "skipped_link"
+	FileStatusFailed        FileStatus = "failed"
+	FileStatusSkippedOutput FileStatus = "skipped_output"
+)
+
+// ReportEntry records the outcome for a single file
+type ReportEntry struct {
+	Path     string     `json:"path"`
+	Status   FileStatus `json:"status"`
+	Reason   string     `json:"reason,omitempty"`
+	Size     int64      `json:"size"`
+	Tokens   int        `json:"tokens,omitempty"`
+	Language string     `json:"language,omitempty"`
+}
+
+// Report is the machine-readable summary of a run, listing every file that
+// was considered together with the aggregated bundle statistics
+type Report struct {
+	Outputs    []string      `json:"outputs,omitempty"`
+	External   []string      `json:"external,omitempty"`
+	Ranking    []RankedFile  `json:"ranking,omitempty"`
+	Statistics *BundleStats  `json:"statistics"`
+	Files      []ReportEntry `json:"files"`
+}
+
+// NewReport builds a report from the included contents and the entries for
+// files that were skipped or excluded along the way. Files are sorted by path
+// so that the report is stable across runs.
+func NewReport(included []FileContent, skipped []ReportEntry) *Report {
+	report := &Report{
+		Statistics: ComputeStats(included),
+		Files:      make([]ReportEntry, 0, len(included)+len(skipped)),
+	}
+
+	for _, content := range included {
+		var reason string
+		if content.Truncated {
+			reason = "truncated to its first and last lines"
+		}
+		report.Files = append(report.Files, ReportEntry{
+			Path:     content.Path,
+			Status:   FileStatusIncluded,
+			Reason:   reason,
+			Size:     content.Size,
+			Tokens:   EstimateTokens(content.Content),
+			Language: content.Language,
+		})
+	}
+	report.Files = append(report.Files, skipped...)
+
+	sort.SliceStable(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	return report
+}
+
+// Count returns the number of entries in the report with the given status
+func (r *Report) Count(status FileStatus) int {
+	count := 0
+	for _, entry := range r.Files {
+		if entry.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteReport writes the report as indented JSON to the given path
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return &MixError{File: path, Message: fmt.Sprintf("error encoding report: %v", err)}
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return &MixError{File: path, Message: fmt.Sprintf("error writing report: %v", err)}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	included := []FileContent{
+		{Path: "b.go", Content: "package b", Size: 9, Language: "go"},
+	}
+	skipped := []ReportEntry{
+		{Path: "c.bin", Status: FileStatusSkippedBinary, Reason: "file appears to be binary", Size: 10},
+		{Path: "a.log", Status: FileStatusExcluded, Reason: `matched exclude pattern "*.log"`},
+	}
+
+	report := NewReport(included, skipped)
+
+	if len(report.Files) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(report.Files))
+	}
+	if report.Files[0].Path != "a.log" || report.Files[1].Path != "b.go" || report.Files[2].Path != "c.bin" {
+		t.Errorf("Expected entries sorted by path, got %+v", report.Files)
+	}
+	if report.Files[1].Status != FileStatusIncluded || report.Files[1].Tokens != 3 {
+		t.Errorf("Unexpected included entry: %+v", report.Files[1])
+	}
+	if report.Count(FileStatusIncluded) != 1 || report.Count(FileStatusExcluded) != 1 {
+		t.Errorf("Unexpected counts in report")
+	}
+	if report.Statistics.TotalFiles != 1 {
+		t.Errorf("Expected statistics for 1 file, got %d", report.Statistics.TotalFiles)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	report := NewReport([]FileContent{{Path: "a.go", Content: "package a", Size: 9}}, nil)
+
+	if err := WriteReport(path, report); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if len(decoded.Files) != 1 || decoded.Files[0].Status != FileStatusIncluded {
+		t.Errorf("Unexpected decoded report: %+v", decoded)
+	}
+
+	if err := WriteReport(filepath.Join(t.TempDir(), "missing", "report.json"), report); err == nil {
+		t.Error("Expected error writing to a missing directory")
+	}
+}
+
+func TestReportCollectsSkippedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string][]byte{
+		"main.go":    []byte("package main\n"),
+		"debug.log":  []byte("log line\n"),
+		"big.go":     []byte(strings.Repeat("x", 200)),
+		"image.json": {0x89, 0x50, 0x4e, 0x47, 0x00, 0x00, 0x00, 0x0d, 0x01, 0x02},
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	finder := NewFileFinder([]string{"*.go", "*.log", "*.json"}, []string{"*.log"}, true)
+	found, err := finder.FindMatchingFiles([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("FindMatchingFiles failed: %v", err)
+	}
+
+	manager := NewFileManager(100, 1000, OutputTypeXML)
+	valid, err := manager.ValidateFiles(found)
+	if err != nil {
+		t.Fatalf("ValidateFiles failed: %v", err)
+	}
+
+	processor := NewFileProcessor(&MixOptions{InputPath: tmpDir, MaxFileSize: 100, SkipBinary: true})
+	contents, err := processor.ProcessFiles(valid)
+	if err != nil {
+		t.Fatalf("ProcessFiles failed: %v", err)
+	}
+
+	var skipped []ReportEntry
+	skipped = append(skipped, finder.ExcludedFiles()...)
+	skipped = append(skipped, manager.SkippedFiles()...)
+	skipped = append(skipped, processor.SkippedFiles()...)
+	report := NewReport(contents, skipped)
+
+	statuses := make(map[string]FileStatus)
+	for _, entry := range report.Files {
+		statuses[filepath.Base(entry.Path)] = entry.Status
+		if entry.Status != FileStatusIncluded && entry.Reason == "" {
+			t.Errorf("Expected a reason for %s", entry.Path)
+		}
+	}
+
+	want := map[string]FileStatus{
+		"main.go":    FileStatusIncluded,
+		"debug.log":  FileStatusExcluded,
+		"big.go":     FileStatusSkippedSize,
+		"image.json": FileStatusSkippedBinary,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %q", name, status, statuses[name])
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// LineRange is an inclusive range of 1-based line numbers. An End of zero
+// extends the range to the end of the file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Selection narrows an input file to line ranges and the declarations of
+// symbols, given as selectors on the input path
+type Selection struct {
+	Ranges  []LineRange
+	Symbols []string // Symbol patterns such as "runMix" or "FileProcessor.*"
+}
+
+var (
+	// rangeSelector matches "path:40-120", "path:40-" and "path:40"
+	rangeSelector = regexp.MustCompile(`^(.+):(\d+)(?:(-)(\d*))?$`)
+	// symbolSelector matches "path#Name" and "path#Type.*"
+	symbolSelector = regexp.MustCompile(`^(.+)#([\pL_$][\pL\pN_$.*?]*)$`)
+)
+
+// ParseSelectors splits line-range selectors ("file.go:40-120") and symbol
+// selectors ("file.go#runMix", "file.go#FileProcessor.*") off input paths.
+// It returns the paths without selectors, each once, and the selections by
+// absolute file path. Several selectors on one file are combined. Paths that
+// exist as given are never treated as selectors.
+func ParseSelectors(args []string) ([]string, map[string]*Selection, error) {
+	var paths []string
+	selections := make(map[string]*Selection)
+	seen := make(map[string]bool)
+
+	for _, arg := range args {
+		p, sel, err := parseSelector(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sel == nil {
+			paths = append(paths, arg)
+			continue
+		}
+
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error resolving path %q: %w", p, err)
+		}
+		if existing, ok := selections[abs]; ok {
+			existing.Ranges = append(existing.Ranges, sel.Ranges...)
+			existing.Symbols = append(existing.Symbols, sel.Symbols...)
+		} else {
+			selections[abs] = sel
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			paths = append(paths, p)
+		}
+	}
+
+	if len(selections) == 0 {
+		return paths, nil, nil
+	}
+	return paths, selections, nil
+}
+
+// parseSelector returns the path and selection of one input argument, or a
+// nil selection when the argument has no selector
+func parseSelector(arg string) (string, *Selection, error) {
+	if _, err := os.Lstat(arg); err == nil {
+		return arg, nil, nil
+	}
+
+	if m := rangeSelector.FindStringSubmatch(arg); m != nil {
+		start, err := strconv.Atoi(m[2])
+		if err != nil || start < 1 {
+			return "", nil, fmt.Errorf("invalid selector %q: lines are numbered from 1", arg)
+		}
+		end := start
+		if m[3] != "" {
+			end = 0
+			if m[4] != "" {
+				if end, err = strconv.Atoi(m[4]); err != nil || end < start {
+					return "", nil, fmt.Errorf("invalid selector %q: the range ends before it starts", arg)
+				}
+			}
+		}
+		return m[1], &Selection{Ranges: []LineRange{{Start: start, End: end}}}, nil
+	}
+
+	if m := symbolSelector.FindStringSubmatch(arg); m != nil {
+		return m[1], &Selection{Symbols: []string{m[2]}}, nil
+	}
+	return arg, nil, nil
+}
+
+// Apply reduces content to the selected lines. The symbols are resolved with
+// the declarations the cleaner parses for the language. It returns the
+// selected lines, in file order and each once with a marker line in place of
+// the lines left out between ranges, and the ranges they span.
+func (s *Selection) Apply(path string, content []byte, lang cleaner.Language) (string, []LineRange, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var ranges []LineRange
+	for _, r := range s.Ranges {
+		if r.Start > len(lines) {
+			return "", nil, fmt.Errorf("line %d is past the end of the file, which has %d lines", r.Start, len(lines))
+		}
+		if r.End == 0 || r.End > len(lines) {
+			r.End = len(lines)
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(s.Symbols) > 0 {
+		symbolRanges, err := symbolRanges(path, content, lang, s.Symbols)
+		if err != nil {
+			return "", nil, err
+		}
+		ranges = append(ranges, symbolRanges...)
+	}
+
+	ranges = mergeRanges(ranges)
+	var b strings.Builder
+	for i, r := range ranges {
+		if i > 0 {
+			b.WriteString(elisionMarker(r.Start - ranges[i-1].End - 1))
+		}
+		for _, line := range lines[r.Start-1 : r.End] {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), ranges, nil
+}
+
+// elisionMarker returns the line that stands in for the lines between two
+// selected ranges
+func elisionMarker(omitted int) string {
+	if omitted == 1 {
+		return "... [1 line omitted] ...\n"
+	}
+	return fmt.Sprintf("... [%s lines omitted] ...\n", formatCount(omitted))
+}
+
+// symbolRanges returns the lines of the declarations matching the symbols,
+// including their doc comments
+func symbolRanges(path string, content []byte, lang cleaner.Language, symbols []string) ([]LineRange, error) {
+	if lang == "" || !cleaner.SupportsSymbols(lang) {
+		return nil, fmt.Errorf("symbol selectors are not supported for this file, its language %q has no symbol support", lang)
+	}
+	c, err := cleaner.NewCleaner(lang, cleaner.DefaultOptions())
+	if err != nil {
+		return nil, err
+	}
+	table, err := c.Declarations(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []LineRange
+	for _, decl := range table.Declarations {
+		if matchesSymbol(symbols, symbolPrefixes(table, path), decl) {
+			ranges = append(ranges, LineRange{
+				Start: lineAt(content, decl.Start),
+				End:   lineAt(content, max(decl.End, decl.Start+1)-1),
+			})
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no declarations found matching #%s", strings.Join(symbols, ", #"))
+	}
+	return ranges, nil
+}
+
+// lineAt returns the 1-based line number of a byte offset
+func lineAt(content []byte, offset uint32) int {
+	return strings.Count(string(content[:min(int(offset), len(content))]), "\n") + 1
+}
+
+// mergeRanges sorts ranges and joins those that overlap or touch
+func mergeRanges(ranges []LineRange) []LineRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	var merged []LineRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End+1 {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// FormatLineRanges returns ranges as recorded in the document metadata, e.g.
+// "12-40,55-80"
+func FormatLineRanges(ranges []LineRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseLineRanges parses ranges in the form written by FormatLineRanges
+func parseLineRanges(s string) ([]LineRange, error) {
+	var ranges []LineRange
+	for _, part := range strings.Split(s, ",") {
+		start, end, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid line range %q", part)
+		}
+		r := LineRange{}
+		var err error
+		if r.Start, err = strconv.Atoi(start); err != nil {
+			return nil, fmt.Errorf("invalid line range %q", part)
+		}
+		if r.End, err = strconv.Atoi(end); err != nil || r.End < r.Start {
+			return nil, fmt.Errorf("invalid line range %q", part)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+func TestParseSelectors(t *testing.T) {
+	dir := t.TempDir()
+	// A file whose name looks like a selector is taken as it is
+	existing := filepath.Join(dir, "notes:1")
+	if err := os.WriteFile(existing, []byte("notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	abs := func(p string) string {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantPaths []string
+		want      map[string]*Selection
+		wantErr   string
+	}{
+		{name: "plain paths", args: []string{"src", "main.go"}, wantPaths: []string{"src", "main.go"}},
+		{
+			name:      "line range",
+			args:      []string{"main.go:40-120"},
+			wantPaths: []string{"main.go"},
+			want:      map[string]*Selection{abs("main.go"): {Ranges: []LineRange{{40, 120}}}},
+		},
+		{
+			name:      "single line and open range",
+			args:      []string{"a.go:7", "b.go:30-"},
+			wantPaths: []string{"a.go", "b.go"},
+			want: map[string]*Selection{
+				abs("a.go"): {Ranges: []LineRange{{7, 7}}},
+				abs("b.go"): {Ranges: []LineRange{{30, 0}}},
+			},
+		},
+		{
+			name:      "symbols",
+			args:      []string{"main.go#runMix", "proc.go#FileProcessor.*"},
+			wantPaths: []string{"main.go", "proc.go"},
+			want: map[string]*Selection{
+				abs("main.go"): {Symbols: []string{"runMix"}},
+				abs("proc.go"): {Symbols: []string{"FileProcessor.*"}},
+			},
+		},
+		{
+			name:      "selectors on one file are combined",
+			args:      []string{"main.go:1-5", "src", "main.go#runMix"},
+			wantPaths: []string{"main.go", "src"},
+			want:      map[string]*Selection{abs("main.go"): {Ranges: []LineRange{{1, 5}}, Symbols: []string{"runMix"}}},
+		},
+		{name: "existing path", args: []string{existing}, wantPaths: []string{existing}},
+		{name: "line zero", args: []string{"main.go:0-3"}, wantErr: "lines are numbered from 1"},
+		{name: "reversed range", args: []string{"main.go:9-3"}, wantErr: "the range ends before it starts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, selections, err := ParseSelectors(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseSelectors() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSelectors() error = %v", err)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("ParseSelectors() paths = %v, want %v", paths, tt.wantPaths)
+			}
+			if !reflect.DeepEqual(selections, tt.want) {
+				t.Errorf("ParseSelectors() selections = %v, want %v", selections, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionApply(t *testing.T) {
+	content := []byte(`package main
+
+import "fmt"
+
+// run prints a greeting
+func run() {
+	fmt.Println("hi")
+}
+
+type Server struct{}
+
+func (s *Server) Start() {}
+
+func (s *Server) Stop() {}
+`)
+
+	tests := []struct {
+		name       string
+		selection  Selection
+		lang       cleaner.Language
+		want       string
+		wantRanges string
+		wantErr    string
+	}{
+		{
+			name:       "line range",
+			selection:  Selection{Ranges: []LineRange{{3, 3}}},
+			want:       "import \"fmt\"\n",
+			wantRanges: "3-3",
+		},
+		{
+			name:       "open range is clamped to the end",
+			selection:  Selection{Ranges: []LineRange{{13, 0}}},
+			want:       "\nfunc (s *Server) Stop() {}\n",
+			wantRanges: "13-14",
+		},
+		{
+			name:       "overlapping ranges are merged",
+			selection:  Selection{Ranges: []LineRange{{5, 6}, {1, 1}, {6, 8}}},
+			want:       "package main\n... [3 lines omitted] ...\n// run prints a greeting\nfunc run() {\n\tfmt.Println(\"hi\")\n}\n",
+			wantRanges: "1-1,5-8",
+		},
+		{
+			name:       "function with its doc comment",
+			selection:  Selection{Symbols: []string{"run"}},
+			lang:       cleaner.LangGo,
+			want:       "// run prints a greeting\nfunc run() {\n\tfmt.Println(\"hi\")\n}\n",
+			wantRanges: "5-8",
+		},
+		{
+			name:       "methods of a type",
+			selection:  Selection{Symbols: []string{"Server.*"}},
+			lang:       cleaner.LangGo,
+			want:       "func (s *Server) Start() {}\n... [1 line omitted] ...\nfunc (s *Server) Stop() {}\n",
+			wantRanges: "12-12,14-14",
+		},
+		{
+			name:       "symbol and range",
+			selection:  Selection{Ranges: []LineRange{{1, 1}}, Symbols: []string{"Server"}},
+			lang:       cleaner.LangGo,
+			want:       "package main\n... [8 lines omitted] ...\ntype Server struct{}\n",
+			wantRanges: "1-1,10-10",
+		},
+		{name: "start past the end", selection: Selection{Ranges: []LineRange{{20, 30}}}, wantErr: "past the end of the file"},
+		{name: "unknown symbol", selection: Selection{Symbols: []string{"missing"}}, lang: cleaner.LangGo, wantErr: "no declarations found matching #missing"},
+		{name: "language without symbols", selection: Selection{Symbols: []string{"run"}}, wantErr: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ranges, err := tt.selection.Apply("main.go", content, tt.lang)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Apply() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+			if FormatLineRanges(ranges) != tt.wantRanges {
+				t.Errorf("Apply() ranges = %q, want %q", FormatLineRanges(ranges), tt.wantRanges)
+			}
+		})
+	}
+}
+
+func TestParseLineRanges(t *testing.T) {
+	ranges, err := parseLineRanges("12-40,55-80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []LineRange{{12, 40}, {55, 80}}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("parseLineRanges() = %v, want %v", ranges, want)
+	}
+	if FormatLineRanges(ranges) != "12-40,55-80" {
+		t.Errorf("FormatLineRanges() = %q", FormatLineRanges(ranges))
+	}
+
+	for _, s := range []string{"", "12", "a-3", "9-3"} {
+		if _, err := parseLineRanges(s); err == nil {
+			t.Errorf("parseLineRanges(%q) error = nil, want error", s)
+		}
+	}
+}
+
+func TestProcessFileSelection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	content := "package main\n\nfunc a() {}\n\nfunc b() {}\n" + strings.Repeat("// filler\n", 100)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is over the size limit, a selection keeps it whole
+	processor := NewFileProcessor(&MixOptions{
+		MaxFileSize: 64,
+		Selections:  map[string]*Selection{path: {Symbols: []string{"b"}}},
+		Transformers: TransformChain{
+			&LineNumberTransformer{},
+		},
+	})
+	result := processor.processFile(path)
+	if result.Error != nil || result.Skipped != nil {
+		t.Fatalf("processFile() = %+v, want the selected lines", result)
+	}
+	if result.Content.Lines != "5-5" {
+		t.Errorf("Lines = %q, want %q", result.Content.Lines, "5-5")
+	}
+	if want := "5 | func b() {}\n"; result.Content.Content != want {
+		t.Errorf("Content = %q, want %q", result.Content.Content, want)
+	}
+
+	// The size limit applies to the selected lines
+	processor = NewFileProcessor(&MixOptions{
+		MaxFileSize: 64,
+		Selections:  map[string]*Selection{path: {Ranges: []LineRange{{1, 0}}}},
+	})
+	result = processor.processFile(path)
+	if result.Skipped == nil || result.Skipped.Status != FileStatusSkippedSize {
+		t.Errorf("processFile() = %+v, want the selected lines skipped for their size", result)
+	}
+}
@@ -0,0 +1,511 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceSeparator separates the source name from the path inside the source
+// in the paths of files read from an InputSource, e.g. "app.tar.gz!/src/main.go"
+const sourceSeparator = "!/"
+
+// InputSource is a tree of files that is read in place through fs.FS rather
+// than from the local file system: a zip or tar archive, or a git repository
+// at a revision. While a source is open, the finder walks it when given its
+// name and the processor reads the files it returns.
+type InputSource struct {
+	Name   string // The argument the source was opened from, e.g. "repo.git@v1.2.0"
+	FS     fs.FS
+	closer func() error
+}
+
+var (
+	sources   = make(map[string]*InputSource)
+	sourcesMu sync.RWMutex
+)
+
+// OpenSource opens an input argument that names an archive (.zip, .tar,
+// .tar.gz or .tgz) or a local git repository at a revision ("repo@v1.2.0").
+// It returns nil for arguments that are plain files or directories. The
+// source must be closed once its files have been processed.
+func OpenSource(arg string) (*InputSource, error) {
+	var (
+		fsys   fs.FS
+		closer func() error
+		err    error
+	)
+
+	info, statErr := os.Stat(arg)
+	switch {
+	case statErr == nil && info.IsDir():
+		return nil, nil
+	case statErr == nil && isArchive(arg):
+		fsys, closer, err = openArchive(arg)
+	case statErr == nil:
+		return nil, nil
+	default:
+		repo, rev, ok := splitRevision(arg)
+		if !ok {
+			return nil, nil
+		}
+		fsys, err = openGitRevision(repo, rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	src := &InputSource{Name: arg, FS: fsys, closer: closer}
+	sourcesMu.Lock()
+	sources[arg] = src
+	sourcesMu.Unlock()
+	return src, nil
+}
+
+// Close releases the source. Its files can no longer be read afterwards.
+func (s *InputSource) Close() error {
+	sourcesMu.Lock()
+	delete(sources, s.Name)
+	sourcesMu.Unlock()
+
+	if s.closer != nil {
+		return s.closer()
+	}
+	return nil
+}
+
+// Path returns the path under which a file of the source is passed between
+// the finder, the processor and the reports
+func (s *InputSource) Path(name string) string {
+	return s.Name + sourceSeparator + name
+}
+
+// lookupSource returns the open source with the given name
+func lookupSource(name string) (*InputSource, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	src, ok := sources[name]
+	return src, ok
+}
+
+// splitSourcePath returns the open source and the path inside it for a file
+// returned by InputSource.Path
+func splitSourcePath(p string) (*InputSource, string, bool) {
+	name, inner, ok := strings.Cut(p, sourceSeparator)
+	if !ok {
+		return nil, "", false
+	}
+	src, ok := lookupSource(name)
+	return src, inner, ok
+}
+
+// statFile returns information about a local file or a file in an open source
+func statFile(p string) (fs.FileInfo, error) {
+	if src, inner, ok := splitSourcePath(p); ok {
+		return fs.Stat(src.FS, inner)
+	}
+	return os.Stat(p)
+}
+
+// StatFile returns information about an input file found by the FileFinder,
+// which may be a file inside an archive or git revision
+func StatFile(p string) (fs.FileInfo, error) {
+	return statFile(p)
+}
+
+// readFile reads a local file or a file in an open source
+func readFile(p string) ([]byte, error) {
+	if src, inner, ok := splitSourcePath(p); ok {
+		return fs.ReadFile(src.FS, inner)
+	}
+	return os.ReadFile(p)
+}
+
+// openFile opens a local file or a file in an open source for reading
+func openFile(p string) (io.ReadCloser, error) {
+	if src, inner, ok := splitSourcePath(p); ok {
+		return src.FS.Open(inner)
+	}
+	return os.Open(p)
+}
+
+// isArchive reports whether a file name has a supported archive extension
+func isArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// openArchive opens a zip archive in place, or indexes a tar archive, see
+// readTar
+func openArchive(name string) (fs.FS, func() error, error) {
+	if strings.HasSuffix(strings.ToLower(name), ".zip") {
+		r, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening archive %s: %w", name, err)
+		}
+		return r, r.Close, nil
+	}
+
+	open := func() (io.ReadCloser, error) {
+		f, err := os.Open(name)
+		if err != nil || strings.HasSuffix(strings.ToLower(name), ".tar") {
+			return f, err
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{gz, closers{gz, f}}, nil
+	}
+
+	fsys, err := readTar(open)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading archive %s: %w", name, err)
+	}
+	return fsys, nil, nil
+}
+
+// splitRevision splits "repo@rev" into an existing git repository and a
+// revision. Revisions starting with "-" are rejected, git would read them as
+// options.
+func splitRevision(arg string) (string, string, bool) {
+	i := strings.LastIndex(arg, "@")
+	if i <= 0 || i == len(arg)-1 || arg[i+1] == '-' {
+		return "", "", false
+	}
+	repo, rev := arg[:i], arg[i+1:]
+	if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+		return "", "", false
+	}
+	return repo, rev, true
+}
+
+// resolveRevision returns the commit hash of a revision in a git repository.
+// The revision follows --end-of-options, so it is never read as an option.
+func resolveRevision(repo, rev string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", repo, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("error resolving %s at %s: %s", repo, rev, msg)
+		}
+		return "", fmt.Errorf("error resolving %s at %s: %w", repo, rev, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// openGitRevision indexes the tree of a git repository at a revision using
+// git archive, which works for bare repositories and working copies, see
+// readTar. The revision is resolved to a commit hash first, and the hash is
+// archived.
+func openGitRevision(repo, rev string) (fs.FS, error) {
+	commit, err := resolveRevision(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	open := func() (io.ReadCloser, error) {
+		cmd := exec.Command("git", "-C", repo, "archive", "--format=tar", commit)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		c := &commandReader{ReadCloser: stdout, cmd: cmd}
+		cmd.Stderr = &c.stderr
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	fsys, err := readTar(open)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at %s: %w", repo, rev, err)
+	}
+	return fsys, nil
+}
+
+// maxBufferedEntry is the largest file of a tar archive or git revision that
+// is held in memory while the source is open. Larger files are read from the
+// archive again each time they are opened.
+const maxBufferedEntry = 1 << 20
+
+// readTar indexes the regular files and directories of the tar stream
+// returned by open into a memory file system. Files up to maxBufferedEntry
+// bytes are held in memory, larger ones are found again in a new stream when
+// opened, so the memory used does not grow with the size of large files.
+// Links and entries outside the archive root are skipped.
+func readTar(open func() (io.ReadCloser, error)) (fs.FS, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := indexTar(rc, open)
+	if err == nil {
+		// Read the padding after the last entry, so a command writing the
+		// stream is not stopped early
+		_, err = io.Copy(io.Discard, rc)
+	}
+	if closeErr := rc.Close(); err == nil && closeErr != nil {
+		return nil, closeErr
+	}
+	return fsys, err
+}
+
+// indexTar reads the entries of a tar stream for readTar
+func indexTar(r io.Reader, open func() (io.ReadCloser, error)) (fs.FS, error) {
+	fsys := newMemFS()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fsys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := tarEntryName(hdr)
+		if !ok {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			fsys.addDir(name, hdr.ModTime)
+		case tar.TypeReg:
+			if hdr.Size > maxBufferedEntry {
+				fsys.addLazyFile(name, hdr.Size, hdr.FileInfo().Mode(), hdr.ModTime, func() (io.ReadCloser, error) {
+					return openTarEntry(open, name)
+				})
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			fsys.addFile(name, data, hdr.FileInfo().Mode(), hdr.ModTime)
+		}
+	}
+}
+
+// tarEntryName returns the cleaned path of a tar entry, and false for entries
+// outside the archive root
+func tarEntryName(hdr *tar.Header) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+	return name, name != "" && fs.ValidPath(name)
+}
+
+// openTarEntry opens a new tar stream and reads up to the regular file name
+func openTarEntry(open func() (io.ReadCloser, error), name string) (io.ReadCloser, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			rc.Close()
+			if errors.Is(err, io.EOF) {
+				err = fs.ErrNotExist
+			}
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		if entry, ok := tarEntryName(hdr); ok && entry == name && hdr.Typeflag == tar.TypeReg {
+			return readCloser{tr, rc}, nil
+		}
+	}
+}
+
+// readCloser reads from one reader and closes another, e.g. an entry of a
+// tar stream and the stream
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// closers closes several readers in order
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var errs []error
+	for _, closer := range c {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// commandReader reads the output of a command. Closing it waits for the
+// command and reports its error output.
+type commandReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+func (c *commandReader) Close() error {
+	c.ReadCloser.Close()
+	if err := c.cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// memFS is a read-only file system held in memory
+type memFS map[string]*memEntry
+
+// memEntry is a file or directory in a memFS
+type memEntry struct {
+	name     string
+	data     []byte
+	size     int64
+	open     func() (io.ReadCloser, error) // Reads a file that is not held in data
+	mode     fs.FileMode
+	modTime  time.Time
+	children []string // Sorted names of the entries in a directory
+}
+
+func newMemFS() memFS {
+	return memFS{".": {name: ".", mode: fs.ModeDir | 0o755}}
+}
+
+// addDir adds a directory and its missing parents
+func (m memFS) addDir(name string, modTime time.Time) *memEntry {
+	if e, ok := m[name]; ok {
+		return e
+	}
+	parent := m.addDir(path.Dir(name), modTime)
+	e := &memEntry{name: path.Base(name), mode: fs.ModeDir | 0o755, modTime: modTime}
+	m[name] = e
+	parent.addChild(e.name)
+	return e
+}
+
+// addFile adds a file and its missing parent directories
+func (m memFS) addFile(name string, data []byte, mode fs.FileMode, modTime time.Time) {
+	if _, ok := m[name]; !ok {
+		m.addDir(path.Dir(name), modTime).addChild(path.Base(name))
+	}
+	m[name] = &memEntry{name: path.Base(name), data: data, size: int64(len(data)), mode: mode.Perm(), modTime: modTime}
+}
+
+// addLazyFile adds a file of the given size that is read with open
+func (m memFS) addLazyFile(name string, size int64, mode fs.FileMode, modTime time.Time, open func() (io.ReadCloser, error)) {
+	m.addFile(name, nil, mode, modTime)
+	m[name].size, m[name].open = size, open
+}
+
+func (e *memEntry) addChild(name string) {
+	i := sort.SearchStrings(e.children, name)
+	if i < len(e.children) && e.children[i] == name {
+		return
+	}
+	e.children = append(e.children, "")
+	copy(e.children[i+1:], e.children[i:])
+	e.children[i] = name
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.IsDir() {
+		entries := make([]fs.DirEntry, len(e.children))
+		for i, child := range e.children {
+			entries[i] = fs.FileInfoToDirEntry(m[path.Join(name, child)])
+		}
+		return &memDir{memEntry: e, entries: entries}, nil
+	}
+	if e.open != nil {
+		rc, err := e.open()
+		if err != nil {
+			return nil, err
+		}
+		return &lazyFile{memEntry: e, ReadCloser: rc}, nil
+	}
+	return &memFile{memEntry: e, Reader: bytes.NewReader(e.data)}, nil
+}
+
+// fs.FileInfo implementation
+func (e *memEntry) Name() string       { return e.name }
+func (e *memEntry) Size() int64        { return e.size }
+func (e *memEntry) Mode() fs.FileMode  { return e.mode }
+func (e *memEntry) ModTime() time.Time { return e.modTime }
+func (e *memEntry) IsDir() bool        { return e.mode.IsDir() }
+func (e *memEntry) Sys() any           { return nil }
+
+// memFile is an open file of a memFS
+type memFile struct {
+	*memEntry
+	*bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.memEntry, nil }
+func (f *memFile) Close() error               { return nil }
+
+// Size resolves the ambiguity between the embedded FileInfo and Reader
+func (f *memFile) Size() int64 { return f.memEntry.Size() }
+
+// lazyFile is an open file of a memFS that is read from its archive
+type lazyFile struct {
+	*memEntry
+	io.ReadCloser
+}
+
+func (f *lazyFile) Stat() (fs.FileInfo, error) { return f.memEntry, nil }
+
+// memDir is an open directory of a memFS
+type memDir struct {
+	*memEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.memEntry, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
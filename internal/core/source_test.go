@@ -0,0 +1,449 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// sourceFiles is the tree written into the archives used by the tests
+var sourceFiles = map[string]string{
+	"README.md":         "# Project\n",
+	"src/main.go":       "package main\n",
+	"src/util.go":       "package main\n\nfunc util() {}\n",
+	"src/vendor/lib.go": "package lib\n",
+	"docs/guide.txt":    "guide\n",
+}
+
+// sortedNames returns the names of sourceFiles in order
+func sortedNames() []string {
+	names := make([]string, 0, len(sourceFiles))
+	for name := range sourceFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeTar writes sourceFiles as a tar stream below an optional prefix
+func writeTar(t *testing.T, w io.Writer, prefix string) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	for _, name := range sortedNames() {
+		content := sourceFiles[name]
+		hdr := &tar.Header{
+			Name:    prefix + name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: time.Unix(1700000000, 0),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Links are not part of the tree read from an archive
+	if err := tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "README.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createArchive writes sourceFiles into an archive of the type given by name
+func createArchive(t *testing.T, dir, name string) string {
+	t.Helper()
+	archivePath := filepath.Join(dir, name)
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		zw := zip.NewWriter(f)
+		for _, name := range sortedNames() {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte(sourceFiles[name])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	case strings.HasSuffix(name, ".tar"):
+		writeTar(t, f, "./")
+	default:
+		gz := gzip.NewWriter(f)
+		writeTar(t, gz, "")
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return archivePath
+}
+
+func TestOpenSourceArchives(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"app.zip", "app.tar", "app.tar.gz", "app.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			archivePath := createArchive(t, dir, name)
+
+			src, err := OpenSource(archivePath)
+			if err != nil {
+				t.Fatalf("OpenSource() error = %v", err)
+			}
+			if src == nil {
+				t.Fatal("OpenSource() returned no source for an archive")
+			}
+			defer src.Close()
+
+			for _, name := range sortedNames() {
+				got, err := readFile(src.Path(name))
+				if err != nil {
+					t.Errorf("readFile(%q) error = %v", name, err)
+					continue
+				}
+				if string(got) != sourceFiles[name] {
+					t.Errorf("readFile(%q) = %q, want %q", name, got, sourceFiles[name])
+				}
+			}
+
+			info, err := statFile(src.Path("src/util.go"))
+			if err != nil {
+				t.Fatalf("statFile() error = %v", err)
+			}
+			if info.Size() != int64(len(sourceFiles["src/util.go"])) {
+				t.Errorf("statFile().Size() = %d, want %d", info.Size(), len(sourceFiles["src/util.go"]))
+			}
+
+			if _, err := statFile(src.Path("link")); err == nil {
+				t.Error("statFile() found a link that should have been left out")
+			}
+		})
+	}
+}
+
+func TestOpenSourcePlainPaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, arg := range []string{dir, file, filepath.Join(dir, "missing@v1")} {
+		src, err := OpenSource(arg)
+		if err != nil {
+			t.Errorf("OpenSource(%q) error = %v", arg, err)
+		}
+		if src != nil {
+			t.Errorf("OpenSource(%q) returned a source for a plain path", arg)
+			src.Close()
+		}
+	}
+}
+
+func TestOpenSourceInvalidArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "broken.tar.gz")
+	if err := os.WriteFile(archivePath, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenSource(archivePath); err == nil {
+		t.Error("OpenSource() expected an error for an invalid archive")
+	}
+}
+
+func TestSourceClose(t *testing.T) {
+	src, err := OpenSource(createArchive(t, t.TempDir(), "app.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := src.Path("README.md")
+	if err := src.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := lookupSource(src.Name); ok {
+		t.Error("source is still registered after Close()")
+	}
+	if _, err := readFile(p); err == nil {
+		t.Error("readFile() succeeded after the source was closed")
+	}
+}
+
+func TestOpenSourceGitRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "src", "main.go"), []byte("package main // v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Files over maxBufferedEntry are read from a new git archive when opened
+	large := strings.Repeat("x", maxBufferedEntry+1)
+	if err := os.WriteFile(filepath.Join(repo, "large.txt"), []byte(large), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1.0.0")
+
+	// Later changes to the working copy are not part of the tagged tree
+	if err := os.WriteFile(filepath.Join(repo, "src", "main.go"), []byte("package main // v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := OpenSource(repo + "@v1.0.0")
+	if err != nil {
+		t.Fatalf("OpenSource() error = %v", err)
+	}
+	if src == nil {
+		t.Fatal("OpenSource() returned no source for a git revision")
+	}
+	defer src.Close()
+
+	got, err := readFile(src.Path("src/main.go"))
+	if err != nil {
+		t.Fatalf("readFile() error = %v", err)
+	}
+	if string(got) != "package main // v1\n" {
+		t.Errorf("readFile() = %q, want the tagged content", got)
+	}
+	if got, err := readFile(src.Path("large.txt")); err != nil || string(got) != large {
+		t.Errorf("readFile() of a large file = %d bytes, %v, want %d bytes", len(got), err, len(large))
+	}
+	if _, err := statFile(src.Path("new.go")); err == nil {
+		t.Error("statFile() found a file that is not part of the revision")
+	}
+
+	if _, err := OpenSource(repo + "@no-such-revision"); err == nil {
+		t.Error("OpenSource() expected an error for an unknown revision")
+	}
+}
+
+func TestGitRevisionOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repo := t.TempDir()
+	cmd := exec.Command("git", "-C", repo, "init", "-q")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	// A revision that git would read as an option must not write this file
+	written := filepath.Join(t.TempDir(), "written.tar")
+	arg := repo + "@--output=" + written
+
+	if _, _, ok := splitRevision(arg); ok {
+		t.Errorf("splitRevision(%q) accepted a revision starting with -", arg)
+	}
+	src, err := OpenSource(arg)
+	if src != nil {
+		src.Close()
+		t.Errorf("OpenSource(%q) returned a source", arg)
+	}
+	if err != nil {
+		t.Errorf("OpenSource(%q) error = %v, want the argument left to the finder", arg, err)
+	}
+	if commit, _ := gitState(arg); commit != "" {
+		t.Errorf("gitState(%q) = %q, want no commit", arg, commit)
+	}
+	if _, err := resolveRevision(repo, "--output="+written); err == nil {
+		t.Error("resolveRevision() resolved an option")
+	}
+	if _, err := os.Stat(written); err == nil {
+		t.Error("a revision was passed to git as an option")
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	fsys := newMemFS()
+	for _, name := range sortedNames() {
+		fsys.addFile(name, []byte(sourceFiles[name]), 0o644, time.Unix(1700000000, 0))
+	}
+	fsys.addDir("empty", time.Unix(1700000000, 0))
+
+	if err := fstest.TestFS(fsys, append(sortedNames(), "empty")...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpenSourceLargeEntries(t *testing.T) {
+	large := strings.Repeat("0123456789abcdef\n", maxBufferedEntry/16)
+
+	for _, name := range []string{"big.tar", "big.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), name)
+			f, err := os.Create(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var w io.WriteCloser = f
+			if strings.HasSuffix(name, ".gz") {
+				w = gzip.NewWriter(f)
+			}
+			tw := tar.NewWriter(w)
+			for _, entry := range []struct{ name, content string }{{"data/large.txt", large}, {"small.txt", "small\n"}} {
+				if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content))}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write([]byte(entry.content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			src, err := OpenSource(archivePath)
+			if err != nil {
+				t.Fatalf("OpenSource() error = %v", err)
+			}
+			defer src.Close()
+
+			// The large file is indexed without its content
+			if e := src.FS.(memFS)["data/large.txt"]; e.data != nil || e.open == nil {
+				t.Error("a file over maxBufferedEntry is held in memory")
+			}
+			info, err := statFile(src.Path("data/large.txt"))
+			if err != nil || info.Size() != int64(len(large)) {
+				t.Errorf("statFile() = %v, %v, want a size of %d", info, err, len(large))
+			}
+			for file, want := range map[string]string{"data/large.txt": large, "small.txt": "small\n"} {
+				got, err := readFile(src.Path(file))
+				if err != nil {
+					t.Fatalf("readFile(%s) error = %v", file, err)
+				}
+				if string(got) != want {
+					t.Errorf("readFile(%s) read %d bytes, want %d", file, len(got), len(want))
+				}
+			}
+		})
+	}
+}
+
+func TestFindMatchingFilesInSource(t *testing.T) {
+	src, err := OpenSource(createArchive(t, t.TempDir(), "app.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		want     []string
+	}{
+		{
+			name:     "extension pattern",
+			includes: []string{"*.go"},
+			want:     []string{"src/main.go", "src/util.go", "src/vendor/lib.go"},
+		},
+		{
+			name:     "exclude directory relative to the archive root",
+			includes: []string{"*.go"},
+			excludes: []string{"src/vendor/**"},
+			want:     []string{"src/main.go", "src/util.go"},
+		},
+		{
+			name:     "path pattern",
+			includes: []string{"docs/*.txt", "README.md"},
+			want:     []string{"README.md", "docs/guide.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewFileFinder(tt.includes, tt.excludes, false)
+			files, err := finder.FindMatchingFiles([]string{src.Name})
+			if err != nil {
+				t.Fatalf("FindMatchingFiles() error = %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				_, inner, ok := splitSourcePath(file)
+				if !ok {
+					t.Fatalf("FindMatchingFiles() returned %q outside the source", file)
+				}
+				got = append(got, inner)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindMatchingFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFilesFromSource(t *testing.T) {
+	src, err := OpenSource(createArchive(t, t.TempDir(), "app.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	processor := NewFileProcessor(&MixOptions{MaxFileSize: 1024, MaxOutputSize: 1024 * 1024})
+	contents, err := processor.ProcessFiles([]string{src.Path("src/util.go")})
+	if err != nil {
+		t.Fatalf("ProcessFiles() error = %v", err)
+	}
+	if len(contents) != 1 {
+		t.Fatalf("ProcessFiles() returned %d files, want 1", len(contents))
+	}
+
+	got := contents[0]
+	if got.Path != "src/util.go" {
+		t.Errorf("Path = %q, want the path relative to the archive root", got.Path)
+	}
+	if got.Origin != src.Name {
+		t.Errorf("Origin = %q, want %q", got.Origin, src.Name)
+	}
+	if got.Content != sourceFiles["src/util.go"] {
+		t.Errorf("Content = %q, want %q", got.Content, sourceFiles["src/util.go"])
+	}
+}
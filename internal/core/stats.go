@@ -0,0 +1,128 @@
+package core
+
+import (
+	"encoding/xml"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxLargestFiles is the number of entries kept in BundleStats.LargestFiles
+const maxLargestFiles = 10
+
+// GroupStats aggregates file counts and sizes for a single language or directory
+type GroupStats struct {
+	Name   string `json:"name" yaml:"name" xml:"name,attr"`
+	Files  int    `json:"files" yaml:"files" xml:"files,attr"`
+	Bytes  int64  `json:"bytes" yaml:"bytes" xml:"bytes,attr"`
+	Tokens int    `json:"tokens" yaml:"tokens" xml:"tokens,attr"`
+}
+
+// FileStats describes a single file in the statistics section
+type FileStats struct {
+	Path   string `json:"path" yaml:"path" xml:"path,attr"`
+	Bytes  int64  `json:"bytes" yaml:"bytes" xml:"bytes,attr"`
+	Tokens int    `json:"tokens" yaml:"tokens" xml:"tokens,attr"`
+}
+
+// BundleStats summarizes the contents of a generated bundle
+type BundleStats struct {
+	XMLName      xml.Name     `json:"-" yaml:"-" xml:"statistics"`
+	TotalFiles   int          `json:"total_files" yaml:"total_files" xml:"total_files"`
+	TotalBytes   int64        `json:"total_bytes" yaml:"total_bytes" xml:"total_bytes"`
+	TotalTokens  int          `json:"total_tokens" yaml:"total_tokens" xml:"total_tokens"`
+	BytesSaved   int64        `json:"bytes_saved" yaml:"bytes_saved" xml:"bytes_saved"`
+	ByLanguage   []GroupStats `json:"by_language" yaml:"by_language" xml:"by_language>language"`
+	ByDirectory  []GroupStats `json:"by_directory" yaml:"by_directory" xml:"by_directory>directory"`
+	LargestFiles []FileStats  `json:"largest_files" yaml:"largest_files" xml:"largest_files>file"`
+}
+
+// EstimateTokens returns an approximate token count for the given text.
+// It uses the common heuristic of roughly four bytes per token, which is
+// close enough for budgeting without depending on a model-specific tokenizer.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// ComputeStats builds summary statistics for the given file contents.
+// Groups are sorted by size in descending order, with ties broken by name.
+func ComputeStats(contents []FileContent) *BundleStats {
+	stats := &BundleStats{}
+	languages := make(map[string]*GroupStats)
+	directories := make(map[string]*GroupStats)
+
+	for _, content := range contents {
+		tokens := EstimateTokens(content.Content)
+
+		stats.TotalFiles++
+		stats.TotalBytes += content.Size
+		stats.TotalTokens += tokens
+		if content.OriginalSize > content.Size {
+			stats.BytesSaved += content.OriginalSize - content.Size
+		}
+
+		addToGroup(languages, languageLabel(content), content.Size, tokens)
+		addToGroup(directories, path.Dir(content.Path), content.Size, tokens)
+
+		stats.LargestFiles = append(stats.LargestFiles, FileStats{
+			Path:   content.Path,
+			Bytes:  content.Size,
+			Tokens: tokens,
+		})
+	}
+
+	stats.ByLanguage = sortedGroups(languages)
+	stats.ByDirectory = sortedGroups(directories)
+
+	sort.SliceStable(stats.LargestFiles, func(i, j int) bool {
+		if stats.LargestFiles[i].Bytes != stats.LargestFiles[j].Bytes {
+			return stats.LargestFiles[i].Bytes > stats.LargestFiles[j].Bytes
+		}
+		return stats.LargestFiles[i].Path < stats.LargestFiles[j].Path
+	})
+	if len(stats.LargestFiles) > maxLargestFiles {
+		stats.LargestFiles = stats.LargestFiles[:maxLargestFiles]
+	}
+
+	return stats
+}
+
+// languageLabel returns the name used to group a file by language, falling back
+// to the file extension when no cleaner language was detected
+func languageLabel(content FileContent) string {
+	if content.Language != "" {
+		return content.Language
+	}
+	if ext := strings.TrimPrefix(content.Extension, "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+	return "other"
+}
+
+func addToGroup(groups map[string]*GroupStats, name string, size int64, tokens int) {
+	group, exists := groups[name]
+	if !exists {
+		group = &GroupStats{Name: name}
+		groups[name] = group
+	}
+	group.Files++
+	group.Bytes += size
+	group.Tokens += tokens
+}
+
+func sortedGroups(groups map[string]*GroupStats) []GroupStats {
+	result := make([]GroupStats, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400), 100},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	contents := []FileContent{
+		{Path: "pkg/a.go", Extension: "go", Language: "go", Content: strings.Repeat("a", 40), Size: 40, OriginalSize: 60},
+		{Path: "pkg/b.go", Extension: "go", Language: "go", Content: strings.Repeat("b", 20), Size: 20, OriginalSize: 20},
+		{Path: "config.yaml", Extension: "yaml", Content: strings.Repeat("c", 80), Size: 80},
+		{Path: "README", Content: "d", Size: 1},
+	}
+
+	stats := ComputeStats(contents)
+
+	if stats.TotalFiles != 4 {
+		t.Errorf("Expected 4 files, got %d", stats.TotalFiles)
+	}
+	if stats.TotalBytes != 141 {
+		t.Errorf("Expected 141 bytes, got %d", stats.TotalBytes)
+	}
+	if stats.TotalTokens != 10+5+20+1 {
+		t.Errorf("Expected 36 tokens, got %d", stats.TotalTokens)
+	}
+	if stats.BytesSaved != 20 {
+		t.Errorf("Expected 20 bytes saved, got %d", stats.BytesSaved)
+	}
+
+	wantLanguages := []string{"yaml", "go", "other"}
+	if len(stats.ByLanguage) != len(wantLanguages) {
+		t.Fatalf("Expected %d languages, got %d", len(wantLanguages), len(stats.ByLanguage))
+	}
+	for i, name := range wantLanguages {
+		if stats.ByLanguage[i].Name != name {
+			t.Errorf("ByLanguage[%d] = %q, want %q", i, stats.ByLanguage[i].Name, name)
+		}
+	}
+	if stats.ByLanguage[1].Files != 2 || stats.ByLanguage[1].Bytes != 60 {
+		t.Errorf("Unexpected go group: %+v", stats.ByLanguage[1])
+	}
+
+	if len(stats.ByDirectory) != 2 {
+		t.Fatalf("Expected 2 directories, got %d", len(stats.ByDirectory))
+	}
+	if stats.ByDirectory[0].Name != "." || stats.ByDirectory[0].Files != 2 {
+		t.Errorf("Unexpected root directory group: %+v", stats.ByDirectory[0])
+	}
+
+	if stats.LargestFiles[0].Path != "config.yaml" {
+		t.Errorf("Expected config.yaml to be the largest file, got %s", stats.LargestFiles[0].Path)
+	}
+}
+
+func TestComputeStatsLimitsLargestFiles(t *testing.T) {
+	var contents []FileContent
+	for i := 0; i < maxLargestFiles+5; i++ {
+		contents = append(contents, FileContent{
+			Path:    strings.Repeat("f", i+1),
+			Content: strings.Repeat("x", i+1),
+			Size:    int64(i + 1),
+		})
+	}
+
+	stats := ComputeStats(contents)
+	if len(stats.LargestFiles) != maxLargestFiles {
+		t.Errorf("Expected %d largest files, got %d", maxLargestFiles, len(stats.LargestFiles))
+	}
+	if stats.LargestFiles[0].Bytes != int64(maxLargestFiles+5) {
+		t.Errorf("Expected largest file first, got %+v", stats.LargestFiles[0])
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+// SymbolOptions configures symbol-focused extraction
+type SymbolOptions struct {
+	Symbols      []string // Symbol patterns such as "pkg.Func", "Type.Method" or "Type.*"
+	Depth        int      // Number of reference levels followed from the matched symbols
+	SkeletonRest bool     // Skeletonize files without selected declarations instead of dropping them
+}
+
+// symbolFile holds the parsed declarations of one file during extraction
+type symbolFile struct {
+	content  FileContent
+	cleaner  *cleaner.Cleaner
+	table    *cleaner.SymbolTable
+	selected map[int]bool
+}
+
+// declarationRef points at a declaration within the files being extracted
+type declarationRef struct {
+	file int
+	decl int
+}
+
+// ExtractSymbols reduces the contents to the declarations matching the symbol
+// patterns, plus the functions, types and constants they reference within the
+// given files, followed transitively up to opts.Depth levels. Files without any
+// selected declaration are dropped, or skeletonized when opts.SkeletonRest is set.
+func ExtractSymbols(contents []FileContent, opts SymbolOptions) ([]FileContent, error) {
+	if len(opts.Symbols) == 0 {
+		return contents, nil
+	}
+
+	files := make([]*symbolFile, len(contents))
+	index := make(map[string][]declarationRef)
+
+	for i, content := range contents {
+		file := &symbolFile{content: content, selected: make(map[int]bool)}
+		files[i] = file
+
+		lang := cleaner.Language(content.Language)
+		if lang == "" || !cleaner.SupportsSymbols(lang) {
+			continue
+		}
+
+		c, err := cleaner.NewCleaner(lang, cleaner.DefaultOptions())
+		if err != nil {
+			continue
+		}
+		file.cleaner = c
+
+		table, err := c.Declarations([]byte(content.Content))
+		if err != nil {
+			logEvent(slog.LevelWarn, EventSymbolFailed, content.Path, err.Error())
+			continue
+		}
+		file.table = table
+
+		for j, decl := range table.Declarations {
+			for _, name := range decl.Names {
+				index[name] = append(index[name], declarationRef{file: i, decl: j})
+			}
+		}
+	}
+
+	// Select the declarations matching the requested symbols
+	var queue []declarationRef
+	for i, file := range files {
+		if file.table == nil {
+			continue
+		}
+		for j, decl := range file.table.Declarations {
+			if matchesSymbol(opts.Symbols, symbolPrefixes(file.table, filepath.FromSlash(file.content.Path)), decl) {
+				file.selected[j] = true
+				queue = append(queue, declarationRef{file: i, decl: j})
+			}
+		}
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no declarations found matching symbols %s", strings.Join(opts.Symbols, ", "))
+	}
+
+	// Follow references breadth-first, one level per iteration
+	for depth := 0; depth < opts.Depth && len(queue) > 0; depth++ {
+		var next []declarationRef
+		for _, ref := range queue {
+			decl := files[ref.file].table.Declarations[ref.decl]
+			for _, name := range decl.References {
+				for _, target := range index[name] {
+					file := files[target.file]
+					if file.selected[target.decl] {
+						continue
+					}
+					file.selected[target.decl] = true
+					next = append(next, target)
+				}
+			}
+		}
+		queue = next
+	}
+
+	var result []FileContent
+	for _, file := range files {
+		content := file.content
+
+		if len(file.selected) > 0 {
+			selected := make([]int, 0, len(file.selected))
+			for idx := range file.selected {
+				selected = append(selected, idx)
+				logEvent(slog.LevelDebug, EventSymbolSelected, content.Path, "",
+					"symbol", qualifiedName(file.table.Declarations[idx]))
+			}
+			content.Content = string(file.table.Extract([]byte(content.Content), selected))
+			content.Size = int64(len(content.Content))
+			result = append(result, content)
+			continue
+		}
+
+		if !opts.SkeletonRest || file.cleaner == nil || content.Content == "" {
+			continue
+		}
+		skeleton, err := file.cleaner.Skeleton([]byte(content.Content))
+		if err != nil {
+			logEvent(slog.LevelWarn, EventSkeletonFailed, content.Path, err.Error())
+			continue
+		}
+		content.Content = string(skeleton)
+		content.Size = int64(len(skeleton))
+		content.Skeleton = true
+		result = append(result, content)
+	}
+
+	return result, nil
+}
+
+// symbolPrefixes returns the names the declarations of a file can be
+// qualified with: the package or namespace the file declares, in full and by
+// its last part, or else the directory and the file name without extension
+func symbolPrefixes(table *cleaner.SymbolTable, filePath string) []string {
+	if pkg := table.Package; pkg != "" {
+		prefixes := []string{pkg}
+		if i := strings.LastIndex(pkg, "."); i >= 0 {
+			prefixes = append(prefixes, pkg[i+1:])
+		}
+		return prefixes
+	}
+
+	dir := filepath.Base(filepath.Dir(filePath))
+	module := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	return []string{dir, module}
+}
+
+// matchesSymbol reports whether a declaration matches one of the symbol patterns.
+// A declaration can be addressed by its name, by "Container.Name" for members,
+// and with one of the prefixes, such as its package, in front.
+func matchesSymbol(patterns []string, prefixes []string, decl cleaner.Declaration) bool {
+	for _, name := range decl.Names {
+		candidates := []string{name}
+		if decl.Container != "" {
+			candidates = append(candidates, decl.Container+"."+name)
+		}
+		for _, prefix := range prefixes {
+			if prefix == "" || prefix == "." || prefix == string(filepath.Separator) {
+				continue
+			}
+			candidates = append(candidates, prefix+"."+name)
+			if decl.Container != "" {
+				candidates = append(candidates, prefix+"."+decl.Container+"."+name)
+			}
+		}
+
+		for _, pattern := range patterns {
+			for _, candidate := range candidates {
+				if matched, err := path.Match(pattern, candidate); err == nil && matched {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// qualifiedName returns a readable name for a declaration, used in logs
+func qualifiedName(decl cleaner.Declaration) string {
+	name := strings.Join(decl.Names, ",")
+	if decl.Container != "" {
+		return decl.Container + "." + name
+	}
+	return name
+}
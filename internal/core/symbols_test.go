@@ -0,0 +1,186 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+)
+
+func TestExtractSymbols(t *testing.T) {
+	contents := []FileContent{
+		{
+			Path:     "server/router.go",
+			Language: "go",
+			Content: `package server
+
+import "strings"
+
+type Router struct{}
+
+// Handle dispatches a request
+func (r *Router) Handle(path string) string {
+	return normalize(path)
+}
+
+func normalize(path string) string {
+	return strings.ToLower(trim(path))
+}
+
+func trim(path string) string {
+	return strings.TrimSpace(path)
+}
+
+func Unused() {}
+`,
+		},
+		{
+			Path:     "internal/helpers/other.go",
+			Language: "go",
+			Content: `package util
+
+func Other() int {
+	return 1
+}
+`,
+		},
+	}
+
+	tests := []struct {
+		name         string
+		opts         SymbolOptions
+		wantFiles    int
+		contains     []string
+		notContains  []string
+		wantSkeleton bool
+		expectError  bool
+	}{
+		{
+			name:        "depth zero keeps only the match",
+			opts:        SymbolOptions{Symbols: []string{"Router.Handle"}, Depth: 0},
+			wantFiles:   1,
+			contains:    []string{"package server", "import \"strings\"", "// Handle dispatches", "func (r *Router) Handle"},
+			notContains: []string{"func normalize", "func Unused"},
+		},
+		{
+			name:        "depth one follows direct references",
+			opts:        SymbolOptions{Symbols: []string{"Router.Handle"}, Depth: 1},
+			wantFiles:   1,
+			contains:    []string{"func (r *Router) Handle", "func normalize"},
+			notContains: []string{"func trim", "func Unused"},
+		},
+		{
+			name:        "depth two follows transitive references",
+			opts:        SymbolOptions{Symbols: []string{"server.Router.Handle"}, Depth: 2},
+			wantFiles:   1,
+			contains:    []string{"func normalize", "func trim"},
+			notContains: []string{"func Unused"},
+		},
+		{
+			name:      "package prefix and wildcard",
+			opts:      SymbolOptions{Symbols: []string{"util.Oth*"}},
+			wantFiles: 1,
+			contains:  []string{"func Other() int"},
+		},
+		{
+			name:        "directory is not the declared package",
+			opts:        SymbolOptions{Symbols: []string{"helpers.Other"}},
+			expectError: true,
+		},
+		{
+			name:         "skeletonize remaining files",
+			opts:         SymbolOptions{Symbols: []string{"Unused"}, SkeletonRest: true},
+			wantFiles:    2,
+			contains:     []string{"func Unused"},
+			wantSkeleton: true,
+		},
+		{
+			name:        "no match",
+			opts:        SymbolOptions{Symbols: []string{"Missing"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractSymbols(contents, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(result) != tt.wantFiles {
+				t.Fatalf("Expected %d files, got %d", tt.wantFiles, len(result))
+			}
+
+			first := result[0].Content
+			for _, want := range tt.contains {
+				if !strings.Contains(first, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, first)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(first, unwanted) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", unwanted, first)
+				}
+			}
+			if result[0].Size != int64(len(first)) {
+				t.Errorf("Expected size %d, got %d", len(first), result[0].Size)
+			}
+			if tt.wantSkeleton {
+				if !result[1].Skeleton || strings.Contains(result[1].Content, "return 1") {
+					t.Errorf("Expected %s to be skeletonized, got:\n%s", result[1].Path, result[1].Content)
+				}
+			}
+		})
+	}
+}
+
+func TestSymbolPrefixes(t *testing.T) {
+	tests := []struct {
+		path    string
+		lang    cleaner.Language
+		content string
+		want    []string
+	}{
+		{"cmd/server/main.go", cleaner.LangGo, "package main\n", []string{"main"}},
+		{"src/Util.java", cleaner.LangJava, "package com.acme.util;\nclass Util {}\n", []string{"com.acme.util", "util"}},
+		{"src/util.kt", cleaner.LangKotlin, "package com.acme.util\nfun f() {}\n", []string{"com.acme.util", "util"}},
+		{"src/Util.cs", cleaner.LangCSharp, "namespace Acme.Util;\nclass Util {}\n", []string{"Acme.Util", "Util"}},
+		{"src/util.php", cleaner.LangPHP, "<?php\nnamespace Acme\\Util;\nfunction f() {}\n", []string{"Acme.Util", "Util"}},
+		{"app/models/user.py", cleaner.LangPython, "def f():\n    pass\n", []string{"models", "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			c, err := cleaner.NewCleaner(tt.lang, cleaner.DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			table, err := c.Declarations([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := symbolPrefixes(table, filepath.FromSlash(tt.path))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("symbolPrefixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSymbolsWithoutPatterns(t *testing.T) {
+	contents := []FileContent{{Path: "a.go", Language: "go", Content: "package a\n"}}
+	result, err := ExtractSymbols(contents, SymbolOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].Content != contents[0].Content {
+		t.Errorf("Expected contents to be returned unchanged, got %+v", result)
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy selects how symbolic links found below the input paths are handled
+type SymlinkPolicy string
+
+const (
+	SymlinkFollow SymlinkPolicy = "follow" // Bundle the target under the link path, once per target
+	SymlinkSkip   SymlinkPolicy = "skip"   // Leave links out
+	SymlinkRecord SymlinkPolicy = "record" // Bundle the link and its target as a document without content
+)
+
+// ParseSymlinkPolicy validates a --symlinks value
+func ParseSymlinkPolicy(value string) (SymlinkPolicy, error) {
+	switch SymlinkPolicy(strings.ToLower(value)) {
+	case SymlinkFollow, "":
+		return SymlinkFollow, nil
+	case SymlinkSkip:
+		return SymlinkSkip, nil
+	case SymlinkRecord:
+		return SymlinkRecord, nil
+	default:
+		return "", fmt.Errorf("invalid symlinks mode %q: must be follow, skip or record", value)
+	}
+}
+
+// linkTarget returns the target of a local symbolic link as it is stored in
+// the link, which may be relative to the directory of the link
+func linkTarget(path string) (string, bool) {
+	if _, _, ok := splitSourcePath(path); ok {
+		return "", false
+	}
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
+
+// linkContent returns the document recording a link. It has no content, the
+// target is kept as stored so an unpacked bundle recreates the same link.
+func (p *FileProcessor) linkContent(path, target string) FileContent {
+	relPath, err := p.createRelativePath(path)
+	if err != nil {
+		relPath = path
+	}
+	return FileContent{
+		Path:       filepath.ToSlash(relPath),
+		Name:       filepath.Base(path),
+		Extension:  strings.TrimPrefix(filepath.Ext(path), "."),
+		Target:     filepath.ToSlash(target),
+		SourcePath: path,
+		RootPath:   p.rootPath(path),
+	}
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SymlinkPolicy
+		wantErr bool
+	}{
+		{value: "", want: SymlinkFollow},
+		{value: "follow", want: SymlinkFollow},
+		{value: "SKIP", want: SymlinkSkip},
+		{value: "record", want: SymlinkRecord},
+		{value: "copy", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSymlinkPolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSymlinkPolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSymlinkPolicy(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// makeTree creates files and links below root. Entries ending in "/" are
+// directories, entries with a "->" are links to the given target.
+func makeTree(t *testing.T, root string, entries ...string) {
+	t.Helper()
+	for _, entry := range entries {
+		name, target, isLink := strings.Cut(entry, " -> ")
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		switch {
+		case isLink:
+			err = os.Symlink(filepath.FromSlash(target), path)
+		case strings.HasSuffix(name, "/"):
+			err = os.MkdirAll(path, 0755)
+		default:
+			err = os.WriteFile(path, []byte("package "+strings.TrimSuffix(filepath.Base(name), ".go")+"\n"), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// relPaths returns paths relative to root, sorted
+func relPaths(t *testing.T, root string, paths []string) []string {
+	t.Helper()
+	rels := make([]string, 0, len(paths))
+	for _, p := range paths {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	sort.Strings(rels)
+	return rels
+}
+
+func TestFindMatchingFilesSymlinkPolicy(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir,
+		"root/main.go",
+		"root/gen.tmpl",
+		"root/alias.go -> main.go",
+		"root/gen.go -> gen.tmpl",
+		"root/broken.go -> missing.go",
+		"root/vendor -> ../shared",
+		"shared/x.go",
+	)
+	root := filepath.Join(dir, "root")
+
+	tests := []struct {
+		policy      SymlinkPolicy
+		want        []string
+		wantSkipped map[string]string // Reason by skipped link
+	}{
+		{
+			policy: SymlinkFollow,
+			want:   []string{"root/gen.go", "root/main.go", "shared/x.go"},
+			wantSkipped: map[string]string{
+				"root/alias.go":  "the link target is already selected as " + filepath.Join(root, "main.go"),
+				"root/broken.go": "broken link to missing.go",
+			},
+		},
+		{
+			policy: SymlinkSkip,
+			want:   []string{"root/main.go"},
+			wantSkipped: map[string]string{
+				"root/alias.go":  "links are not followed with --symlinks=skip",
+				"root/gen.go":    "links are not followed with --symlinks=skip",
+				"root/broken.go": "links are not followed with --symlinks=skip",
+			},
+		},
+		{
+			policy:      SymlinkRecord,
+			want:        []string{"root/alias.go", "root/broken.go", "root/gen.go", "root/main.go"},
+			wantSkipped: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			ff := NewFileFinder([]string{"*.go"}, nil, true)
+			ff.SetSymlinks(tt.policy)
+			matches, err := ff.FindMatchingFiles([]string{root})
+			if err != nil {
+				t.Fatalf("FindMatchingFiles() error = %v", err)
+			}
+			if got := relPaths(t, dir, matches); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindMatchingFiles() = %v, want %v", got, tt.want)
+			}
+
+			skipped := make(map[string]string)
+			for _, entry := range ff.ExcludedFiles() {
+				if entry.Status == FileStatusSkippedLink {
+					skipped[relPaths(t, dir, []string{entry.Path})[0]] = entry.Reason
+				}
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped links = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestFindMatchingFilesLinkLoops(t *testing.T) {
+	root := t.TempDir()
+	// a/b/to_c and c/d/to_a form a cycle across several directories, self
+	// links back to the root and the two link files point at each other
+	makeTree(t, root,
+		"a/a.go",
+		"a/b/to_c -> ../../c",
+		"c/c.go",
+		"c/d/to_a -> ../../a",
+		"a/b/self -> ../..",
+		"loop1.go -> loop2.go",
+		"loop2.go -> loop1.go",
+	)
+
+	tests := []struct {
+		policy SymlinkPolicy
+		want   []string
+	}{
+		{policy: SymlinkFollow, want: []string{"a/a.go", "c/c.go"}},
+		{policy: SymlinkRecord, want: []string{"a/a.go", "c/c.go", "loop1.go", "loop2.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			// Repeated as the walk order differs between runs
+			for i := 0; i < 20; i++ {
+				ff := NewFileFinder([]string{"**/*.go"}, nil, true)
+				ff.SetSymlinks(tt.policy)
+				matches, err := ff.FindMatchingFiles([]string{root})
+				if err != nil {
+					t.Fatalf("FindMatchingFiles() error = %v", err)
+				}
+				if got := relPaths(t, root, matches); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("FindMatchingFiles() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFilesRecordedLinks(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root,
+		"main.go",
+		"alias.go -> main.go",
+		"broken.go -> missing/gone.go",
+	)
+
+	processor := NewFileProcessor(&MixOptions{
+		InputPath:   root,
+		MaxFileSize: 1024,
+		Symlinks:    SymlinkRecord,
+	})
+	contents, err := processor.ProcessFiles([]string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "alias.go"),
+		filepath.Join(root, "broken.go"),
+	})
+	if err != nil {
+		t.Fatalf("ProcessFiles() error = %v", err)
+	}
+
+	got := make(map[string]FileContent)
+	for _, c := range contents {
+		got[c.Path] = c
+	}
+	if len(got) != 3 {
+		t.Fatalf("ProcessFiles() = %+v, want the file and both links", contents)
+	}
+	if c := got["main.go"]; c.Target != "" || c.Content != "package main\n" {
+		t.Errorf("main.go = %+v, want its content", c)
+	}
+	for path, target := range map[string]string{"alias.go": "main.go", "broken.go": "missing/gone.go"} {
+		if c := got[path]; c.Target != target || c.Content != "" || c.Size != 0 {
+			t.Errorf("%s = %+v, want a link to %s without content", path, c, target)
+		}
+	}
+
+	// The file manager passes recorded links without reading their target
+	fm := NewFileManager(1024, 1024, OutputTypeXML)
+	fm.SetSymlinks(SymlinkRecord)
+	if valid, err := fm.ValidateFiles([]string{filepath.Join(root, "broken.go")}); err != nil || len(valid) != 1 {
+		t.Errorf("ValidateFiles() = %v, %v, want the broken link kept", valid, err)
+	}
+}
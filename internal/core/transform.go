@@ -0,0 +1,465 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/drgsn/filefusion/internal/core/cleaner"
+	"github.com/drgsn/filefusion/internal/core/markup"
+	"github.com/drgsn/filefusion/internal/core/minify"
+)
+
+// defaultTruncateLines is the number of lines kept by "truncate" without an argument
+const defaultTruncateLines = 200
+
+// Transformer is one step of the pipeline that every file passes through after
+// it is read. Transformers run in order, each receiving the output of the
+// previous one.
+type Transformer interface {
+	// Match reports whether the transformer applies to the file
+	Match(file FileContent) bool
+	// Transform returns the transformed file
+	Transform(ctx context.Context, file FileContent) (FileContent, error)
+}
+
+// TransformChain is an ordered list of transformers
+type TransformChain []Transformer
+
+// Apply runs the matching transformers on the file. A failing transformer is
+// logged and skipped, so the file continues with its previous content.
+func (c TransformChain) Apply(ctx context.Context, file FileContent) FileContent {
+	return c.apply(ctx, file, nil)
+}
+
+// apply runs the chain like Apply and passes the outcome of every transformer
+// to trace, when set
+func (c TransformChain) apply(ctx context.Context, file FileContent, trace func(ExplainStep)) FileContent {
+	for _, t := range c {
+		if !t.Match(file) {
+			if trace != nil {
+				trace(ExplainStep{Stage: transformerName(t), Result: "not applicable"})
+			}
+			continue
+		}
+		transformed, err := t.Transform(ctx, file)
+		if err != nil {
+			logEvent(slog.LevelWarn, transformFailedEvent(t), file.Path, err.Error(), "transformer", transformerName(t))
+			if trace != nil {
+				trace(ExplainStep{Stage: transformerName(t), Result: "failed", Detail: err.Error() + ", content left unchanged"})
+			}
+			continue
+		}
+		transformed.Size = int64(len(transformed.Content))
+		if trace != nil {
+			trace(ExplainStep{Stage: transformerName(t), Result: "applied",
+				Detail: fmt.Sprintf("%s to %s", formatSize(file.Size), formatSize(transformed.Size))})
+		}
+		file = transformed
+	}
+	return file
+}
+
+// transformFailedEvent keeps the established events for the built-in stages
+func transformFailedEvent(t Transformer) Event {
+	switch t.(type) {
+	case *CleanerTransformer:
+		return EventCleanFailed
+	case *MarkupTransformer:
+		return EventMarkupFailed
+	case *skeletonTransformer:
+		return EventSkeletonFailed
+	}
+	return EventTransformFailed
+}
+
+// transformerName returns the name a transformer is configured with
+func transformerName(t Transformer) string {
+	if s, ok := t.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", t)
+}
+
+// ParseTransformer creates a transformer from a --transform value of the form
+// "[patterns=]name[:argument]". Patterns are comma-separated globs that limit
+// the transformer to matching files. The names are:
+//   - clean: run the code cleaner with the given options
+//   - redact: replace secrets such as API keys, tokens and private keys
+//   - truncate[:lines]: keep the first lines of each file
+//   - line-numbers: prefix every line with its number
+//   - log-summary: collapse repeated log lines, see LogSummaryTransformer
+//   - minify[:items]: compact JSON and YAML and sample arrays and CSV rows
+//   - exec:command [args...]: run an external transformer, see ExecTransformer
+func ParseTransformer(spec string, cleanerOptions *cleaner.CleanerOptions) (Transformer, error) {
+	spec = strings.TrimSpace(spec)
+	var patterns []string
+	if prefix, rest, ok := strings.Cut(spec, "="); ok && !strings.ContainsAny(prefix, ": \t") {
+		for _, pattern := range strings.Split(prefix, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern == "" {
+				continue
+			}
+			if !doublestar.ValidatePattern(pattern) {
+				return nil, fmt.Errorf("invalid transformer %q: bad pattern %q", spec, pattern)
+			}
+			patterns = append(patterns, pattern)
+		}
+		spec = strings.TrimSpace(rest)
+	}
+
+	name, arg, _ := strings.Cut(spec, ":")
+	arg = strings.TrimSpace(arg)
+
+	var t Transformer
+	switch strings.TrimSpace(name) {
+	case "clean":
+		if cleanerOptions == nil {
+			cleanerOptions = cleaner.DefaultOptions()
+		}
+		t = NewCleanerTransformer(cleanerOptions)
+	case "redact":
+		t = &RedactTransformer{}
+	case "truncate":
+		maxLines := defaultTruncateLines
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid transformer %q: line count must be a positive number", spec)
+			}
+			maxLines = n
+		}
+		t = &TruncateTransformer{MaxLines: maxLines}
+	case "line-numbers":
+		t = &LineNumberTransformer{}
+	case "minify":
+		maxItems := minify.DefaultMaxItems
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid transformer %q: item count must be zero or a positive number", spec)
+			}
+			maxItems = n
+		}
+		t = &MinifyTransformer{MaxItems: maxItems}
+	case "log-summary":
+		// Patterns replace the default log file patterns instead of narrowing them
+		return &LogSummaryTransformer{Patterns: patterns}, nil
+	case "exec":
+		command := strings.Fields(arg)
+		if len(command) == 0 {
+			return nil, fmt.Errorf("invalid transformer %q: exec requires a command", spec)
+		}
+		t = &ExecTransformer{Command: command, Timeout: defaultExecTimeout}
+	default:
+		return nil, fmt.Errorf("unknown transformer %q: expected clean, redact, truncate, line-numbers, log-summary, minify or exec", name)
+	}
+
+	if len(patterns) > 0 {
+		t = &patternTransformer{Transformer: t, Patterns: patterns}
+	}
+	return t, nil
+}
+
+// patternTransformer limits a transformer to the files matching its patterns.
+// Patterns are read like --pattern, see matchFilePatterns.
+type patternTransformer struct {
+	Transformer
+	Patterns []string
+}
+
+func (t *patternTransformer) Match(file FileContent) bool {
+	return matchFilePatterns(t.Patterns, file) && t.Transformer.Match(file)
+}
+
+// matchFilePatterns reports whether a file matches the patterns by its path
+// below the input root, see matchPatterns
+func matchFilePatterns(patterns []string, file FileContent) bool {
+	rel := file.RootPath
+	if rel == "" {
+		rel = file.Path
+	}
+	return matchPatterns(patterns, rel)
+}
+
+// matchPatterns reports whether a slash-separated path below an input root
+// matches the patterns with the semantics of --pattern: without a slash a
+// pattern matches the file name, otherwise the path, a leading "/" anchors it
+// to the root and a leading "!" excludes. The last matching pattern decides.
+func matchPatterns(patterns []string, rel string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		rule := newPatternRule(pattern, false)
+		if ok, err := rule.match(rel); err == nil && ok {
+			matched = !rule.exclude
+		}
+	}
+	return matched
+}
+
+func (t *patternTransformer) String() string {
+	return strings.Join(t.Patterns, ",") + "=" + transformerName(t.Transformer)
+}
+
+// CleanerTransformer runs the code cleaner on files in a supported language.
+// Cleaners are created on first use and shared between goroutines.
+type CleanerTransformer struct {
+	Options  *cleaner.CleanerOptions
+	cleaners map[cleaner.Language]*cleaner.Cleaner
+	mu       sync.RWMutex
+}
+
+// NewCleanerTransformer creates a cleaner transformer with the given options
+func NewCleanerTransformer(options *cleaner.CleanerOptions) *CleanerTransformer {
+	return &CleanerTransformer{
+		Options:  options,
+		cleaners: make(map[cleaner.Language]*cleaner.Cleaner),
+	}
+}
+
+func (t *CleanerTransformer) Match(file FileContent) bool {
+	return file.Language != "" && file.Content != ""
+}
+
+func (t *CleanerTransformer) Transform(_ context.Context, file FileContent) (result FileContent, err error) {
+	// Add defer/recover to prevent panics from crashing goroutines
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic: %v", r)
+		}
+	}()
+
+	c, err := t.cleaner(cleaner.Language(file.Language))
+	if err != nil {
+		return file, fmt.Errorf("failed to create cleaner: %w", err)
+	}
+
+	cleaned, err := c.Clean([]byte(file.Content))
+	if err != nil {
+		return file, fmt.Errorf("failed to clean content: %w", err)
+	}
+
+	file.Content = string(cleaned)
+	return file, nil
+}
+
+func (t *CleanerTransformer) String() string {
+	return "clean"
+}
+
+// cleaner safely gets or creates a cleaner for the given language
+func (t *CleanerTransformer) cleaner(lang cleaner.Language) (*cleaner.Cleaner, error) {
+	// Try to get existing cleaner
+	t.mu.RLock()
+	c, exists := t.cleaners[lang]
+	t.mu.RUnlock()
+
+	if exists {
+		return c, nil
+	}
+
+	// Create new cleaner if needed
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Check again in case another goroutine created it
+	if c, exists = t.cleaners[lang]; exists {
+		return c, nil
+	}
+
+	c, err := cleaner.NewCleaner(lang, t.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	t.cleaners[lang] = c
+	return c, nil
+}
+
+// MarkupTransformer converts notebooks and strips Markdown and reStructuredText
+type MarkupTransformer struct {
+	Options *markup.MarkupOptions
+}
+
+func (t *MarkupTransformer) Match(file FileContent) bool {
+	return markup.DetectFormat(file.Name) != ""
+}
+
+func (t *MarkupTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	transformed, err := markup.Transform(markup.DetectFormat(file.Name), []byte(file.Content), t.Options)
+	if err != nil {
+		return file, err
+	}
+	file.Content = string(transformed)
+	return file, nil
+}
+
+func (t *MarkupTransformer) String() string {
+	return "markup"
+}
+
+// MinifyTransformer compacts JSON and YAML files and samples long arrays and
+// CSV or TSV rows down to MaxItems, see the minify package
+type MinifyTransformer struct {
+	MaxItems int
+}
+
+func (t *MinifyTransformer) Match(file FileContent) bool {
+	return minify.DetectFormat(file.Name) != ""
+}
+
+func (t *MinifyTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	minified, err := minify.Minify(minify.DetectFormat(file.Name), []byte(file.Content), t.MaxItems)
+	if err != nil {
+		return file, err
+	}
+	file.Content = string(minified)
+	return file, nil
+}
+
+func (t *MinifyTransformer) String() string {
+	return fmt.Sprintf("minify:%d", t.MaxItems)
+}
+
+// redactedText replaces secrets found by the RedactTransformer
+const redactedText = "[REDACTED]"
+
+var (
+	// Secrets recognized by their format alone
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                     // AWS access key IDs
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                                    // GitHub tokens
+		regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),                                  // GitHub fine-grained tokens
+		regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`),                                 // Slack tokens
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`),                                         // API secret keys
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`), // JWTs
+	}
+	// Values assigned to keys that name a secret, e.g. password = "hunter22"
+	secretAssignment = regexp.MustCompile(`(?i)\b([\w.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)[\w.-]*["']?\s*[:=]\s*)(["']?)([^\s"',;]{8,})(["']?)`)
+)
+
+// RedactTransformer replaces secrets such as private keys, cloud credentials,
+// tokens and values assigned to password or secret keys with [REDACTED]
+type RedactTransformer struct{}
+
+func (t *RedactTransformer) Match(file FileContent) bool {
+	return true
+}
+
+func (t *RedactTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	content := file.Content
+	for _, pattern := range secretPatterns {
+		content = pattern.ReplaceAllString(content, redactedText)
+	}
+	content = secretAssignment.ReplaceAllStringFunc(content, func(match string) string {
+		parts := secretAssignment.FindStringSubmatch(match)
+		if parts[3] == redactedText {
+			return match
+		}
+		return parts[1] + parts[2] + redactedText + parts[4]
+	})
+	file.Content = content
+	return file, nil
+}
+
+func (t *RedactTransformer) String() string {
+	return "redact"
+}
+
+// TruncateTransformer keeps the first MaxLines lines of each file and notes how
+// many lines were cut
+type TruncateTransformer struct {
+	MaxLines int
+}
+
+func (t *TruncateTransformer) Match(file FileContent) bool {
+	return t.MaxLines > 0
+}
+
+func (t *TruncateTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	lines := strings.SplitAfter(file.Content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= t.MaxLines {
+		return file, nil
+	}
+
+	kept := strings.Join(lines[:t.MaxLines], "")
+	if !strings.HasSuffix(kept, "\n") {
+		kept += "\n"
+	}
+	file.Content = kept + fmt.Sprintf("... (%d more lines truncated)\n", len(lines)-t.MaxLines)
+	return file, nil
+}
+
+func (t *TruncateTransformer) String() string {
+	return fmt.Sprintf("truncate:%d", t.MaxLines)
+}
+
+// LineNumberTransformer prefixes every line with its right-aligned number, so
+// that a model can refer to lines of the original file
+type LineNumberTransformer struct{}
+
+func (t *LineNumberTransformer) Match(file FileContent) bool {
+	return file.Content != ""
+}
+
+func (t *LineNumberTransformer) Transform(_ context.Context, file FileContent) (FileContent, error) {
+	lines := strings.Split(file.Content, "\n")
+	trailingNewline := lines[len(lines)-1] == ""
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+
+	numbers := originalLineNumbers(file, len(lines))
+	width := len(strconv.Itoa(numbers[len(numbers)-1]))
+	var b strings.Builder
+	for i, line := range lines {
+		if numbers[i] == 0 {
+			fmt.Fprintf(&b, "%*s | %s", width, "", line)
+		} else {
+			fmt.Fprintf(&b, "%*d | %s", width, numbers[i], line)
+		}
+		if i < len(lines)-1 || trailingNewline {
+			b.WriteByte('\n')
+		}
+	}
+	file.Content = b.String()
+	return file, nil
+}
+
+// originalLineNumbers returns the number of each of the count lines of a file.
+// Lines selected from a file keep their numbers in the whole file, with zero
+// for the marker between ranges, unless an earlier transformer changed how
+// many lines there are.
+func originalLineNumbers(file FileContent, count int) []int {
+	numbers := make([]int, 0, count)
+	if ranges, err := parseLineRanges(file.Lines); file.Lines != "" && err == nil {
+		for i, r := range ranges {
+			if i > 0 {
+				numbers = append(numbers, 0)
+			}
+			for n := r.Start; n <= r.End; n++ {
+				numbers = append(numbers, n)
+			}
+		}
+		if len(numbers) == count {
+			return numbers
+		}
+		numbers = numbers[:0]
+	}
+	for n := 1; n <= count; n++ {
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+func (t *LineNumberTransformer) String() string {
+	return "line-numbers"
+}
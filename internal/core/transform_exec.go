@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultExecTimeout limits how long an external transformer may run per file
+const defaultExecTimeout = 30 * time.Second
+
+// ExecTransformer runs an external program for every file. The program receives
+// the file as a JSON object on stdin, with the same fields as the JSON output
+// format (path, name, extension, content, size, language, ...), and writes the
+// transformed object to stdout. Fields missing from the output keep their
+// value, so a transformer may print only {"content": "..."}; an empty content
+// leaves the file out of the output. A non-zero exit status is an error and the
+// file keeps its previous content.
+type ExecTransformer struct {
+	Command []string      // Program and arguments, run without a shell
+	Timeout time.Duration // Maximum run time per file, no limit when zero
+}
+
+func (t *ExecTransformer) Match(file FileContent) bool {
+	return len(t.Command) > 0
+}
+
+func (t *ExecTransformer) Transform(ctx context.Context, file FileContent) (FileContent, error) {
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		defer cancel()
+	}
+
+	input, err := json.Marshal(file)
+	if err != nil {
+		return file, fmt.Errorf("failed to encode file: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.Command[0], t.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for children that keep the output pipes open after a timeout
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", t.Timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return file, fmt.Errorf("%s: %w: %s", t.Command[0], err, msg)
+		}
+		return file, fmt.Errorf("%s: %w", t.Command[0], err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return file, fmt.Errorf("%s: no output", t.Command[0])
+	}
+
+	result := file
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return file, fmt.Errorf("%s: invalid output: %w", t.Command[0], err)
+	}
+	return result, nil
+}
+
+func (t *ExecTransformer) String() string {
+	return "exec:" + strings.Join(t.Command, " ")
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecTransformer(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	file := FileContent{Path: "src/app.py", Name: "app.py", Extension: "py", Content: "print(1)\n", Size: 9}
+
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		want    FileContent
+		wantErr string
+	}{
+		{
+			name:   "replaces content",
+			script: `cat >/dev/null; printf '%s' '{"content": "print(2)\n"}'`,
+			want:   FileContent{Path: "src/app.py", Name: "app.py", Extension: "py", Content: "print(2)\n", Size: 9},
+		},
+		{
+			name:   "receives the file as json",
+			script: `grep -q '"path":"src/app.py"' && echo '{"language": "python"}'`,
+			want:   FileContent{Path: "src/app.py", Name: "app.py", Extension: "py", Content: "print(1)\n", Size: 9, Language: "python"},
+		},
+		{
+			name:    "non-zero exit",
+			script:  `echo "bad input" >&2; exit 3`,
+			wantErr: "bad input",
+		},
+		{
+			name:    "no output",
+			script:  `cat >/dev/null`,
+			wantErr: "no output",
+		},
+		{
+			name:    "invalid output",
+			script:  `echo not json`,
+			wantErr: "invalid output",
+		},
+		{
+			name:    "timeout",
+			script:  `sleep 5`,
+			timeout: 50 * time.Millisecond,
+			wantErr: "timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := &ExecTransformer{Command: []string{"sh", "-c", tt.script}, Timeout: tt.timeout}
+			got, err := transformer.Transform(context.Background(), file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want error containing %q", err, tt.wantErr)
+				}
+				if got != file {
+					t.Errorf("failed transformer changed the file: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,14 +6,33 @@ import (
 	"strings"
 
 	"github.com/drgsn/filefusion/internal/core/cleaner"
+	"github.com/drgsn/filefusion/internal/core/markup"
 )
 
 type FileContent struct {
-	Path      string `json:"path"`
-	Name      string `json:"name"`
-	Content   string `json:"content"`
-	Extension string `json:"extension"`
-	Size      int64  `json:"size"`
+	Path         string `json:"path"`
+	Name         string `json:"name"`
+	Content      string `json:"content"`
+	Extension    string `json:"extension"`
+	Size         int64  `json:"size"`
+	OriginalSize int64  `json:"original_size,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Skeleton     bool   `json:"skeleton,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty"`
+	Origin       string `json:"origin,omitempty"` // Input source the file was read from, if any
+	Lines        string `json:"lines,omitempty"`  // Line ranges selected from the file, e.g. "40-120"
+	Target       string `json:"target,omitempty"` // Target of a link recorded with --symlinks=record
+	SourcePath   string `json:"-" yaml:"-"`       // Path the file was found at, which rankings and orders refer to
+	RootPath     string `json:"-" yaml:"-"`       // Slash-separated path below the input root, which transformer patterns match
+}
+
+// key returns the path the finder reported for the file, which differs from
+// Path for files read from an archive or git revision
+func (c FileContent) key() string {
+	if c.SourcePath != "" {
+		return filepath.ToSlash(c.SourcePath)
+	}
+	return c.Path
 }
 
 type OutputType string
@@ -39,7 +58,23 @@ type MixOptions struct {
 	MaxOutputSize  int64
 	OutputType     OutputType
 	CleanerOptions *cleaner.CleanerOptions
+	MarkupOptions  *markup.MarkupOptions
 	IgnoreSymlinks bool
+	IncludeStats   bool
+	SkipBinary     bool
+	Skeleton       bool
+	FullPatterns   []string
+	LanguageMap    []LanguageMapping
+	InputRoots     []string // Input paths that --lang-map, --full and transformer patterns are matched relative to
+	Transformers   []Transformer
+	Oversize       Oversize
+	OversizeHead   int
+	OversizeTail   int
+	Selections     map[string]*Selection // Selectors given on input files, by absolute path
+	Symlinks       SymlinkPolicy         // How links the finder passes on are read
+	FailFast       bool                  // Stop processing at the first file that fails
+	Overwrite      OverwritePolicy       // What happens to an existing output file
+	Provenance     *Provenance           // How the bundle was made, written into it when set
 }
 
 func validatePattern(pattern string) error {
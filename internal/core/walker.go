@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// walkEntry locates a file or directory found while walking an input root
+type walkEntry struct {
+	path     string // Path as reported, below the input root
+	realPath string // Path with links resolved, used to skip files seen twice
+	rel      string // Slash-separated path relative to the input root
+	input    bool   // Whether the entry is an input path itself
+}
+
+// child returns the entry for a name inside the directory e
+func (e walkEntry) child(name string) walkEntry {
+	rel := name
+	if e.rel != "" {
+		rel = e.rel + "/" + name
+	}
+	return walkEntry{
+		path:     joinName(e.path, name),
+		realPath: joinName(e.realPath, name),
+		rel:      rel,
+	}
+}
+
+// joinName appends a name to a clean directory path without cleaning the
+// result again as filepath.Join does
+func joinName(dir, name string) string {
+	if strings.HasSuffix(dir, string(os.PathSeparator)) {
+		return dir + name
+	}
+	return dir + string(os.PathSeparator) + name
+}
+
+// dirQueue is the deque of directories owned by one walker goroutine. The
+// owner pushes and pops at the back, working depth-first, while idle
+// goroutines steal from the front, where the largest subtrees wait.
+type dirQueue struct {
+	mu   sync.Mutex
+	dirs []walkEntry
+}
+
+func (q *dirQueue) push(dir walkEntry) {
+	q.mu.Lock()
+	q.dirs = append(q.dirs, dir)
+	q.mu.Unlock()
+}
+
+func (q *dirQueue) pop() (walkEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.dirs) == 0 {
+		return walkEntry{}, false
+	}
+	dir := q.dirs[len(q.dirs)-1]
+	q.dirs = q.dirs[:len(q.dirs)-1]
+	return dir, true
+}
+
+func (q *dirQueue) steal() (walkEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.dirs) == 0 {
+		return walkEntry{}, false
+	}
+	dir := q.dirs[0]
+	q.dirs = q.dirs[1:]
+	return dir, true
+}
+
+// dirWalker reads directory trees with a fixed number of goroutines. Each
+// goroutine works on its own queue and steals directories from the others
+// when it runs out, so a single large root is spread over all of them.
+// Directories the rules provably exclude are never read.
+type dirWalker struct {
+	ctx     context.Context // Cancelled to stop reading directories
+	ff      *FileFinder
+	queues  []*dirQueue
+	pending sync.WaitGroup // Directories queued but not read yet
+	wake    chan struct{}  // Signals idle goroutines that directories were queued
+	done    chan struct{}  // Closed once every queued directory has been read
+	results chan<- Result
+}
+
+func newDirWalker(ctx context.Context, ff *FileFinder, workers int, results chan<- Result) *dirWalker {
+	w := &dirWalker{
+		ctx:     ctx,
+		ff:      ff,
+		queues:  make([]*dirQueue, max(workers, 1)),
+		wake:    make(chan struct{}, max(workers, 1)),
+		done:    make(chan struct{}),
+		results: results,
+	}
+	for i := range w.queues {
+		w.queues[i] = &dirQueue{}
+	}
+	return w
+}
+
+// run walks the given root directories and returns once all files below them
+// have been sent to the results channel, or soon after the context is
+// cancelled
+func (w *dirWalker) run(roots []walkEntry) {
+	for i, root := range roots {
+		w.pending.Add(1)
+		w.queues[i%len(w.queues)].push(root)
+	}
+	go func() {
+		w.pending.Wait()
+		close(w.done)
+	}()
+
+	var wg sync.WaitGroup
+	for id := range w.queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(id)
+		}()
+	}
+	wg.Wait()
+}
+
+// work reads directories until all queues are drained
+func (w *dirWalker) work(id int) {
+	for {
+		dir, ok := w.next(id)
+		if !ok {
+			select {
+			case <-w.done:
+				return
+			case <-w.wake:
+				continue
+			}
+		}
+		// Once cancelled, queued directories are dropped without being read
+		if w.ctx.Err() == nil {
+			w.readDir(id, dir)
+		}
+		w.pending.Done()
+	}
+}
+
+// next takes a directory from the goroutine's own queue or steals one
+func (w *dirWalker) next(id int) (walkEntry, bool) {
+	if dir, ok := w.queues[id].pop(); ok {
+		return dir, true
+	}
+	for i := 1; i < len(w.queues); i++ {
+		if dir, ok := w.queues[(id+i)%len(w.queues)].steal(); ok {
+			return dir, true
+		}
+	}
+	return walkEntry{}, false
+}
+
+// readDir handles the files of a directory and queues its subdirectories
+func (w *dirWalker) readDir(id int, dir walkEntry) {
+	entries, err := readDirUnsorted(dir.path)
+	if err != nil {
+		// For vanished directories and permission errors, log and continue
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			logEvent(slog.LevelWarn, EventWalkSkipped, dir.path, err.Error())
+			return
+		}
+		w.results <- Result{Path: dir.path, Err: fmt.Errorf("error walking path %q: %w", dir.path, err)}
+		return
+	}
+
+	for _, d := range entries {
+		if w.ctx.Err() != nil {
+			return
+		}
+		entry := dir.child(d.Name())
+		if d.IsDir() {
+			if w.ff.pruneDir(entry) {
+				continue
+			}
+			w.pending.Add(1)
+			w.queues[id].push(entry)
+			select {
+			case w.wake <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		if err := w.ff.handleEntry(entry, d, w.results); err != nil {
+			w.results <- Result{Path: entry.path, Err: err}
+		}
+	}
+}
+
+// readDirUnsorted reads the entries of a directory in the order the file
+// system returns them. The finder sorts its matches once at the end.
+func readDirUnsorted(dir string) ([]os.DirEntry, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.ReadDir(-1)
+}
@@ -0,0 +1,327 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// buildTree creates packages directories of files source files each, with a
+// node_modules directory of the same size next to them
+func buildTree(tb testing.TB, packages, files int) string {
+	tb.Helper()
+	root := tb.TempDir()
+	for _, top := range []string{"src", "node_modules"} {
+		for p := 0; p < packages; p++ {
+			dir := filepath.Join(root, top, fmt.Sprintf("pkg%d", p), "internal")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				tb.Fatal(err)
+			}
+			for f := 0; f < files; f++ {
+				name := fmt.Sprintf("file%d.go", f)
+				if f%4 == 0 {
+					name = fmt.Sprintf("file%d.js", f)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+					tb.Fatal(err)
+				}
+			}
+		}
+	}
+	return root
+}
+
+// treeFiles lists the files of a tree made by buildTree for which keep
+// returns true, sorted. The lists are spelled out from the layout of the
+// tree, independently of the pattern rules under test.
+func treeFiles(root string, packages, files int, keep func(top string, pkg int, name string) bool) []string {
+	var paths []string
+	for _, top := range []string{"src", "node_modules"} {
+		for p := 0; p < packages; p++ {
+			for f := 0; f < files; f++ {
+				name := fmt.Sprintf("file%d.go", f)
+				if f%4 == 0 {
+					name = fmt.Sprintf("file%d.js", f)
+				}
+				if keep(top, p, name) {
+					paths = append(paths, filepath.Join(root, top, fmt.Sprintf("pkg%d", p), "internal", name))
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// findSerial is a sequential walk with one filepath.WalkDir per root that
+// resolves links for every file and tests every file against every pattern,
+// the baseline of the benchmarks
+func findSerial(ff *FileFinder, root string) ([]string, error) {
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if _, err := ff.GetRealPath(path); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		include := ff.rules.includeAll
+		for i := len(ff.rules.rules) - 1; i >= 0; i-- {
+			matched, err := ff.rules.rules[i].match(rel)
+			if err != nil {
+				return err
+			}
+			if matched {
+				include = !ff.rules.rules[i].exclude
+				break
+			}
+		}
+		if include {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+func TestFindMatchingFilesTree(t *testing.T) {
+	const packages, files = 12, 20
+	root := buildTree(t, packages, files)
+
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		keep     func(top string, pkg int, name string) bool
+	}{
+		{
+			name: "all files",
+			keep: func(string, int, string) bool { return true },
+		},
+		{
+			name:     "extension",
+			includes: []string{"*.go"},
+			keep:     func(_ string, _ int, name string) bool { return strings.HasSuffix(name, ".go") },
+		},
+		{
+			name:     "excluded directory",
+			includes: []string{"*.go", "*.js"},
+			excludes: []string{"node_modules/**"},
+			keep:     func(top string, _ int, _ string) bool { return top == "src" },
+		},
+		{
+			name:     "re-included files",
+			includes: []string{"*.go"},
+			excludes: []string{"**/internal/**", "!file1*.go"},
+			keep: func(_ string, _ int, name string) bool {
+				return strings.HasPrefix(name, "file1") && strings.HasSuffix(name, ".go")
+			},
+		},
+		{
+			name:     "path pattern",
+			includes: []string{"src/pkg1*/**/*.js"},
+			keep: func(top string, pkg int, name string) bool {
+				return top == "src" && (pkg == 1 || pkg >= 10) && strings.HasSuffix(name, ".js")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := treeFiles(root, packages, files, tt.keep)
+			got, err := NewFileFinder(tt.includes, tt.excludes, false).FindMatchingFiles([]string{root})
+			if err != nil {
+				t.Fatalf("FindMatchingFiles() error = %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindMatchingFiles() found %d files, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestFindMatchingFilesOrder(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"a.go", "a/b.go", "a/c/d.go", "b.go", "a-z.go"} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := NewFileFinder([]string{"*.go"}, nil, false).FindMatchingFiles([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, match := range matches {
+		rel, _ := filepath.Rel(root, match)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"a/b.go", "a/c/d.go", "a-z.go", "a.go", "b.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMatchingFiles() = %v, want walk order %v", got, want)
+	}
+}
+
+func TestFindMatchingFilesPrunesExcludedDirectories(t *testing.T) {
+	root := buildTree(t, 2, 3)
+	buf := captureLogs(t, LogOptions{Format: LogFormatJSON, Level: LevelTrace})
+
+	ff := NewFileFinder([]string{"*.go"}, []string{"node_modules/**"}, false)
+	if _, err := ff.FindMatchingFiles([]string{root}); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := buf.String()
+	if !strings.Contains(logs, `"event":"dir_pruned"`) || !strings.Contains(logs, filepath.Join(root, "node_modules")) {
+		t.Errorf("expected the node_modules directory to be pruned, got logs:\n%s", logs)
+	}
+	if strings.Contains(logs, filepath.Join(root, "node_modules", "pkg0")) {
+		t.Error("files below a pruned directory were visited")
+	}
+
+	// The directory is recorded in place of the files below it
+	want := []ReportEntry{{
+		Path:   filepath.Join(root, "node_modules"),
+		Status: FileStatusExcluded,
+		Reason: `matched exclude pattern "node_modules/**"`,
+	}}
+	if got := ff.ExcludedFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludedFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestFindMatchingFilesRecordsUnmatchedDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"src/main.go", "docs/guide.go"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ff := NewFileFinder([]string{"src/**"}, nil, false)
+	if _, err := ff.FindMatchingFiles([]string{root}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ReportEntry{{
+		Path:   filepath.Join(root, "docs"),
+		Status: FileStatusExcluded,
+		Reason: "no include pattern can match below it",
+	}}
+	if got := ff.ExcludedFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludedFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestDirWalkerWorkerCounts(t *testing.T) {
+	root := buildTree(t, 8, 5)
+	want := treeFiles(root, 8, 5, func(string, int, string) bool { return true })
+
+	for _, workers := range []int{0, 1, 3, 16} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			ff := NewFileFinder(nil, nil, false)
+			results := make(chan Result)
+			go func() {
+				newDirWalker(context.Background(), ff, workers, results).run([]walkEntry{{path: root, realPath: root}})
+				close(results)
+			}()
+
+			var got []string
+			for result := range results {
+				if result.Err != nil {
+					t.Fatal(result.Err)
+				}
+				got = append(got, result.Path)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("walker found %d files, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+// benchmarkFind compares the serial walk with FindMatchingFiles on a tree of
+// about 40,000 files, half of them below node_modules
+func benchmarkFind(b *testing.B, includes, excludes []string) {
+	root := buildTree(b, 200, 100)
+	previous := Logger()
+	SetLogger(NewLogger(&strings.Builder{}, LogOptions{Level: slog.LevelError}))
+	b.Cleanup(func() { SetLogger(previous) })
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := findSerial(NewFileFinder(includes, excludes, true), root); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewFileFinder(includes, excludes, true).FindMatchingFiles([]string{root}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkFindMatchingFiles(b *testing.B) {
+	benchmarkFind(b, []string{"*.go", "*.json", "*.yaml", "*.yml"}, nil)
+}
+
+func BenchmarkFindMatchingFilesExcludedDirectory(b *testing.B) {
+	benchmarkFind(b, []string{"*.go", "*.js"}, []string{"**/node_modules/**", "**/*_test.go"})
+}
+
+func TestFindMatchingFilesFailFast(t *testing.T) {
+	root := buildTree(t, 2, 3)
+	file := filepath.Join(root, "src", "pkg0", "internal", "file1.go")
+	// A path below a file cannot be walked
+	inputs := []string{filepath.Join(file, "a"), filepath.Join(file, "b"), root}
+
+	for _, failFast := range []bool{false, true} {
+		ff := NewFileFinder([]string{"*.go"}, nil, false)
+		ff.SetFailFast(failFast)
+		matches, err := ff.FindMatchingFiles(inputs)
+
+		var errs *MultiError
+		if !errors.As(err, &errs) {
+			t.Fatalf("FindMatchingFiles() with fail fast %v error = %v, want a *MultiError", failFast, err)
+		}
+		if failFast {
+			// The walk stops at the first failure, before the root is read
+			if errs.Len() != 1 || len(matches) != 0 {
+				t.Errorf("FindMatchingFiles() with fail fast = %d matches, %d errors, want none and 1", len(matches), errs.Len())
+			}
+			continue
+		}
+		if errs.Len() != 2 || len(matches) == 0 {
+			t.Errorf("FindMatchingFiles() = %d matches, %d errors, want matches and 2", len(matches), errs.Len())
+		}
+	}
+}
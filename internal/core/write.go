@@ -0,0 +1,220 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OverwritePolicy selects what happens to an output file that already exists
+type OverwritePolicy string
+
+const (
+	OverwriteBundles   OverwritePolicy = ""           // Replace earlier bundles, refuse other files
+	OverwriteForce     OverwritePolicy = "force"      // Replace any file
+	OverwriteNoClobber OverwritePolicy = "no-clobber" // Leave the existing file as it is
+	OverwriteBackup    OverwritePolicy = "backup"     // Keep the existing file with a .bak suffix
+)
+
+// backupSuffix is appended to the name of an output kept with --backup
+const backupSuffix = ".bak"
+
+// bundleGenerator names filefusion in the marker every bundle carries
+const bundleGenerator = "filefusion"
+
+// bundleMarkers identify the bundles written in each format, near the start
+// of the file
+var bundleMarkers = []string{
+	`<documents generator="` + bundleGenerator + `"`,
+	`"generator": "` + bundleGenerator + `"`,
+	"generator: " + bundleGenerator,
+}
+
+// unmarkedPrefixes are the beginnings of bundles written before the marker
+var unmarkedPrefixes = []string{
+	"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<documents>",
+	"{\n  \"documents\": ",
+	"documents:",
+}
+
+// bundleExtensions are the extensions of the output formats, only files with
+// one of them are read to look for the marker
+var bundleExtensions = map[string]bool{".xml": true, ".json": true, ".yaml": true, ".yml": true}
+
+// CheckOutput reports whether a bundle may be written to path. An output that
+// is one of the inputs is refused and an existing file is handled by the
+// policy. False without an error means the existing file is left as it is.
+func CheckOutput(path string, inputs []string, policy OverwritePolicy) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, &MixError{File: path, Message: fmt.Sprintf("error checking output file: %v", err)}
+	}
+	if !info.Mode().IsRegular() {
+		return false, &MixError{File: path, Message: "output path exists and is not a regular file"}
+	}
+
+	// Writing over an input would bundle the previous output into the next one
+	for _, input := range inputs {
+		if inputInfo, err := os.Stat(input); err == nil && os.SameFile(info, inputInfo) {
+			return false, &MixError{
+				File:    path,
+				Message: fmt.Sprintf("output file is the selected input %s, exclude it or choose another output path", input),
+			}
+		}
+	}
+
+	switch policy {
+	case OverwriteNoClobber:
+		logEvent(slog.LevelWarn, EventOutputKept, path, "output file exists and is left unchanged with --no-clobber")
+		return false, nil
+	case OverwriteForce, OverwriteBackup:
+		return true, nil
+	}
+	if info.Size() > 0 && !isBundle(path) && !isUnmarkedBundle(path) {
+		return false, &MixError{
+			File:    path,
+			Message: "output file exists and is not a bundle, use --force to replace it, --backup to keep a copy or --no-clobber to leave it",
+		}
+	}
+	return true, nil
+}
+
+// isBundle reports whether a file carries the marker of a filefusion bundle
+func isBundle(path string) bool {
+	head := readHead(path, 128)
+	for _, marker := range bundleMarkers {
+		if strings.Contains(head, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnmarkedBundle reports whether a file starts like a bundle written
+// before bundles carried a marker
+func isUnmarkedBundle(path string) bool {
+	head := readHead(path, 64)
+	for _, prefix := range unmarkedPrefixes {
+		if strings.HasPrefix(head, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readHead returns up to n bytes from the start of a file
+func readHead(path string, n int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	head := make([]byte, n)
+	read, _ := io.ReadFull(f, head)
+	return string(head[:read])
+}
+
+// atomicFile is written next to its destination and renamed into place once
+// complete, so the rename never crosses file systems and readers never see a
+// partial file
+type atomicFile struct {
+	*os.File
+	path string // Destination of the file
+}
+
+// createAtomic creates the temporary file for path in its directory. Its name
+// starts with a dot, so the finder passes over leftovers of an interrupted run.
+// It is created with mode 0666 like a file from os.Create, so the umask sets
+// the permissions of a new output.
+func createAtomic(path string) (*atomicFile, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".")
+	for try := 0; ; try++ {
+		name := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10) + ".tmp"
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 100 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &atomicFile{File: f, path: path}, nil
+	}
+}
+
+// commit syncs the file and moves it into place. A replaced file passes its
+// permissions on, a new file keeps those it was created with.
+func (f *atomicFile) commit(policy OverwritePolicy) error {
+	if info, err := os.Stat(f.path); err == nil {
+		if err := f.Chmod(info.Mode().Perm()); err != nil {
+			f.abort()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.abort()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if policy == OverwriteBackup {
+		if err := backupFile(f.path); err != nil {
+			os.Remove(f.Name())
+			return fmt.Errorf("error keeping a backup: %w", err)
+		}
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	syncDir(filepath.Dir(f.path))
+	return nil
+}
+
+// abort closes and removes the file, leaving the destination untouched
+func (f *atomicFile) abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// backupFile keeps the current content of path under the backup suffix,
+// replacing an older backup. A hard link keeps path in place until the new
+// file is renamed over it, where links are unsupported the file is moved.
+func backupFile(path string) error {
+	backup := path + backupSuffix
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(path, backup); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err := os.Rename(path, backup); err != nil {
+			return err
+		}
+	}
+	logEvent(slog.LevelInfo, EventOutputBackedUp, path, "previous output kept as "+backup)
+	return nil
+}
+
+// syncDir flushes the directory entry of a renamed file. Not every platform
+// can sync a directory, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckOutput(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "bundle.xml")
+	notes := filepath.Join(dir, "notes.txt")
+	marked := filepath.Join(dir, "marked.json")
+	empty := filepath.Join(dir, "empty.xml")
+	input := filepath.Join(dir, "input.yaml")
+	for path, content := range map[string]string{
+		bundle: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<documents>\n</documents>",
+		notes:  "my notes",
+		marked: "{\n  \"generator\": \"filefusion\",\n  \"documents\": []\n}",
+		empty:  "",
+		input:  "documents:\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		inputs    []string
+		policy    OverwritePolicy
+		wantWrite bool
+		wantErr   string
+	}{
+		{name: "new file", path: filepath.Join(dir, "new.xml"), wantWrite: true},
+		{name: "earlier bundle", path: marked, wantWrite: true},
+		{name: "bundle without a marker", path: bundle, wantWrite: true},
+		{name: "empty file", path: empty, wantWrite: true},
+		{name: "other file", path: notes, wantErr: "is not a bundle"},
+		{name: "other file with force", path: notes, policy: OverwriteForce, wantWrite: true},
+		{name: "other file with backup", path: notes, policy: OverwriteBackup, wantWrite: true},
+		{name: "bundle with no-clobber", path: bundle, policy: OverwriteNoClobber},
+		{name: "directory", path: dir, policy: OverwriteForce, wantErr: "not a regular file"},
+		{name: "selected input", path: input, inputs: []string{bundle, input}, policy: OverwriteForce, wantErr: "is the selected input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			write, err := CheckOutput(tt.path, tt.inputs, tt.policy)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CheckOutput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckOutput() error = %v", err)
+			}
+			if write != tt.wantWrite {
+				t.Errorf("CheckOutput() = %v, want %v", write, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestGenerateAtomic(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.xml")
+	contents := []FileContent{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12}}
+
+	generate := func(policy OverwritePolicy, maxSize int64) error {
+		t.Helper()
+		gen, err := NewOutputGenerator(&MixOptions{
+			OutputPath:    output,
+			OutputType:    OutputTypeXML,
+			MaxOutputSize: maxSize,
+			Overwrite:     policy,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gen.Generate(contents)
+	}
+	// leftovers returns the entries of dir other than the outputs
+	leftovers := func() []string {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			if e.Name() != "out.xml" && e.Name() != "out.xml.bak" {
+				names = append(names, e.Name())
+			}
+		}
+		return names
+	}
+
+	// A new output gets the permissions of a file from os.Create, no
+	// temporary file is left behind
+	if err := generate(OverwriteBundles, 1024); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got, want := filePerm(t, output), createdPerm(t); got != want {
+		t.Errorf("permissions = %v, want %v", got, want)
+	}
+	if names := leftovers(); len(names) != 0 {
+		t.Errorf("leftover files = %v", names)
+	}
+
+	// A replaced output keeps its permissions
+	if err := os.Chmod(output, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(OverwriteBundles, 1024); err != nil {
+		t.Fatalf("Generate() over a bundle error = %v", err)
+	}
+	if info, _ := os.Stat(output); info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	// An output over the size limit leaves the existing file in place
+	if err := os.WriteFile(output, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(OverwriteForce, 10); err == nil {
+		t.Error("Generate() over the size limit succeeded")
+	}
+	if data, _ := os.ReadFile(output); string(data) != "keep me" {
+		t.Errorf("output = %q, want the existing file kept", data)
+	}
+	if names := leftovers(); len(names) != 0 {
+		t.Errorf("leftover files = %v", names)
+	}
+
+	// Other files are only replaced when asked to
+	if err := generate(OverwriteBundles, 1024); err == nil {
+		t.Error("Generate() replaced a file that is not a bundle")
+	}
+	if err := generate(OverwriteNoClobber, 1024); err != nil {
+		t.Errorf("Generate() with no-clobber error = %v", err)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "keep me" {
+		t.Errorf("output = %q, want the file left unchanged", data)
+	}
+
+	if err := generate(OverwriteBackup, 1024); err != nil {
+		t.Fatalf("Generate() with backup error = %v", err)
+	}
+	if data, _ := os.ReadFile(output + backupSuffix); string(data) != "keep me" {
+		t.Errorf("backup = %q, want the previous file", data)
+	}
+	if data, _ := os.ReadFile(output); !strings.Contains(string(data), "package main") {
+		t.Errorf("output = %q, want the new bundle", data)
+	}
+}
+
+// filePerm returns the permission bits of a file
+func filePerm(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Mode().Perm()
+}
+
+// createdPerm returns the permissions os.Create gives a new file under the
+// current umask
+func createdPerm(t *testing.T) os.FileMode {
+	t.Helper()
+	probe := filepath.Join(t.TempDir(), "probe")
+	f, err := os.Create(probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return filePerm(t, probe)
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package core
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestGenerateUmask(t *testing.T) {
+	old := syscall.Umask(0o027)
+	defer syscall.Umask(old)
+
+	output := filepath.Join(t.TempDir(), "out.xml")
+	gen, err := NewOutputGenerator(&MixOptions{
+		OutputPath:    output,
+		OutputType:    OutputTypeXML,
+		MaxOutputSize: 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate([]FileContent{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12}}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if perm := filePerm(t, output); perm != 0o640 {
+		t.Errorf("permissions = %v, want 0640 under umask 027", perm)
+	}
+}